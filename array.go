@@ -20,7 +20,7 @@ type Array struct {
 // Example:
 //  array := NewArray(t, []interface{}{"foo", 123})
 func NewArray(reporter Reporter, value []interface{}) *Array {
-	chain := makeChain(reporter)
+	chain := makeChain(reporter).root("Array").rootJSON()
 	if value == nil {
 		chain.fail("expected non-nil array value")
 	} else {
@@ -44,6 +44,16 @@ func (a *Array) Path(path string) *Value {
 	return getPath(&a.chain, a.value, path)
 }
 
+// PathAll is similar to Value.PathAll.
+func (a *Array) PathAll(path string) *Array {
+	return getPathAll(&a.chain, a.value, path)
+}
+
+// Pointer is similar to Value.Pointer.
+func (a *Array) Pointer(pointer string) *Value {
+	return getPointer(&a.chain, a.value, pointer)
+}
+
 // Schema is similar to Value.Schema.
 func (a *Array) Schema(schema interface{}) *Array {
 	checkSchema(&a.chain, a.value, schema)
@@ -56,7 +66,7 @@ func (a *Array) Schema(schema interface{}) *Array {
 //  array := NewArray(t, []interface{}{1, 2, 3})
 //  array.Length().Equal(3)
 func (a *Array) Length() *Number {
-	return &Number{a.chain, float64(len(a.value))}
+	return &Number{a.chain.enter("Length()"), float64(len(a.value))}
 }
 
 // Element returns a new Value object that may be used to inspect array element
@@ -70,15 +80,16 @@ func (a *Array) Length() *Number {
 //  array.Element(0).String().Equal("foo")
 //  array.Element(1).Number().Equal(123)
 func (a *Array) Element(index int) *Value {
+	chain := a.chain.enter("Element(%d)", index).enterIndex(index)
 	if index < 0 || index >= len(a.value) {
 		a.chain.fail(
 			"\narray index out of bounds:\n  index %d\n\n  bounds [%d; %d)",
 			index,
 			0,
 			len(a.value))
-		return &Value{a.chain, nil}
+		return &Value{chain, nil}
 	}
-	return &Value{a.chain, a.value[index]}
+	return &Value{chain, a.value[index]}
 }
 
 // First returns a new Value object that may be used to inspect first element
@@ -93,9 +104,9 @@ func (a *Array) Element(index int) *Value {
 func (a *Array) First() *Value {
 	if len(a.value) < 1 {
 		a.chain.fail("\narray is empty")
-		return &Value{a.chain, nil}
+		return &Value{a.chain.enter("First()").enterIndex(0), nil}
 	}
-	return &Value{a.chain, a.value[0]}
+	return &Value{a.chain.enter("First()").enterIndex(0), a.value[0]}
 }
 
 // Last returns a new Value object that may be used to inspect last element
@@ -110,9 +121,10 @@ func (a *Array) First() *Value {
 func (a *Array) Last() *Value {
 	if len(a.value) < 1 {
 		a.chain.fail("\narray is empty")
-		return &Value{a.chain, nil}
+		return &Value{a.chain.enter("Last()"), nil}
 	}
-	return &Value{a.chain, a.value[len(a.value)-1]}
+	last := len(a.value) - 1
+	return &Value{a.chain.enter("Last()").enterIndex(last), a.value[last]}
 }
 
 // Iter returns a new slice of Values attached to array elements.
@@ -130,7 +142,9 @@ func (a *Array) Iter() []Value {
 	}
 	ret := []Value{}
 	for n := range a.value {
-		ret = append(ret, Value{a.chain, a.value[n]})
+		ret = append(ret, Value{
+			a.chain.enter("Iter()[%d]", n).enterIndex(n), a.value[n],
+		})
 	}
 	return ret
 }
@@ -173,7 +187,8 @@ func (a *Array) Equal(value interface{}) *Array {
 		return a
 	}
 	if !reflect.DeepEqual(expected, a.value) {
-		a.chain.fail("\nexpected array equal to:\n%s\n\nbut got:\n%s\n\ndiff:\n%s",
+		a.chain.failExpected(expected, a.value,
+			"\nexpected array equal to:\n%s\n\nbut got:\n%s\n\ndiff:\n%s",
 			dumpValue(expected),
 			dumpValue(a.value),
 			diffValues(expected, a.value))
@@ -0,0 +1,581 @@
+package httpexpect
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authenticator adds credentials to requests.
+//
+// Authenticate is invoked before every attempt to send request. If server
+// responds with "401 Unauthorized", Refresh is invoked, and if it returns
+// true, request is authenticated and sent again. This is done only once
+// per request, and only if request body can be replayed (see RetryPolicy).
+//
+// Both methods receive the client used to send request, which should be
+// used to obtain tokens, so that authentication works with Binder and
+// with any other client passed to Config.
+//
+// Authenticators may be shared among concurrently sent requests, and
+// should be safe for concurrent use.
+//
+// Example:
+//  e := httpexpect.WithConfig(httpexpect.Config{
+//      BaseURL:       "http://example.com",
+//      Reporter:      httpexpect.NewAssertReporter(t),
+//      Authenticator: httpexpect.NewBearerAuthenticator("my-token"),
+//  })
+type Authenticator interface {
+	// Authenticate adds credentials to request, e.g. to its headers.
+	Authenticate(client Client, req *http.Request) error
+
+	// Refresh is invoked when server rejected credentials with given
+	// "401 Unauthorized" response. It returns true if credentials were
+	// updated and request should be sent again.
+	Refresh(client Client, req *http.Request, resp *http.Response) (bool, error)
+}
+
+// BearerAuthenticator sends static token in "Authorization: Bearer" header.
+type BearerAuthenticator struct {
+	token string
+}
+
+// NewBearerAuthenticator returns a new BearerAuthenticator with given token.
+//
+// Example:
+//  req := NewRequest(config, "GET", "/path")
+//  req.WithAuthenticator(NewBearerAuthenticator("my-token"))
+func NewBearerAuthenticator(token string) *BearerAuthenticator {
+	return &BearerAuthenticator{token: token}
+}
+
+// Authenticate implements Authenticator.Authenticate.
+func (a *BearerAuthenticator) Authenticate(client Client, req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+// Refresh implements Authenticator.Refresh.
+// Static token can't be refreshed, so it always returns false.
+func (a *BearerAuthenticator) Refresh(
+	client Client, req *http.Request, resp *http.Response,
+) (bool, error) {
+	return false, nil
+}
+
+// APIKeyLocation defines where APIKeyAuthenticator puts the key.
+type APIKeyLocation int
+
+const (
+	// APIKeyInHeader puts API key into request header.
+	APIKeyInHeader APIKeyLocation = iota
+
+	// APIKeyInQuery puts API key into URL query parameter.
+	APIKeyInQuery
+)
+
+// APIKeyAuthenticator sends static API key in request header or URL query.
+type APIKeyAuthenticator struct {
+	location APIKeyLocation
+	name     string
+	key      string
+}
+
+// NewAPIKeyAuthenticator returns a new APIKeyAuthenticator that sends key
+// in header or query parameter with given name.
+//
+// Example:
+//  req := NewRequest(config, "GET", "/path")
+//  req.WithAuthenticator(NewAPIKeyAuthenticator(APIKeyInHeader, "X-API-Key", "secret"))
+func NewAPIKeyAuthenticator(
+	location APIKeyLocation, name, key string,
+) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{
+		location: location,
+		name:     name,
+		key:      key,
+	}
+}
+
+// Authenticate implements Authenticator.Authenticate.
+func (a *APIKeyAuthenticator) Authenticate(client Client, req *http.Request) error {
+	switch a.location {
+	case APIKeyInHeader:
+		req.Header.Set(a.name, a.key)
+	case APIKeyInQuery:
+		query := req.URL.Query()
+		query.Set(a.name, a.key)
+		req.URL.RawQuery = query.Encode()
+	default:
+		return fmt.Errorf("unsupported API key location %d", a.location)
+	}
+	return nil
+}
+
+// Refresh implements Authenticator.Refresh.
+// Static key can't be refreshed, so it always returns false.
+func (a *APIKeyAuthenticator) Refresh(
+	client Client, req *http.Request, resp *http.Response,
+) (bool, error) {
+	return false, nil
+}
+
+// DigestAuthenticator implements HTTP Digest authentication, as defined
+// in RFC 7616.
+//
+// The first request is sent without credentials. When server responds with
+// "401 Unauthorized" and Digest challenge, the request is sent again with
+// credentials, and the challenge is remembered and used for subsequent
+// requests until server rejects it.
+//
+// Supported algorithms are MD5, MD5-sess, SHA-256, and SHA-256-sess.
+// Supported quality of protection is "auth", or none for RFC 2069
+// compatibility.
+type DigestAuthenticator struct {
+	username string
+	password string
+
+	mu        sync.Mutex
+	challenge map[string]string
+	nc        int
+}
+
+// NewDigestAuthenticator returns a new DigestAuthenticator with given
+// credentials.
+//
+// Example:
+//  req := NewRequest(config, "GET", "/path")
+//  req.WithAuthenticator(NewDigestAuthenticator("john", "secret"))
+func NewDigestAuthenticator(username, password string) *DigestAuthenticator {
+	return &DigestAuthenticator{
+		username: username,
+		password: password,
+	}
+}
+
+// Authenticate implements Authenticator.Authenticate.
+func (a *DigestAuthenticator) Authenticate(client Client, req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.challenge == nil {
+		return nil
+	}
+
+	a.nc++
+
+	credentials, err := a.credentials(req.Method, req.URL.RequestURI())
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", credentials)
+	return nil
+}
+
+// Refresh implements Authenticator.Refresh.
+// It returns true if response contains Digest challenge.
+func (a *DigestAuthenticator) Refresh(
+	client Client, req *http.Request, resp *http.Response,
+) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, header := range resp.Header["Www-Authenticate"] {
+		scheme, params := splitAuthHeader(header)
+		if !strings.EqualFold(scheme, "Digest") {
+			continue
+		}
+
+		challenge := parseAuthParams(params)
+		if challenge["nonce"] == "" {
+			return false, errors.New("digest challenge without nonce")
+		}
+
+		a.challenge = challenge
+		a.nc = 0
+
+		return true, nil
+	}
+
+	a.challenge = nil
+	return false, nil
+}
+
+func (a *DigestAuthenticator) credentials(method, uri string) (string, error) {
+	algorithm := a.challenge["algorithm"]
+	if algorithm == "" {
+		algorithm = "MD5"
+	}
+
+	var newHash func() hash.Hash
+	switch strings.TrimSuffix(strings.ToUpper(algorithm), "-SESS") {
+	case "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		return "", fmt.Errorf("unsupported digest algorithm %q", algorithm)
+	}
+
+	h := func(s string) string {
+		hasher := newHash()
+		_, _ = io.WriteString(hasher, s)
+		return hex.EncodeToString(hasher.Sum(nil))
+	}
+
+	var qop string
+	if a.challenge["qop"] != "" {
+		for _, q := range strings.Split(a.challenge["qop"], ",") {
+			if strings.TrimSpace(q) == "auth" {
+				qop = "auth"
+			}
+		}
+		if qop == "" {
+			return "", fmt.Errorf("unsupported digest qop %q", a.challenge["qop"])
+		}
+	}
+
+	realm := a.challenge["realm"]
+	nonce := a.challenge["nonce"]
+	nc := fmt.Sprintf("%08x", a.nc)
+
+	cnonce, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+
+	ha1 := h(a.username + ":" + realm + ":" + a.password)
+	if strings.HasSuffix(strings.ToUpper(algorithm), "-SESS") {
+		ha1 = h(ha1 + ":" + nonce + ":" + cnonce)
+	}
+
+	ha2 := h(method + ":" + uri)
+
+	var response string
+	if qop != "" {
+		response = h(strings.Join([]string{ha1, nonce, nc, cnonce, qop, ha2}, ":"))
+	} else {
+		response = h(ha1 + ":" + nonce + ":" + ha2)
+	}
+
+	params := []string{
+		fmt.Sprintf("username=%s", quoteAuthParam(a.username)),
+		fmt.Sprintf("realm=%s", quoteAuthParam(realm)),
+		fmt.Sprintf("nonce=%s", quoteAuthParam(nonce)),
+		fmt.Sprintf("uri=%s", quoteAuthParam(uri)),
+		fmt.Sprintf("algorithm=%s", algorithm),
+		fmt.Sprintf("response=%s", quoteAuthParam(response)),
+	}
+	if qop != "" {
+		params = append(params,
+			fmt.Sprintf("qop=%s", qop),
+			fmt.Sprintf("nc=%s", nc),
+			fmt.Sprintf("cnonce=%s", quoteAuthParam(cnonce)))
+	}
+	if opaque, ok := a.challenge["opaque"]; ok {
+		params = append(params, fmt.Sprintf("opaque=%s", quoteAuthParam(opaque)))
+	}
+
+	return "Digest " + strings.Join(params, ", "), nil
+}
+
+// OAuth2Opts defines parameters of OAuth 2.0 authorization server.
+type OAuth2Opts struct {
+	// TokenURL is the token endpoint. If it's relative, it's resolved
+	// against the URL of the authenticated request.
+	TokenURL string
+
+	// ClientID and ClientSecret are client credentials.
+	ClientID     string
+	ClientSecret string
+
+	// ClientAuthInBody sends client credentials in request body instead
+	// of HTTP Basic authentication.
+	ClientAuthInBody bool
+
+	// Username and Password are resource owner credentials,
+	// used only by password grant.
+	Username string
+	Password string
+
+	// Scopes is the list of requested scopes. May be empty.
+	Scopes []string
+
+	// Params are additional token request parameters, e.g. "audience".
+	Params map[string]string
+}
+
+// OAuth2Authenticator obtains access token from OAuth 2.0 authorization
+// server using client credentials or password grant, as defined in
+// RFC 6749, and sends it in "Authorization" header.
+//
+// The token is cached until it expires. When server responds with
+// "401 Unauthorized", the token is refreshed using refresh token, if
+// authorization server issued one, or obtained again otherwise.
+//
+// Token requests are sent using the same client as the authenticated
+// request, so a local stand-in authorization server or Binder may be used.
+type OAuth2Authenticator struct {
+	opts      OAuth2Opts
+	grantType string
+
+	mu           sync.Mutex
+	accessToken  string
+	tokenType    string
+	refreshToken string
+	expiry       time.Time
+}
+
+// NewOAuth2ClientCredentials returns a new OAuth2Authenticator that uses
+// client credentials grant.
+//
+// Example:
+//  auth := NewOAuth2ClientCredentials(OAuth2Opts{
+//      TokenURL:     "http://auth.example.com/token",
+//      ClientID:     "my-client",
+//      ClientSecret: "secret",
+//      Scopes:       []string{"read", "write"},
+//  })
+func NewOAuth2ClientCredentials(opts OAuth2Opts) *OAuth2Authenticator {
+	return &OAuth2Authenticator{opts: opts, grantType: "client_credentials"}
+}
+
+// NewOAuth2Password returns a new OAuth2Authenticator that uses resource
+// owner password credentials grant.
+//
+// Example:
+//  auth := NewOAuth2Password(OAuth2Opts{
+//      TokenURL: "/oauth/token",
+//      ClientID: "my-client",
+//      Username: "john",
+//      Password: "secret",
+//  })
+func NewOAuth2Password(opts OAuth2Opts) *OAuth2Authenticator {
+	return &OAuth2Authenticator{opts: opts, grantType: "password"}
+}
+
+// Authenticate implements Authenticator.Authenticate.
+// It obtains a new token if there is no cached token or it has expired.
+func (a *OAuth2Authenticator) Authenticate(client Client, req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.accessToken == "" ||
+		(!a.expiry.IsZero() && !time.Now().Before(a.expiry)) {
+		if err := a.obtainToken(client, req); err != nil {
+			return err
+		}
+	}
+
+	req.Header.Set("Authorization", a.tokenType+" "+a.accessToken)
+	return nil
+}
+
+// Refresh implements Authenticator.Refresh.
+// It drops cached token and obtains a new one.
+func (a *OAuth2Authenticator) Refresh(
+	client Client, req *http.Request, resp *http.Response,
+) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.accessToken = ""
+
+	if err := a.obtainToken(client, req); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (a *OAuth2Authenticator) obtainToken(client Client, req *http.Request) error {
+	if a.refreshToken != "" {
+		params := url.Values{}
+		params.Set("grant_type", "refresh_token")
+		params.Set("refresh_token", a.refreshToken)
+
+		a.refreshToken = ""
+
+		if err := a.requestToken(client, req, params); err == nil {
+			return nil
+		}
+	}
+
+	params := url.Values{}
+	params.Set("grant_type", a.grantType)
+	if a.grantType == "password" {
+		params.Set("username", a.opts.Username)
+		params.Set("password", a.opts.Password)
+	}
+	if len(a.opts.Scopes) != 0 {
+		params.Set("scope", strings.Join(a.opts.Scopes, " "))
+	}
+	for k, v := range a.opts.Params {
+		params.Set(k, v)
+	}
+
+	return a.requestToken(client, req, params)
+}
+
+func (a *OAuth2Authenticator) requestToken(
+	client Client, req *http.Request, params url.Values,
+) error {
+	tokenURL, err := url.Parse(a.opts.TokenURL)
+	if err != nil {
+		return err
+	}
+	if req.URL != nil {
+		tokenURL = req.URL.ResolveReference(tokenURL)
+	}
+
+	if a.opts.ClientAuthInBody {
+		params.Set("client_id", a.opts.ClientID)
+		if a.opts.ClientSecret != "" {
+			params.Set("client_secret", a.opts.ClientSecret)
+		}
+	}
+
+	tokenReq, err := http.NewRequest(
+		"POST", tokenURL.String(), strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+
+	tokenReq = tokenReq.WithContext(req.Context())
+	tokenReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	tokenReq.Header.Set("Accept", "application/json")
+
+	if !a.opts.ClientAuthInBody {
+		tokenReq.SetBasicAuth(
+			url.QueryEscape(a.opts.ClientID), url.QueryEscape(a.opts.ClientSecret))
+	}
+
+	resp, err := client.Do(tokenReq)
+	if err != nil {
+		return fmt.Errorf("oauth2 token request failed: %s", err.Error())
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("oauth2 token request failed: %s", err.Error())
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oauth2 token request failed: %s: %s",
+			statusCodeText(resp.StatusCode), strings.TrimSpace(string(body)))
+	}
+
+	var token struct {
+		AccessToken  string      `json:"access_token"`
+		TokenType    string      `json:"token_type"`
+		RefreshToken string      `json:"refresh_token"`
+		ExpiresIn    json.Number `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return fmt.Errorf("oauth2 token response is invalid: %s", err.Error())
+	}
+
+	if token.AccessToken == "" {
+		return errors.New("oauth2 token response has no access_token")
+	}
+
+	a.accessToken = token.AccessToken
+	a.refreshToken = token.RefreshToken
+
+	a.tokenType = token.TokenType
+	if a.tokenType == "" || strings.EqualFold(a.tokenType, "bearer") {
+		a.tokenType = "Bearer"
+	}
+
+	a.expiry = time.Time{}
+	if token.ExpiresIn != "" {
+		if secs, err := strconv.ParseInt(string(token.ExpiresIn), 10, 64); err == nil &&
+			secs > 0 {
+			a.expiry = time.Now().Add(time.Duration(secs) * time.Second)
+		}
+	}
+
+	return nil
+}
+
+// splitAuthHeader splits WWW-Authenticate header into scheme and parameters.
+func splitAuthHeader(header string) (string, string) {
+	header = strings.TrimSpace(header)
+	if n := strings.IndexByte(header, ' '); n >= 0 {
+		return header[:n], header[n+1:]
+	}
+	return header, ""
+}
+
+// parseAuthParams parses comma-separated list of auth parameters, where
+// values may be tokens or quoted strings, as defined in RFC 7235.
+func parseAuthParams(s string) map[string]string {
+	params := map[string]string{}
+
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if s == "" {
+			return params
+		}
+
+		n := strings.IndexByte(s, '=')
+		if n < 0 {
+			return params
+		}
+
+		key := strings.ToLower(strings.TrimSpace(s[:n]))
+		s = strings.TrimLeft(s[n+1:], " \t")
+
+		var value strings.Builder
+		if strings.HasPrefix(s, `"`) {
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				value.WriteByte(s[i])
+			}
+			if i < len(s) {
+				i++
+			}
+			s = s[i:]
+		} else {
+			n := strings.IndexByte(s, ',')
+			if n < 0 {
+				n = len(s)
+			}
+			value.WriteString(strings.TrimSpace(s[:n]))
+			s = s[n:]
+		}
+
+		params[key] = value.String()
+	}
+}
+
+func quoteAuthParam(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
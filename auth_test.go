@@ -0,0 +1,537 @@
+package httpexpect
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthBearer(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+
+	config := newBinderConfig(t, handler)
+	config.Reporter = NewAssertReporter(t)
+	config.Authenticator = NewBearerAuthenticator("secret")
+
+	e := WithConfig(config)
+
+	e.GET("/").Expect().
+		Status(http.StatusOK).
+		Attempts().Equal(1)
+
+	e.GET("/").WithAuthenticator(NewBearerAuthenticator("bad")).Expect().
+		Status(http.StatusUnauthorized).
+		Attempts().Equal(1)
+
+	e.GET("/").WithAuthenticator(nil).Expect().
+		Status(http.StatusUnauthorized)
+}
+
+func TestAuthAPIKey(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "secret" &&
+			r.URL.Query().Get("api_key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(r.URL.RawQuery))
+	})
+
+	config := newBinderConfig(t, handler)
+	config.Reporter = NewAssertReporter(t)
+	config.Authenticator = NewAPIKeyAuthenticator(APIKeyInHeader, "X-API-Key", "secret")
+
+	e := WithConfig(config)
+
+	e.GET("/").Expect().
+		Status(http.StatusOK).
+		Body().Empty()
+
+	e.GET("/").
+		WithQuery("foo", "bar").
+		WithAuthenticator(NewAPIKeyAuthenticator(APIKeyInQuery, "api_key", "secret")).
+		Expect().
+		Status(http.StatusOK).
+		Body().Equal("api_key=secret&foo=bar")
+
+	e.GET("/").
+		WithAuthenticator(NewAPIKeyAuthenticator(APIKeyInQuery, "X-API-Key", "secret")).
+		Expect().
+		Status(http.StatusUnauthorized)
+
+	resp := NewRequest(newBinderConfig(t, handler), "GET", "/").
+		WithAuthenticator(NewAPIKeyAuthenticator(APIKeyLocation(-1), "key", "secret")).
+		Expect()
+
+	resp.chain.assertFailed(t)
+}
+
+type digestTestServer struct {
+	t         *testing.T
+	algorithm string
+	qop       string
+	username  string
+	password  string
+
+	mu         sync.Mutex
+	challenges int
+	lastNC     string
+}
+
+func (s *digestTestServer) hash(v string) string {
+	var h hash.Hash
+	if strings.HasPrefix(s.algorithm, "SHA-256") {
+		h = sha256.New()
+	} else {
+		h = md5.New()
+	}
+	_, _ = io.WriteString(h, v)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (s *digestTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	challenge := func() {
+		s.challenges++
+		header := fmt.Sprintf(`Digest realm="test", nonce="n%d", opaque="op"`,
+			s.challenges)
+		if s.algorithm != "" {
+			header += ", algorithm=" + s.algorithm
+		}
+		if s.qop != "" {
+			header += fmt.Sprintf(`, qop="%s"`, s.qop)
+		}
+		w.Header().Add("WWW-Authenticate", `Basic realm="test"`)
+		w.Header().Add("WWW-Authenticate", header)
+		w.WriteHeader(http.StatusUnauthorized)
+	}
+
+	scheme, rest := splitAuthHeader(r.Header.Get("Authorization"))
+	if scheme != "Digest" {
+		challenge()
+		return
+	}
+
+	params := parseAuthParams(rest)
+
+	assert.Equal(s.t, s.username, params["username"])
+	assert.Equal(s.t, "test", params["realm"])
+	assert.Equal(s.t, "op", params["opaque"])
+	assert.Equal(s.t, r.URL.RequestURI(), params["uri"])
+
+	nonce := fmt.Sprintf("n%d", s.challenges)
+	if params["nonce"] != nonce {
+		challenge()
+		return
+	}
+
+	ha1 := s.hash(s.username + ":test:" + s.password)
+	if strings.HasSuffix(s.algorithm, "-sess") {
+		ha1 = s.hash(ha1 + ":" + nonce + ":" + params["cnonce"])
+	}
+	ha2 := s.hash(r.Method + ":" + params["uri"])
+
+	var expected string
+	if s.qop != "" {
+		assert.Equal(s.t, "auth", params["qop"])
+		s.lastNC = params["nc"]
+		expected = s.hash(strings.Join([]string{
+			ha1, nonce, params["nc"], params["cnonce"], "auth", ha2,
+		}, ":"))
+	} else {
+		expected = s.hash(ha1 + ":" + nonce + ":" + ha2)
+	}
+
+	if params["response"] != expected {
+		challenge()
+		return
+	}
+}
+
+func TestAuthDigest(t *testing.T) {
+	for _, server := range []*digestTestServer{
+		{algorithm: "", qop: ""},
+		{algorithm: "MD5", qop: "auth"},
+		{algorithm: "MD5-sess", qop: "auth,auth-int"},
+		{algorithm: "SHA-256", qop: "auth"},
+		{algorithm: "SHA-256-sess", qop: "auth"},
+	} {
+		t.Run(server.algorithm+"/"+server.qop, func(t *testing.T) {
+			server.t = t
+			server.username = `jo"hn`
+			server.password = "secret"
+
+			auth := NewDigestAuthenticator(`jo"hn`, "secret")
+
+			config := newBinderConfig(t, server)
+			config.Reporter = NewAssertReporter(t)
+			config.Authenticator = auth
+
+			e := WithConfig(config)
+
+			e.GET("/path").WithQuery("a", "b").Expect().
+				Status(http.StatusOK).
+				Attempts().Equal(2)
+
+			e.POST("/path").WithText("body").Expect().
+				Status(http.StatusOK).
+				Attempts().Equal(1)
+
+			assert.Equal(t, 1, server.challenges)
+			if server.qop != "" {
+				assert.Equal(t, "00000002", server.lastNC)
+			}
+
+			e.GET("/path").
+				WithAuthenticator(NewDigestAuthenticator(`jo"hn`, "bad")).
+				Expect().
+				Status(http.StatusUnauthorized).
+				Attempts().Equal(2)
+		})
+	}
+}
+
+func TestAuthDigestUnsupported(t *testing.T) {
+	for _, server := range []*digestTestServer{
+		{algorithm: "SHA-512-256"},
+		{qop: "auth-int"},
+	} {
+		server.t = t
+
+		resp := NewRequest(newBinderConfig(t, server), "GET", "/").
+			WithAuthenticator(NewDigestAuthenticator("john", "secret")).
+			Expect()
+
+		resp.chain.assertFailed(t)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	config := newBinderConfig(t, handler)
+	config.Reporter = NewAssertReporter(t)
+
+	NewRequest(config, "GET", "/").
+		WithAuthenticator(NewDigestAuthenticator("john", "secret")).
+		Expect().
+		Status(http.StatusUnauthorized).
+		Attempts().Equal(1)
+}
+
+type oauth2TestServer struct {
+	t            *testing.T
+	grantType    string
+	refreshToken bool
+
+	mu            sync.Mutex
+	tokenRequests []string
+	current       string
+}
+
+func (s *oauth2TestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/oauth/token":
+		assert.Equal(s.t, "POST", r.Method)
+		assert.Equal(s.t, "application/x-www-form-urlencoded",
+			r.Header.Get("Content-Type"))
+
+		_ = r.ParseForm()
+
+		grant := r.PostForm.Get("grant_type")
+		s.tokenRequests = append(s.tokenRequests, grant)
+
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok {
+			clientID = r.PostForm.Get("client_id")
+			clientSecret = r.PostForm.Get("client_secret")
+		}
+		if clientID != "client" || clientSecret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error": "invalid_client"}`))
+			return
+		}
+
+		switch grant {
+		case s.grantType:
+			assert.Equal(s.t, "read write", r.PostForm.Get("scope"))
+			assert.Equal(s.t, "api", r.PostForm.Get("audience"))
+			if grant == "password" {
+				assert.Equal(s.t, "john", r.PostForm.Get("username"))
+				assert.Equal(s.t, "pass", r.PostForm.Get("password"))
+			}
+		case "refresh_token":
+			if r.PostForm.Get("refresh_token") != "refresh-"+s.current {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error": "invalid_grant"}`))
+				return
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		s.current = fmt.Sprintf("token%d", len(s.tokenRequests))
+
+		w.Header().Set("Content-Type", "application/json")
+		if s.refreshToken {
+			_, _ = fmt.Fprintf(w,
+				`{"access_token": %q, "token_type": "bearer", "expires_in": 3600,`+
+					` "refresh_token": %q}`, s.current, "refresh-"+s.current)
+		} else {
+			_, _ = fmt.Fprintf(w,
+				`{"access_token": %q, "token_type": "bearer", "expires_in": 3600}`,
+				s.current)
+		}
+
+	default:
+		if s.current == "" || r.Header.Get("Authorization") != "Bearer "+s.current {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}
+}
+
+func (s *oauth2TestServer) revoke() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current = "revoked"
+}
+
+func (s *oauth2TestServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.tokenRequests...)
+}
+
+func TestAuthOAuth2ClientCredentials(t *testing.T) {
+	for _, inBody := range []bool{false, true} {
+		server := &oauth2TestServer{t: t, grantType: "client_credentials"}
+
+		auth := NewOAuth2ClientCredentials(OAuth2Opts{
+			TokenURL:         "/oauth/token",
+			ClientID:         "client",
+			ClientSecret:     "s3cret",
+			ClientAuthInBody: inBody,
+			Scopes:           []string{"read", "write"},
+			Params:           map[string]string{"audience": "api"},
+		})
+
+		config := newBinderConfig(t, server)
+		config.Reporter = NewAssertReporter(t)
+		config.Authenticator = auth
+
+		e := WithConfig(config)
+
+		e.POST("/api").WithText("hello").Expect().
+			Status(http.StatusOK).
+			Body().Equal("hello")
+
+		e.GET("/api").Expect().
+			Status(http.StatusOK).
+			Attempts().Equal(1)
+
+		assert.Equal(t, []string{"client_credentials"}, server.requests())
+
+		server.revoke()
+
+		resp := e.POST("/api").WithText("again").Expect()
+
+		resp.Status(http.StatusOK)
+		resp.Attempts().Equal(2)
+		resp.Body().Equal("again")
+
+		assert.Equal(t,
+			[]string{"client_credentials", "client_credentials"}, server.requests())
+
+		auth.expiry = time.Now().Add(-time.Second)
+
+		e.GET("/api").Expect().
+			Status(http.StatusOK).
+			Attempts().Equal(1)
+
+		assert.Equal(t, 3, len(server.requests()))
+	}
+}
+
+func TestAuthOAuth2Password(t *testing.T) {
+	server := &oauth2TestServer{
+		t:            t,
+		grantType:    "password",
+		refreshToken: true,
+	}
+
+	auth := NewOAuth2Password(OAuth2Opts{
+		TokenURL:     "http://example.com/oauth/token",
+		ClientID:     "client",
+		ClientSecret: "s3cret",
+		Username:     "john",
+		Password:     "pass",
+		Scopes:       []string{"read", "write"},
+		Params:       map[string]string{"audience": "api"},
+	})
+
+	config := newBinderConfig(t, server)
+	config.Reporter = NewAssertReporter(t)
+	config.Authenticator = auth
+
+	e := WithConfig(config)
+
+	e.GET("/api").Expect().Status(http.StatusOK)
+
+	assert.Equal(t, []string{"password"}, server.requests())
+
+	server.mu.Lock()
+	server.current = "token-rotated"
+	server.mu.Unlock()
+
+	// refresh token is no longer valid, so password grant is used
+	e.GET("/api").Expect().
+		Status(http.StatusOK).
+		Attempts().Equal(2)
+
+	assert.Equal(t,
+		[]string{"password", "refresh_token", "password"}, server.requests())
+
+	// refresh token is still valid, but access token is expired
+	auth.expiry = time.Now().Add(-time.Second)
+
+	e.GET("/api").Expect().
+		Status(http.StatusOK).
+		Attempts().Equal(1)
+
+	assert.Equal(t,
+		[]string{"password", "refresh_token", "password", "refresh_token"},
+		server.requests())
+}
+
+func TestAuthOAuth2Failed(t *testing.T) {
+	server := &oauth2TestServer{t: t, grantType: "client_credentials"}
+
+	auth := NewOAuth2ClientCredentials(OAuth2Opts{
+		TokenURL:     "/oauth/token",
+		ClientID:     "client",
+		ClientSecret: "bad",
+	})
+
+	resp := NewRequest(newBinderConfig(t, server), "GET", "/api").
+		WithAuthenticator(auth).
+		Expect()
+	resp.chain.assertFailed(t)
+
+	auth = NewOAuth2ClientCredentials(OAuth2Opts{
+		TokenURL: "/missing",
+	})
+
+	resp = NewRequest(newBinderConfig(t, server), "GET", "/api").
+		WithAuthenticator(auth).
+		Expect()
+	resp.chain.assertFailed(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"token_type": "bearer"}`))
+	})
+
+	resp = NewRequest(newBinderConfig(t, handler), "GET", "/api").
+		WithAuthenticator(auth).
+		Expect()
+	resp.chain.assertFailed(t)
+}
+
+type countingAuthenticator struct {
+	refreshOK  bool
+	refreshErr error
+
+	authenticated int
+	refreshed     int
+}
+
+func (a *countingAuthenticator) Authenticate(client Client, req *http.Request) error {
+	a.authenticated++
+	return nil
+}
+
+func (a *countingAuthenticator) Refresh(
+	client Client, req *http.Request, resp *http.Response,
+) (bool, error) {
+	a.refreshed++
+	return a.refreshOK, a.refreshErr
+}
+
+func TestAuthRefreshOnce(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	auth := &countingAuthenticator{refreshOK: true}
+
+	config := newBinderConfig(t, handler)
+	config.Reporter = NewAssertReporter(t)
+
+	NewRequest(config, "GET", "/").
+		WithAuthenticator(auth).
+		Expect().
+		Status(http.StatusUnauthorized).
+		Attempts().Equal(2)
+
+	assert.Equal(t, 2, auth.authenticated)
+	assert.Equal(t, 1, auth.refreshed)
+
+	auth = &countingAuthenticator{refreshOK: true}
+
+	NewRequest(config, "POST", "/").
+		WithAuthenticator(auth).
+		WithChunked(ioutil.NopCloser(strings.NewReader("body"))).
+		Expect().
+		Status(http.StatusUnauthorized).
+		Attempts().Equal(1)
+
+	assert.Equal(t, 1, auth.authenticated)
+	assert.Equal(t, 0, auth.refreshed)
+
+	auth = &countingAuthenticator{refreshErr: fmt.Errorf("refresh error")}
+
+	resp := NewRequest(newBinderConfig(t, handler), "GET", "/").
+		WithAuthenticator(auth).
+		Expect()
+	resp.chain.assertFailed(t)
+}
+
+func TestAuthParseParams(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"realm":     "a, b",
+		"nonce":     `x"y\z`,
+		"algorithm": "MD5",
+		"stale":     "TRUE",
+		"empty":     "",
+	}, parseAuthParams(
+		`realm="a, b", nonce="x\"y\\z",algorithm=MD5 , Stale=TRUE, empty=""`))
+
+	assert.Equal(t, map[string]string{}, parseAuthParams(""))
+	assert.Equal(t, map[string]string{"a": "1"}, parseAuthParams("a=1, bad"))
+	assert.Equal(t, map[string]string{"a": "unterminated"},
+		parseAuthParams(`a="unterminated`))
+}
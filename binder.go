@@ -2,12 +2,17 @@ package httpexpect
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"mime"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 
 	"github.com/valyala/fasthttp"
 )
@@ -18,6 +23,16 @@ import (
 // Binder emulates network communication by invoking given http.Handler
 // directly. It passes httptest.ResponseRecorder as http.ResponseWriter
 // to the handler, and then constructs http.Response from recorded data.
+//
+// If request context is done before handler returns, RoundTrip returns
+// context error without waiting for the handler.
+//
+// If request has "Accept: text/event-stream" header, handler is invoked
+// in a separate goroutine. If it flushes the response before returning,
+// RoundTrip returns response as soon as it's flushed, and the rest of the
+// body is streamed to the client as handler writes it. This allows to test
+// streaming handlers, e.g. Server-Sent Events. When response body is closed
+// by the client, the context of the handler request is cancelled.
 type Binder struct {
 	// HTTP handler invoked for every request.
 	Handler http.Handler
@@ -57,9 +72,18 @@ func (binder Binder) RoundTrip(req *http.Request) (*http.Response, error) {
 		req.RequestURI = req.URL.RequestURI()
 	}
 
+	if acceptsEventStream(req) {
+		return binder.roundTripStream(req)
+	}
+
 	recorder := httptest.NewRecorder()
 
-	binder.Handler.ServeHTTP(recorder, req)
+	err := runHandler(req.Context(), func() {
+		binder.Handler.ServeHTTP(recorder, req)
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	resp := http.Response{
 		Request:    req,
@@ -79,6 +103,202 @@ func (binder Binder) RoundTrip(req *http.Request) (*http.Response, error) {
 	return &resp, nil
 }
 
+// roundTripStream invokes handler in a separate goroutine and returns
+// response when handler either returns or flushes it.
+func (binder Binder) roundTripStream(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+
+	// handler request is created before starting the handler, since the
+	// caller may reuse req after RoundTrip returns, e.g. on timeout
+	handlerReq := req.WithContext(ctx)
+
+	writer := &binderWriter{
+		recorder: httptest.NewRecorder(),
+		flushed:  make(chan struct{}),
+		body:     &binderBody{cancel: cancel},
+	}
+	writer.body.cond = sync.NewCond(&writer.body.mu)
+
+	done := make(chan interface{}, 1)
+
+	go func() {
+		defer func() {
+			p := recover()
+			if p != nil && writer.isStreaming() {
+				writer.body.finish(fmt.Errorf("handler panicked: %v", p))
+				p = nil
+			} else {
+				writer.body.finish(io.EOF)
+			}
+			done <- p
+		}()
+		binder.Handler.ServeHTTP(writer, handlerReq)
+	}()
+
+	select {
+	case p := <-done:
+		cancel()
+		if p != nil {
+			panic(p) // re-panic in the caller goroutine
+		}
+
+	case <-writer.flushed:
+
+	case <-req.Context().Done():
+		cancel()
+		return nil, req.Context().Err()
+	}
+
+	recorder := writer.recorder
+
+	resp := http.Response{
+		Request:    req,
+		StatusCode: recorder.Code,
+		Status:     http.StatusText(recorder.Code),
+		Header:     writer.header,
+	}
+
+	if resp.Header == nil {
+		resp.Header = recorder.Result().Header
+	}
+
+	if recorder.Flushed {
+		resp.TransferEncoding = []string{"chunked"}
+		resp.Body = writer.body
+	} else if recorder.Body != nil {
+		resp.Body = ioutil.NopCloser(recorder.Body)
+	}
+
+	return &resp, nil
+}
+
+func acceptsEventStream(req *http.Request) bool {
+	for _, accept := range req.Header["Accept"] {
+		for _, item := range strings.Split(accept, ",") {
+			mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(item))
+			if err == nil && mediaType == "text/event-stream" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// binderWriter records response like httptest.ResponseRecorder until
+// handler flushes it, and then streams the rest of the body to binderBody.
+type binderWriter struct {
+	recorder *httptest.ResponseRecorder
+	header   http.Header
+	flushed  chan struct{}
+	body     *binderBody
+}
+
+func (w *binderWriter) Header() http.Header {
+	return w.recorder.Header()
+}
+
+func (w *binderWriter) WriteHeader(code int) {
+	if !w.isStreaming() {
+		w.recorder.WriteHeader(code)
+	}
+}
+
+func (w *binderWriter) Write(b []byte) (int, error) {
+	if w.isStreaming() {
+		return w.body.write(b)
+	}
+	return w.recorder.Write(b)
+}
+
+func (w *binderWriter) Flush() {
+	if w.isStreaming() {
+		return
+	}
+
+	w.recorder.Flush()
+	w.header = w.recorder.Result().Header
+
+	_, _ = w.body.write(w.recorder.Body.Bytes())
+
+	close(w.flushed)
+}
+
+func (w *binderWriter) isStreaming() bool {
+	select {
+	case <-w.flushed:
+		return true
+	default:
+		return false
+	}
+}
+
+// binderBody is an unbounded in-memory pipe used as streamed response body.
+type binderBody struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	buf    bytes.Buffer
+	err    error
+	closed bool
+	cancel context.CancelFunc
+}
+
+func (b *binderBody) write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	n, _ := b.buf.Write(p)
+	b.cond.Broadcast()
+
+	return n, nil
+}
+
+func (b *binderBody) finish(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err == nil {
+		b.err = err
+	}
+	b.cond.Broadcast()
+}
+
+// Read implements io.Reader.
+func (b *binderBody) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for b.buf.Len() == 0 && b.err == nil && !b.closed {
+		b.cond.Wait()
+	}
+
+	if b.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	if b.buf.Len() != 0 {
+		return b.buf.Read(p)
+	}
+
+	return 0, b.err
+}
+
+// Close implements io.Closer.
+func (b *binderBody) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	b.buf.Reset()
+	b.cond.Broadcast()
+	b.mu.Unlock()
+
+	b.cancel()
+
+	return nil
+}
+
 // FastBinder implements networkless http.RoundTripper attached directly
 // to fasthttp.RequestHandler.
 //
@@ -130,11 +350,42 @@ func (binder FastBinder) RoundTrip(stdreq *http.Request) (*http.Response, error)
 		}
 	}
 
-	binder.Handler(&ctx)
+	err := runHandler(stdreq.Context(), func() {
+		binder.Handler(&ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	return fast2std(stdreq, &ctx.Response), nil
 }
 
+func runHandler(ctx context.Context, handler func()) error {
+	if ctx.Done() == nil {
+		handler()
+		return nil
+	}
+
+	done := make(chan interface{}, 1)
+
+	go func() {
+		defer func() {
+			done <- recover()
+		}()
+		handler()
+	}()
+
+	select {
+	case p := <-done:
+		if p != nil {
+			panic(p) // re-panic in the caller goroutine
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func std2fast(stdreq *http.Request) *fasthttp.Request {
 	fastreq := &fasthttp.Request{}
 	fastreq.SetRequestURI(stdreq.URL.String())
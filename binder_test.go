@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"io/ioutil"
 	"net/http"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -167,6 +168,69 @@ func TestBinderChunked(t *testing.T) {
 	assert.Equal(t, []string{"chunked"}, resp.TransferEncoding)
 }
 
+func TestBinderSynchronous(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		runtime.Goexit()
+	})
+
+	client := &http.Client{
+		Transport: NewBinder(handler),
+	}
+
+	returned := false
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		req, _ := http.NewRequest("GET", "http://example.com/path", nil)
+		_, _ = client.Do(req)
+		returned = true
+	}()
+
+	<-done
+
+	// Goexit in handler terminates caller goroutine
+	assert.False(t, returned)
+}
+
+func TestBinderStreaming(t *testing.T) {
+	release := make(chan struct{})
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: 1\n\n"))
+		w.(http.Flusher).Flush()
+		<-release
+		_, _ = w.Write([]byte("data: 2\n\n"))
+	})
+
+	client := &http.Client{
+		Transport: NewBinder(handler),
+	}
+
+	req, _ := http.NewRequest("GET", "http://example.com/path", nil)
+	req.Header.Set("Accept", "text/html, text/event-stream")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+
+	line, err := reader.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "data: 1\n", line)
+
+	close(release)
+
+	rest, err := ioutil.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "\ndata: 2\n\n", string(rest))
+
+	assert.NoError(t, resp.Body.Close())
+}
+
 func TestFastBinder(t *testing.T) {
 	handler := func(ctx *fasthttp.RequestCtx) {
 		assert.Equal(t, "POST", string(ctx.Request.Header.Method()))
@@ -15,7 +15,7 @@ type Boolean struct {
 // Example:
 //  boolean := NewBoolean(t, true)
 func NewBoolean(reporter Reporter, value bool) *Boolean {
-	return &Boolean{makeChain(reporter), value}
+	return &Boolean{makeChain(reporter).root("Boolean"), value}
 }
 
 // Raw returns underlying value attached to Boolean.
@@ -33,6 +33,16 @@ func (b *Boolean) Path(path string) *Value {
 	return getPath(&b.chain, b.value, path)
 }
 
+// PathAll is similar to Value.PathAll.
+func (b *Boolean) PathAll(path string) *Array {
+	return getPathAll(&b.chain, b.value, path)
+}
+
+// Pointer is similar to Value.Pointer.
+func (b *Boolean) Pointer(pointer string) *Value {
+	return getPointer(&b.chain, b.value, pointer)
+}
+
 // Schema is similar to Value.Schema.
 func (b *Boolean) Schema(schema interface{}) *Boolean {
 	checkSchema(&b.chain, b.value, schema)
@@ -46,7 +56,8 @@ func (b *Boolean) Schema(schema interface{}) *Boolean {
 //  boolean.Equal(true)
 func (b *Boolean) Equal(value bool) *Boolean {
 	if !(b.value == value) {
-		b.chain.fail("expected boolean == %v, but got %v", value, b.value)
+		b.chain.failExpected(value, b.value,
+			"expected boolean == %v, but got %v", value, b.value)
 	}
 	return b
 }
@@ -0,0 +1,270 @@
+package httpexpect
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheControl provides methods to inspect directives of Cache-Control
+// header, as defined in RFC 9111.
+//
+// Directive names are case-insensitive. If header is repeated, directives
+// from all values are merged.
+type CacheControl struct {
+	chain      chain
+	directives map[string]string
+}
+
+// NewCacheControl returns a new CacheControl object given a reporter used
+// to report failures and Cache-Control header value to be inspected.
+//
+// reporter should not be nil.
+//
+// Example:
+//  cc := NewCacheControl(reporter, "public, max-age=3600")
+//  cc.Public().MaxAge().Equal(time.Hour)
+func NewCacheControl(reporter Reporter, value string) *CacheControl {
+	return makeCacheControl(makeChain(reporter).root("CacheControl"),
+		http.Header{"Cache-Control": {value}})
+}
+
+func makeCacheControl(chain chain, header http.Header) *CacheControl {
+	cc := &CacheControl{chain: chain, directives: map[string]string{}}
+
+	if chain.failed() {
+		return cc
+	}
+
+	for _, value := range header["Cache-Control"] {
+		for name, arg := range parseCacheDirectives(value) {
+			cc.directives[name] = arg
+		}
+	}
+
+	return cc
+}
+
+// parseCacheDirectives parses comma-separated list of directives, each
+// being a token with optional token or quoted-string argument.
+func parseCacheDirectives(s string) map[string]string {
+	directives := map[string]string{}
+
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		end := strings.IndexAny(s, "=,")
+
+		if end < 0 || s[end] == ',' {
+			if end < 0 {
+				end = len(s)
+			}
+			if name := strings.TrimSpace(s[:end]); name != "" {
+				directives[strings.ToLower(name)] = ""
+			}
+			s = strings.TrimPrefix(s[end:], ",")
+			continue
+		}
+
+		name := strings.ToLower(strings.TrimSpace(s[:end]))
+		s = strings.TrimSpace(s[end+1:])
+
+		var arg string
+		if strings.HasPrefix(s, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+			arg = b.String()
+			if i < len(s) {
+				i++
+			}
+			s = s[i:]
+			if n := strings.IndexByte(s, ','); n >= 0 {
+				s = s[n+1:]
+			} else {
+				s = ""
+			}
+		} else {
+			n := strings.IndexByte(s, ',')
+			if n < 0 {
+				n = len(s)
+			}
+			arg = strings.TrimSpace(s[:n])
+			s = strings.TrimPrefix(s[n:], ",")
+		}
+
+		if name != "" {
+			directives[name] = arg
+		}
+	}
+
+	return directives
+}
+
+// Raw returns parsed directives, mapping lower-cased directive name to
+// its argument, or to empty string if directive has no argument.
+func (cc *CacheControl) Raw() map[string]string {
+	return cc.directives
+}
+
+// Contains succeeds if Cache-Control contains given directive.
+//
+// Example:
+//  cc := NewCacheControl(t, "no-cache, no-store")
+//  cc.Contains("no-store")
+func (cc *CacheControl) Contains(directive string) *CacheControl {
+	if cc.chain.failed() {
+		return cc
+	}
+	if _, ok := cc.directives[strings.ToLower(directive)]; !ok {
+		cc.chain.fail("\nexpected Cache-Control containing %q directive, but got:\n %s",
+			directive, cc.format())
+	}
+	return cc
+}
+
+// NotContains succeeds if Cache-Control doesn't contain given directive.
+//
+// Example:
+//  cc := NewCacheControl(t, "no-cache, no-store")
+//  cc.NotContains("public")
+func (cc *CacheControl) NotContains(directive string) *CacheControl {
+	if cc.chain.failed() {
+		return cc
+	}
+	if _, ok := cc.directives[strings.ToLower(directive)]; ok {
+		cc.chain.fail(
+			"\nexpected Cache-Control not containing %q directive, but got:\n %s",
+			directive, cc.format())
+	}
+	return cc
+}
+
+// Directive returns a new String object that may be used to inspect
+// argument of given directive. Argument of directive without argument
+// is empty string.
+//
+// If directive is missing, failure is reported.
+//
+// Example:
+//  cc := NewCacheControl(t, `private="Set-Cookie"`)
+//  cc.Directive("private").Equal("Set-Cookie")
+func (cc *CacheControl) Directive(name string) *String {
+	if !cc.Contains(name).chain.failed() {
+		return &String{cc.chain.enter("Directive(%q)", name),
+			cc.directives[strings.ToLower(name)]}
+	}
+	return &String{cc.chain.enter("Directive(%q)", name), ""}
+}
+
+// NoStore succeeds if Cache-Control contains "no-store" directive.
+func (cc *CacheControl) NoStore() *CacheControl {
+	return cc.Contains("no-store")
+}
+
+// NoCache succeeds if Cache-Control contains "no-cache" directive.
+func (cc *CacheControl) NoCache() *CacheControl {
+	return cc.Contains("no-cache")
+}
+
+// Public succeeds if Cache-Control contains "public" directive.
+func (cc *CacheControl) Public() *CacheControl {
+	return cc.Contains("public")
+}
+
+// Private succeeds if Cache-Control contains "private" directive.
+func (cc *CacheControl) Private() *CacheControl {
+	return cc.Contains("private")
+}
+
+// MustRevalidate succeeds if Cache-Control contains "must-revalidate"
+// directive.
+func (cc *CacheControl) MustRevalidate() *CacheControl {
+	return cc.Contains("must-revalidate")
+}
+
+// Immutable succeeds if Cache-Control contains "immutable" directive.
+func (cc *CacheControl) Immutable() *CacheControl {
+	return cc.Contains("immutable")
+}
+
+// MaxAge returns a new Duration object that may be used to inspect
+// "max-age" directive.
+//
+// If directive is missing, returned Duration is not set. If directive
+// argument is not a non-negative integer, failure is reported.
+//
+// Example:
+//  cc := NewCacheControl(t, "max-age=60")
+//  cc.MaxAge().IsSet().Equal(time.Minute)
+func (cc *CacheControl) MaxAge() *Duration {
+	return cc.seconds("MaxAge()", "max-age")
+}
+
+// SMaxAge returns a new Duration object that may be used to inspect
+// "s-maxage" directive. See MaxAge for details.
+func (cc *CacheControl) SMaxAge() *Duration {
+	return cc.seconds("SMaxAge()", "s-maxage")
+}
+
+// StaleWhileRevalidate returns a new Duration object that may be used to
+// inspect "stale-while-revalidate" directive, as defined in RFC 5861.
+// See MaxAge for details.
+func (cc *CacheControl) StaleWhileRevalidate() *Duration {
+	return cc.seconds("StaleWhileRevalidate()", "stale-while-revalidate")
+}
+
+// StaleIfError returns a new Duration object that may be used to
+// inspect "stale-if-error" directive, as defined in RFC 5861.
+// See MaxAge for details.
+func (cc *CacheControl) StaleIfError() *Duration {
+	return cc.seconds("StaleIfError()", "stale-if-error")
+}
+
+func (cc *CacheControl) seconds(method, directive string) *Duration {
+	if cc.chain.failed() {
+		return &Duration{cc.chain.enter(method), nil}
+	}
+
+	arg, ok := cc.directives[directive]
+	if !ok {
+		return &Duration{cc.chain.enter(method), nil}
+	}
+
+	sec, err := strconv.ParseUint(arg, 10, 32)
+	if err != nil {
+		cc.chain.fail(
+			"\nexpected %q directive with delta-seconds argument, but got:\n %q",
+			directive, arg)
+		return &Duration{cc.chain.enter(method), nil}
+	}
+
+	value := time.Duration(sec) * time.Second
+	return &Duration{cc.chain.enter(method), &value}
+}
+
+func (cc *CacheControl) format() string {
+	names := make([]string, 0, len(cc.directives))
+	for name := range cc.directives {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		if arg := cc.directives[name]; arg != "" {
+			parts = append(parts, name+"="+strconv.Quote(arg))
+		} else {
+			parts = append(parts, name)
+		}
+	}
+	if len(parts) == 0 {
+		return "(empty)"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,190 @@
+package httpexpect
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheControlParse(t *testing.T) {
+	cases := map[string]map[string]string{
+		"":                 {},
+		"no-store":         {"no-store": ""},
+		" No-Cache , Foo ": {"no-cache": "", "foo": ""},
+		"max-age=60, s-maxage = 120": {
+			"max-age":  "60",
+			"s-maxage": "120",
+		},
+		`private="Set-Cookie, X-Foo", max-age=0`: {
+			"private": "Set-Cookie, X-Foo",
+			"max-age": "0",
+		},
+		`ext="a\"b", public`: {
+			"ext":    `a"b`,
+			"public": "",
+		},
+		`ext="unterminated`: {"ext": "unterminated"},
+		",,public,,":        {"public": ""},
+	}
+
+	for value, expected := range cases {
+		assert.Equal(t, expected, parseCacheDirectives(value), value)
+	}
+}
+
+func TestCacheControlDirectives(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	cc := NewCacheControl(reporter,
+		"public, max-age=3600, stale-while-revalidate=60, immutable")
+
+	cc.Public().Immutable()
+	cc.chain.assertOK(t)
+
+	cc.Contains("MAX-AGE")
+	cc.chain.assertOK(t)
+
+	cc.NotContains("no-store")
+	cc.chain.assertOK(t)
+
+	cc.MaxAge().Equal(time.Hour)
+	cc.StaleWhileRevalidate().Equal(time.Minute)
+	cc.SMaxAge().NotSet()
+	cc.StaleIfError().NotSet()
+	cc.chain.assertOK(t)
+
+	cc.Directive("max-age").Equal("3600")
+	cc.Directive("public").Empty()
+	cc.chain.assertOK(t)
+
+	assert.Equal(t, map[string]string{
+		"public":                 "",
+		"max-age":                "3600",
+		"stale-while-revalidate": "60",
+		"immutable":              "",
+	}, cc.Raw())
+
+	for _, fn := range []func(){
+		func() { cc.NoStore() },
+		func() { cc.NoCache() },
+		func() { cc.Private() },
+		func() { cc.MustRevalidate() },
+		func() { cc.NotContains("public") },
+		func() { cc.Directive("private") },
+	} {
+		fn()
+		cc.chain.assertFailed(t)
+		cc.chain.reset()
+	}
+
+	cc = NewCacheControl(reporter, "max-age=soon")
+
+	cc.MaxAge()
+	cc.chain.assertFailed(t)
+}
+
+func TestCacheControlResponse(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	resp := NewResponse(reporter, &http.Response{
+		Header: http.Header{
+			"Cache-Control": {"private, no-cache", "max-age=0"},
+			"Etag":          {`W/"v1"`},
+			"Last-Modified": {"Tue, 20 Apr 2021 02:07:55 GMT"},
+		},
+	})
+
+	resp.CacheControl().Private().NoCache().MaxAge().Equal(0)
+	resp.ETag().Equal(`W/"v1"`)
+	resp.LastModified().Equal(time.Date(2021, 4, 20, 2, 7, 55, 0, time.UTC))
+	resp.chain.assertOK(t)
+
+	resp = NewResponse(reporter, &http.Response{
+		Header: http.Header{
+			"Last-Modified": {"yesterday"},
+		},
+	})
+
+	resp.CacheControl().NotContains("no-store")
+	resp.chain.assertOK(t)
+
+	resp.ETag()
+	resp.chain.assertFailed(t)
+	resp.chain.reset()
+
+	resp.LastModified()
+	resp.chain.assertFailed(t)
+	resp.chain.reset()
+
+	resp = NewResponse(reporter, &http.Response{})
+
+	resp.LastModified()
+	resp.chain.assertFailed(t)
+}
+
+func TestCacheConditionalRequest(t *testing.T) {
+	modtime := time.Date(2021, 4, 20, 2, 7, 55, 0, time.UTC)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "data.txt", modtime, strings.NewReader("hello"))
+	})
+
+	e := WithConfig(Config{
+		BaseURL:  "http://example.com",
+		Reporter: NewAssertReporter(t),
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+	})
+
+	resp := e.GET("/").
+		Expect().
+		Status(http.StatusOK)
+
+	resp.CacheControl().NoCache()
+	resp.Body().Equal("hello")
+
+	e.GET("/").
+		WithConditionalFrom(resp).
+		Expect().
+		Status(http.StatusNotModified).
+		ETag().Equal(resp.ETag().Raw())
+
+	req := e.PUT("/").WithConditionalFrom(resp)
+
+	assert.Equal(t, `"v1"`, req.http.Header.Get("If-Match"))
+	assert.Equal(t, "Tue, 20 Apr 2021 02:07:55 GMT",
+		req.http.Header.Get("If-Unmodified-Since"))
+	assert.Empty(t, req.http.Header.Get("If-None-Match"))
+}
+
+func TestCacheConditionalRequestFailed(t *testing.T) {
+	config := Config{
+		RequestFactory: DefaultRequestFactory{},
+		Reporter:       newMockReporter(t),
+		Client:         &mockClient{},
+	}
+
+	req := NewRequest(config, "GET", "/")
+	req.WithConditionalFrom(NewResponse(newMockReporter(t), &http.Response{}))
+	req.chain.assertFailed(t)
+
+	req = NewRequest(config, "GET", "/")
+	req.WithConditionalFrom(nil)
+	req.chain.assertFailed(t)
+
+	req = NewRequest(config, "GET", "/")
+	req.WithConditionalFrom(NewResponse(newMockReporter(t), &http.Response{
+		Header: http.Header{"Last-Modified": {"Tue, 20 Apr 2021 02:07:55 GMT"}},
+	}))
+	req.chain.assertOK(t)
+
+	assert.Equal(t, "Tue, 20 Apr 2021 02:07:55 GMT",
+		req.http.Header.Get("If-Modified-Since"))
+	assert.Empty(t, req.http.Header.Get("If-None-Match"))
+}
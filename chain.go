@@ -1,12 +1,89 @@
 package httpexpect
 
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
 type chain struct {
 	reporter Reporter
 	failbit  bool
+	path     []string
+	jsonPath string
+	request  *http.Request
+	response *http.Response
 }
 
 func makeChain(reporter Reporter) chain {
-	return chain{reporter, false}
+	return chain{reporter: reporter}
+}
+
+// enter returns a copy of the chain with a new segment appended to its path.
+// Called when a new object is derived from the object owning the chain.
+func (c chain) enter(segment string, args ...interface{}) chain {
+	if len(args) != 0 {
+		segment = fmt.Sprintf(segment, args...)
+	}
+	path := make([]string, len(c.path), len(c.path)+1)
+	copy(path, c.path)
+	c.path = append(path, segment)
+	return c
+}
+
+// root returns a copy of the chain with path reset to given segment.
+func (c chain) root(segment string) chain {
+	c.path = []string{segment}
+	return c
+}
+
+// rootJSON returns a copy of the chain with JSON path reset to "$".
+// Called when a new JSON document is entered.
+func (c chain) rootJSON() chain {
+	c.jsonPath = "$"
+	return c
+}
+
+// enterKey returns a copy of the chain with given object key appended
+// to its JSON path, e.g. "$.data" becomes "$.data.id".
+func (c chain) enterKey(key string) chain {
+	if c.jsonPath == "" {
+		return c
+	}
+	if jsonIdentRegexp.MatchString(key) {
+		c.jsonPath += "." + key
+	} else {
+		c.jsonPath += "['" + jsonKeyEscaper.Replace(key) + "']"
+	}
+	return c
+}
+
+// enterIndex returns a copy of the chain with given array index appended
+// to its JSON path, e.g. "$.data" becomes "$.data[3]".
+func (c chain) enterIndex(index int) chain {
+	if c.jsonPath == "" {
+		return c
+	}
+	c.jsonPath += "[" + strconv.Itoa(index) + "]"
+	return c
+}
+
+// enterQuery returns a copy of the chain with given JSONPath query appended
+// to its JSON path, e.g. "$.data" and "$..id" becomes "$.data..id".
+func (c chain) enterQuery(query string) chain {
+	if c.jsonPath == "" {
+		return c
+	}
+	query = strings.TrimPrefix(query, "$")
+	if query != "" && query[0] != '.' && query[0] != '[' {
+		query = "." + query
+	}
+	c.jsonPath += query
+	return c
 }
 
 func (c *chain) failed() bool {
@@ -17,8 +94,44 @@ func (c *chain) fail(message string, args ...interface{}) {
 	if c.failbit {
 		return
 	}
+	c.report(&AssertionFailure{
+		Message: fmt.Sprintf(message, args...),
+	})
+}
+
+// failExpected is like fail, but also attaches expected and actual values
+// to the reported AssertionFailure.
+func (c *chain) failExpected(
+	expected, actual interface{}, message string, args ...interface{},
+) {
+	if c.failbit {
+		return
+	}
+	failure := &AssertionFailure{
+		Expected: expected,
+		Actual:   actual,
+		Message:  fmt.Sprintf(message, args...),
+	}
+	if diff := diffValues(expected, actual); diff != diffUnavailable {
+		failure.Diff = diff
+	}
+	c.report(failure)
+}
+
+func (c *chain) report(failure *AssertionFailure) {
 	c.failbit = true
-	c.reporter.Errorf(message, args...)
+
+	failure.Assertion = assertionName()
+	failure.Path = strings.Join(c.path, ".")
+	failure.JSONPath = c.jsonPath
+	failure.Request = c.request
+	failure.Response = c.response
+
+	if c.jsonPath != "" && c.jsonPath != "$" {
+		failure.Message += "\n\nat path:\n " + c.jsonPath
+	}
+
+	reportFailure(c.reporter, failure)
 }
 
 func (c *chain) reset() {
@@ -36,3 +149,56 @@ func (c *chain) assertOK(r Reporter) {
 		r.Errorf("expected chain is ok, but it's failed")
 	}
 }
+
+var (
+	packagePrefix = reflect.TypeOf(chain{}).PkgPath() + "."
+
+	assertionRegexp = regexp.MustCompile(
+		`^(?:\(\*([A-Z]\w*)\)|([A-Z]\w*))\.([A-Z]\w*)$|^(New[A-Z]\w*)$`)
+
+	jsonIdentRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+	jsonKeyEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+)
+
+// assertionName returns name of the exported function or method of this
+// package that was invoked by the user and has reported the failure,
+// e.g. "Object.ValueEqual".
+//
+// It inspects the call stack and returns the outermost exported function
+// or method before the stack leaves the package.
+func assertionName() string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+
+	frames := runtime.CallersFrames(pcs[:n])
+
+	name := ""
+	for {
+		frame, more := frames.Next()
+
+		if !strings.HasPrefix(frame.Function, packagePrefix) ||
+			strings.HasSuffix(frame.File, "_test.go") {
+			break
+		}
+
+		fn := strings.TrimPrefix(frame.Function, packagePrefix)
+
+		if m := assertionRegexp.FindStringSubmatch(fn); m != nil {
+			switch {
+			case m[1] != "":
+				name = m[1] + "." + m[3]
+			case m[2] != "":
+				name = m[2] + "." + m[3]
+			default:
+				name = m[4]
+			}
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return name
+}
@@ -1,6 +1,7 @@
 package httpexpect
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -62,3 +63,162 @@ func TestChainReport(t *testing.T) {
 	chain.assertOK(r2)
 	assert.True(t, r2.reported)
 }
+
+func TestChainPath(t *testing.T) {
+	chain1 := makeChain(newMockReporter(t)).root("Value")
+
+	chain2 := chain1.enter("Object()")
+	chain3 := chain2.enter("Value(%q)", "foo")
+	chain4 := chain2.enter("Value(%q)", "bar")
+
+	assert.Equal(t, []string{"Value"}, chain1.path)
+	assert.Equal(t, []string{"Value", "Object()"}, chain2.path)
+	assert.Equal(t, []string{"Value", "Object()", `Value("foo")`}, chain3.path)
+	assert.Equal(t, []string{"Value", "Object()", `Value("bar")`}, chain4.path)
+
+	chain5 := chain4.root("Array")
+	assert.Equal(t, []string{"Array"}, chain5.path)
+}
+
+func TestChainFailure(t *testing.T) {
+	reporter := newMockFailureReporter(t)
+
+	value := NewValue(reporter, map[string]interface{}{
+		"foo": []interface{}{"bar", 123},
+	})
+
+	value.Object().Value("foo").Array().Element(1).Number().Equal(456)
+
+	assert.Equal(t, 1, len(reporter.failures))
+
+	failure := reporter.failures[0]
+
+	assert.Equal(t, "Number.Equal", failure.Assertion)
+	assert.Equal(t,
+		`Value.Object().Value("foo").Array().Element(1).Number()`, failure.Path)
+	assert.Equal(t, 456.0, failure.Expected)
+	assert.Equal(t, 123.0, failure.Actual)
+	assert.Equal(t, "", failure.Diff)
+	assert.Contains(t, failure.Message, "456")
+	assert.Nil(t, failure.Request)
+	assert.Nil(t, failure.Response)
+}
+
+func TestChainFailureDiff(t *testing.T) {
+	reporter := newMockFailureReporter(t)
+
+	NewObject(reporter, map[string]interface{}{"foo": 123}).
+		Equal(map[string]interface{}{"foo": 456})
+
+	assert.Equal(t, 1, len(reporter.failures))
+
+	failure := reporter.failures[0]
+
+	assert.Equal(t, "Object.Equal", failure.Assertion)
+	assert.Equal(t, "Object", failure.Path)
+	assert.Equal(t, map[string]interface{}{"foo": 456.0}, failure.Expected)
+	assert.Equal(t, map[string]interface{}{"foo": 123.0}, failure.Actual)
+	assert.NotEqual(t, "", failure.Diff)
+}
+
+func TestChainFailureResponse(t *testing.T) {
+	reporter := newMockFailureReporter(t)
+
+	config := Config{
+		BaseURL:        "http://example.com",
+		RequestFactory: DefaultRequestFactory{},
+		Client: &mockClient{
+			resp: http.Response{
+				StatusCode: http.StatusOK,
+			},
+		},
+		Reporter: reporter,
+	}
+
+	resp1 := NewRequest(config, "GET", "/path").Expect()
+	resp1.Status(http.StatusNotFound)
+
+	resp2 := NewRequest(config, "GET", "/path").Expect()
+	resp2.Header("Foo").Equal("bar")
+
+	assert.Equal(t, 2, len(reporter.failures))
+
+	assert.Equal(t, "Response.Status", reporter.failures[0].Assertion)
+	assert.Equal(t, "Response", reporter.failures[0].Path)
+	assert.Equal(t, "404 Not Found", reporter.failures[0].Expected)
+	assert.Equal(t, "200 OK", reporter.failures[0].Actual)
+
+	assert.Equal(t, "String.Equal", reporter.failures[1].Assertion)
+	assert.Equal(t, `Response.Header("Foo")`, reporter.failures[1].Path)
+
+	for _, failure := range reporter.failures {
+		assert.NotNil(t, failure.Request)
+		assert.Equal(t, "http://example.com/path", failure.Request.URL.String())
+		assert.NotNil(t, failure.Response)
+		assert.Equal(t, http.StatusOK, failure.Response.StatusCode)
+	}
+}
+
+func TestChainFailureFallback(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	NewNumber(reporter, 123).Equal(456)
+
+	assert.True(t, reporter.reported)
+}
+
+func TestChainJSONPath(t *testing.T) {
+	chain := makeChain(newMockReporter(t))
+
+	assert.Equal(t, "", chain.enterKey("foo").enterIndex(1).jsonPath)
+
+	chain = chain.rootJSON()
+
+	assert.Equal(t, "$", chain.jsonPath)
+	assert.Equal(t, "$.foo", chain.enterKey("foo").jsonPath)
+	assert.Equal(t, "$.foo_1", chain.enterKey("foo_1").jsonPath)
+	assert.Equal(t, "$['foo bar']", chain.enterKey("foo bar").jsonPath)
+	assert.Equal(t, `$['it\'s']`, chain.enterKey("it's").jsonPath)
+	assert.Equal(t, "$['1foo']", chain.enterKey("1foo").jsonPath)
+	assert.Equal(t, "$[3]", chain.enterIndex(3).jsonPath)
+	assert.Equal(t, "$.foo[3].bar",
+		chain.enterKey("foo").enterIndex(3).enterKey("bar").jsonPath)
+
+	assert.Equal(t, "$.foo..bar", chain.enterKey("foo").enterQuery("$..bar").jsonPath)
+	assert.Equal(t, "$.foo.bar", chain.enterKey("foo").enterQuery("$.bar").jsonPath)
+	assert.Equal(t, "$.foo[0]", chain.enterKey("foo").enterQuery("$[0]").jsonPath)
+	assert.Equal(t, "$.foo.bar", chain.enterKey("foo").enterQuery("bar").jsonPath)
+	assert.Equal(t, "$.foo", chain.enterKey("foo").enterQuery("$").jsonPath)
+}
+
+func TestChainJSONPathFailure(t *testing.T) {
+	reporter := newMockFailureReporter(t)
+
+	value := NewValue(reporter, map[string]interface{}{
+		"data": []interface{}{
+			map[string]interface{}{"id": 1},
+			map[string]interface{}{"id": 2},
+		},
+	})
+
+	value.Object().Value("data").Array().Element(1).Object().ValueEqual("id", 5)
+	value.Object().Value("data").Array().Last().Object().Value("id").Equal(5)
+	value.Object().Value("data").Array().Iter()[0].Object().ValueNotEqual("id", 1)
+	value.Path("$.data").Array().First().Object().Value("id").Number().Gt(1)
+	value.Object().Value("data").Array().Length().Equal(3)
+	value.Equal(nil)
+
+	assert.Equal(t, 6, len(reporter.failures))
+
+	assert.Equal(t, "$.data[1].id", reporter.failures[0].JSONPath)
+	assert.Equal(t, "$.data[1].id", reporter.failures[1].JSONPath)
+	assert.Equal(t, "$.data[0].id", reporter.failures[2].JSONPath)
+	assert.Equal(t, "$.data[0].id", reporter.failures[3].JSONPath)
+	assert.Equal(t, "$.data", reporter.failures[4].JSONPath)
+	assert.Equal(t, "$", reporter.failures[5].JSONPath)
+
+	for _, failure := range reporter.failures[:5] {
+		assert.Contains(t, failure.Message, failure.JSONPath)
+	}
+	assert.NotContains(t, reporter.failures[5].Message, "at path")
+}
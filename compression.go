@@ -0,0 +1,185 @@
+package httpexpect
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// ContentCoding defines a content coding, used to compress request bodies
+// and to decompress response bodies.
+//
+// Built-in codings are "gzip" (and its alias "x-gzip"), "deflate", and "br".
+// Other codings may be added using Config.ContentCodings.
+//
+// Example:
+//  // using github.com/klauspost/compress/zstd
+//  zstdCoding := httpexpect.ContentCoding{
+//      Encoder: func(w io.Writer) (io.WriteCloser, error) {
+//          return zstd.NewWriter(w)
+//      },
+//      Decoder: func(r io.Reader) (io.ReadCloser, error) {
+//          d, err := zstd.NewReader(r)
+//          if err != nil {
+//              return nil, err
+//          }
+//          return d.IOReadCloser(), nil
+//      },
+//  }
+type ContentCoding struct {
+	// Encoder returns a writer that compresses data and writes it to w.
+	// May be nil if coding is used only to decompress responses.
+	Encoder func(w io.Writer) (io.WriteCloser, error)
+
+	// Decoder returns a reader that decompresses data read from r.
+	// May be nil if coding is used only to compress requests.
+	Decoder func(r io.Reader) (io.ReadCloser, error)
+}
+
+var gzipCoding = ContentCoding{
+	Encoder: func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	},
+	Decoder: func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+}
+
+var builtinContentCodings = map[string]ContentCoding{
+	"gzip":   gzipCoding,
+	"x-gzip": gzipCoding,
+	"deflate": {
+		Encoder: func(w io.Writer) (io.WriteCloser, error) {
+			return zlib.NewWriter(w), nil
+		},
+		Decoder: func(r io.Reader) (io.ReadCloser, error) {
+			// "deflate" should be zlib stream, as defined in RFC 7230,
+			// but some servers send raw deflate stream instead
+			br := bufio.NewReader(r)
+			header, err := br.Peek(2)
+			if err == nil && isZlibHeader(header) {
+				return zlib.NewReader(br)
+			}
+			return flate.NewReader(br), nil
+		},
+	},
+	"br": {
+		Encoder: func(w io.Writer) (io.WriteCloser, error) {
+			return brotli.NewWriter(w), nil
+		},
+		Decoder: func(r io.Reader) (io.ReadCloser, error) {
+			return ioutil.NopCloser(brotli.NewReader(r)), nil
+		},
+	},
+}
+
+func isZlibHeader(b []byte) bool {
+	return b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+func lookupContentCoding(
+	codings map[string]ContentCoding, name string,
+) (ContentCoding, bool) {
+	name = strings.ToLower(name)
+	if coding, ok := codings[name]; ok {
+		return coding, true
+	}
+	coding, ok := builtinContentCodings[name]
+	return coding, ok
+}
+
+// contentEncodings returns the list of codings from Content-Encoding header,
+// in order in which they were applied, without "identity".
+func contentEncodings(header http.Header) []string {
+	var ret []string
+	for _, value := range header["Content-Encoding"] {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name != "" && name != "identity" {
+				ret = append(ret, name)
+			}
+		}
+	}
+	return ret
+}
+
+// decodeContent decompresses content according to Content-Encoding header.
+//
+// If some coding is unknown, an error is returned, since content can't be
+// interpreted without decoding it.
+func decodeContent(
+	codings map[string]ContentCoding, header http.Header, content []byte,
+) ([]byte, error) {
+	encodings := contentEncodings(header)
+	if len(encodings) == 0 || len(content) == 0 {
+		return content, nil
+	}
+
+	decoders := make([]func(io.Reader) (io.ReadCloser, error), 0, len(encodings))
+	for _, name := range encodings {
+		coding, ok := lookupContentCoding(codings, name)
+		if !ok || coding.Decoder == nil {
+			return nil, fmt.Errorf("unsupported content encoding %q", name)
+		}
+		decoders = append(decoders, coding.Decoder)
+	}
+
+	for n := len(encodings) - 1; n >= 0; n-- {
+		decoded, err := transformContent(content, decoders[n])
+		if err != nil {
+			return nil, fmt.Errorf("%q content encoding: %s", encodings[n], err.Error())
+		}
+		content = decoded
+	}
+
+	return content, nil
+}
+
+func transformContent(
+	content []byte, decoder func(io.Reader) (io.ReadCloser, error),
+) ([]byte, error) {
+	reader, err := decoder(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return ioutil.ReadAll(reader)
+}
+
+// encodeContent compresses content using given coding.
+func encodeContent(
+	codings map[string]ContentCoding, name string, content []byte,
+) ([]byte, error) {
+	coding, ok := lookupContentCoding(codings, name)
+	if !ok || coding.Encoder == nil {
+		return nil, fmt.Errorf("unsupported content encoding %q", name)
+	}
+
+	var buf bytes.Buffer
+
+	writer, err := coding.Encoder(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := writer.Write(content); err != nil {
+		_ = writer.Close()
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
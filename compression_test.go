@@ -0,0 +1,281 @@
+package httpexpect
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressionRoundTrip(t *testing.T) {
+	content := []byte(strings.Repeat("hello, world! ", 100))
+
+	for _, name := range []string{"gzip", "x-gzip", "deflate", "br", "GZIP"} {
+		compressed, err := encodeContent(nil, name, content)
+		assert.NoError(t, err, name)
+		assert.True(t, len(compressed) < len(content), name)
+
+		header := http.Header{"Content-Encoding": {name}}
+
+		decoded, err := decodeContent(nil, header, compressed)
+		assert.NoError(t, err, name)
+		assert.Equal(t, content, decoded, name)
+	}
+
+	_, err := encodeContent(nil, "compress", content)
+	assert.Error(t, err)
+}
+
+func TestCompressionDecode(t *testing.T) {
+	gzipped, _ := encodeContent(nil, "gzip", []byte("hello"))
+	twice, _ := encodeContent(nil, "br", gzipped)
+
+	var raw bytes.Buffer
+	w, _ := flate.NewWriter(&raw, flate.DefaultCompression)
+	_, _ = w.Write([]byte("hello"))
+	_ = w.Close()
+
+	cases := []struct {
+		name     string
+		encoding []string
+		content  []byte
+		result   []byte
+		fail     bool
+	}{
+		{"none", nil, []byte("hello"), []byte("hello"), false},
+		{"identity", []string{"identity"}, []byte("hello"), []byte("hello"), false},
+		{"empty", []string{"gzip"}, []byte{}, []byte{}, false},
+		{"unknown", []string{"compress"}, []byte("hello"), nil, true},
+		{"unknown in list", []string{"gzip, compress"}, twice, nil, true},
+		{"list", []string{"gzip, br"}, twice, []byte("hello"), false},
+		{"values", []string{"gzip", "br"}, twice, []byte("hello"), false},
+		{"raw deflate", []string{"deflate"}, raw.Bytes(), []byte("hello"), false},
+		{"malformed", []string{"gzip"}, []byte("hello"), nil, true},
+	}
+
+	for _, tc := range cases {
+		header := http.Header{}
+		if tc.encoding != nil {
+			header["Content-Encoding"] = tc.encoding
+		}
+
+		result, err := decodeContent(nil, header, tc.content)
+		if tc.fail {
+			assert.Error(t, err, tc.name)
+		} else {
+			assert.NoError(t, err, tc.name)
+			assert.Equal(t, tc.result, result, tc.name)
+		}
+	}
+}
+
+func TestCompressionCustom(t *testing.T) {
+	codings := map[string]ContentCoding{
+		"b64": {
+			Encoder: func(w io.Writer) (io.WriteCloser, error) {
+				return base64.NewEncoder(base64.StdEncoding, w), nil
+			},
+			Decoder: func(r io.Reader) (io.ReadCloser, error) {
+				return ioutil.NopCloser(base64.NewDecoder(base64.StdEncoding, r)), nil
+			},
+		},
+	}
+
+	encoded, err := encodeContent(codings, "b64", []byte("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, "aGVsbG8=", string(encoded))
+
+	decoded, err := decodeContent(codings,
+		http.Header{"Content-Encoding": {"gzip, b64"}},
+		[]byte(base64.StdEncoding.EncodeToString(mustGzip([]byte("hello")))))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(decoded))
+}
+
+func mustGzip(content []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, _ = w.Write(content)
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+func TestCompressionResponse(t *testing.T) {
+	compressed := mustGzip([]byte(`{"foo":123}`))
+
+	resp := NewResponse(newMockReporter(t), &http.Response{
+		Header: http.Header{
+			"Content-Type":     {"application/json"},
+			"Content-Encoding": {"gzip"},
+		},
+		Body: ioutil.NopCloser(bytes.NewReader(compressed)),
+	})
+
+	resp.ContentEncoding("gzip")
+	resp.JSON().Object().ValueEqual("foo", 123)
+	resp.Body().Equal(`{"foo":123}`)
+	resp.CompressedBody().Equal(string(compressed))
+	resp.CompressedSize().Equal(len(compressed))
+	resp.chain.assertOK(t)
+
+	resp = NewResponse(newMockReporter(t), &http.Response{
+		Body: ioutil.NopCloser(strings.NewReader("hello")),
+	})
+
+	resp.Body().Equal("hello")
+	resp.CompressedBody().Equal("hello")
+	resp.CompressedSize().Equal(5)
+	resp.chain.assertOK(t)
+
+	for _, encoding := range []string{"gzip", "compress"} {
+		resp = NewResponse(newMockReporter(t), &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header{
+				"Content-Type":     {"application/json"},
+				"Content-Encoding": {encoding},
+			},
+			Body: ioutil.NopCloser(strings.NewReader("hello")),
+		})
+
+		resp.Status(http.StatusOK)
+		resp.ContentEncoding(encoding)
+		resp.CompressedBody().Equal("hello")
+		resp.CompressedSize().Equal(5)
+		resp.chain.assertOK(t)
+
+		resp.Body()
+		resp.chain.assertFailed(t)
+		resp.chain.reset()
+
+		resp.JSON()
+		resp.chain.assertFailed(t)
+		resp.chain.reset()
+
+		resp.NoContent()
+		resp.chain.assertFailed(t)
+	}
+
+	resp = NewResponse(newMockReporter(t), &http.Response{
+		Body:         ioutil.NopCloser(strings.NewReader("hello")),
+		Uncompressed: true,
+	})
+
+	resp.Body().Equal("hello")
+	resp.chain.assertOK(t)
+
+	resp.CompressedSize()
+	resp.chain.assertFailed(t)
+	resp.chain.reset()
+
+	resp.CompressedBody()
+	resp.chain.assertFailed(t)
+}
+
+func TestCompressionRequest(t *testing.T) {
+	var requestSize int
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requestSize = len(body)
+
+		decoded, err := decodeContent(nil, r.Header, body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+
+		if r.Header.Get("Content-Encoding") != "" && len(decoded) != 0 {
+			decoded, _ = encodeContent(nil, "br", decoded)
+			w.Header().Set("Content-Encoding", "br")
+		}
+
+		_, _ = w.Write(decoded)
+	})
+
+	e := WithConfig(Config{
+		BaseURL:  "http://example.com",
+		Reporter: NewAssertReporter(t),
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+	})
+
+	payload := map[string]string{"foo": strings.Repeat("bar", 100)}
+
+	for _, encoding := range []string{"gzip", "deflate", "br"} {
+		resp := e.POST("/").
+			WithCompression(encoding).
+			WithJSON(payload).
+			Expect().
+			Status(http.StatusOK).
+			ContentEncoding("br")
+
+		resp.JSON().Object().Equal(payload)
+		resp.CompressedSize().Lt(len(resp.Body().Raw()))
+
+		assert.True(t, requestSize < len(resp.Body().Raw()), encoding)
+	}
+
+	e.POST("/").
+		WithCompression("gzip").
+		WithFormField("foo", "bar").
+		Expect().
+		Status(http.StatusOK).
+		Body().Equal("foo=bar")
+
+	e.PUT("/").
+		WithCompression("gzip").
+		WithChunked(strings.NewReader("chunked")).
+		Expect().
+		Status(http.StatusOK).
+		Body().Equal("chunked")
+
+	e.GET("/").
+		WithCompression("gzip").
+		Expect().
+		Status(http.StatusOK).
+		ContentEncoding().
+		Body().Empty()
+
+	e.POST("/").
+		WithCompression("gzip").
+		WithCompression("identity").
+		WithText("hello").
+		Expect().
+		Status(http.StatusOK).
+		ContentEncoding().
+		Body().Equal("hello")
+}
+
+func TestCompressionRequestUnsupported(t *testing.T) {
+	config := Config{
+		RequestFactory: DefaultRequestFactory{},
+		Reporter:       newMockReporter(t),
+		Client:         &mockClient{},
+	}
+
+	req := NewRequest(config, "POST", "/")
+	req.WithCompression("compress")
+	req.chain.assertFailed(t)
+
+	config.ContentCodings = map[string]ContentCoding{
+		"zstd": {
+			Decoder: func(r io.Reader) (io.ReadCloser, error) {
+				return ioutil.NopCloser(r), nil
+			},
+		},
+	}
+
+	req = NewRequest(config, "POST", "/")
+	req.WithCompression("zstd")
+	req.chain.assertFailed(t)
+}
@@ -2,6 +2,7 @@ package httpexpect
 
 import (
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -22,7 +23,7 @@ type Cookie struct {
 //   cookie.Path().Equal("/")
 //   cookie.Expires().InRange(time.Now(), time.Now().Add(time.Hour * 24))
 func NewCookie(reporter Reporter, value *http.Cookie) *Cookie {
-	chain := makeChain(reporter)
+	chain := makeChain(reporter).root("Cookie")
 	if value == nil {
 		chain.fail("expected non-nil cookie")
 	}
@@ -47,9 +48,9 @@ func (c *Cookie) Raw() *http.Cookie {
 //  cookie.Name().Equal("session")
 func (c *Cookie) Name() *String {
 	if c.chain.failed() {
-		return &String{c.chain, ""}
+		return &String{c.chain.enter("Name()"), ""}
 	}
-	return &String{c.chain, c.value.Name}
+	return &String{c.chain.enter("Name()"), c.value.Name}
 }
 
 // Value returns a new String object that may be used to inspect
@@ -60,9 +61,9 @@ func (c *Cookie) Name() *String {
 //  cookie.Value().Equal("gH6z7Y")
 func (c *Cookie) Value() *String {
 	if c.chain.failed() {
-		return &String{c.chain, ""}
+		return &String{c.chain.enter("Value()"), ""}
 	}
-	return &String{c.chain, c.value.Value}
+	return &String{c.chain.enter("Value()"), c.value.Value}
 }
 
 // Domain returns a new String object that may be used to inspect
@@ -73,9 +74,9 @@ func (c *Cookie) Value() *String {
 //  cookie.Domain().Equal("example.com")
 func (c *Cookie) Domain() *String {
 	if c.chain.failed() {
-		return &String{c.chain, ""}
+		return &String{c.chain.enter("Domain()"), ""}
 	}
-	return &String{c.chain, c.value.Domain}
+	return &String{c.chain.enter("Domain()"), c.value.Domain}
 }
 
 // Path returns a new String object that may be used to inspect
@@ -86,9 +87,9 @@ func (c *Cookie) Domain() *String {
 //  cookie.Path().Equal("/foo")
 func (c *Cookie) Path() *String {
 	if c.chain.failed() {
-		return &String{c.chain, ""}
+		return &String{c.chain.enter("Path()"), ""}
 	}
-	return &String{c.chain, c.value.Path}
+	return &String{c.chain.enter("Path()"), c.value.Path}
 }
 
 // Expires returns a new DateTime object that may be used to inspect
@@ -99,9 +100,9 @@ func (c *Cookie) Path() *String {
 //  cookie.Expires().InRange(time.Now(), time.Now().Add(time.Hour * 24))
 func (c *Cookie) Expires() *DateTime {
 	if c.chain.failed() {
-		return &DateTime{c.chain, time.Unix(0, 0)}
+		return &DateTime{c.chain.enter("Expires()"), time.Unix(0, 0)}
 	}
-	return &DateTime{c.chain, c.value.Expires}
+	return &DateTime{c.chain.enter("Expires()"), c.value.Expires}
 }
 
 // MaxAge returns a new Duration object that may be used to inspect
@@ -119,15 +120,182 @@ func (c *Cookie) Expires() *DateTime {
 //  cookie.MaxAge().InRange(time.Minute, time.Minute*10)
 func (c *Cookie) MaxAge() *Duration {
 	if c.chain.failed() {
-		return &Duration{c.chain, nil}
+		return &Duration{c.chain.enter("MaxAge()"), nil}
 	}
 	if c.value.MaxAge == 0 {
-		return &Duration{c.chain, nil}
+		return &Duration{c.chain.enter("MaxAge()"), nil}
 	}
 	if c.value.MaxAge < 0 {
 		var zero time.Duration
-		return &Duration{c.chain, &zero}
+		return &Duration{c.chain.enter("MaxAge()"), &zero}
 	}
 	d := time.Duration(c.value.MaxAge) * time.Second
-	return &Duration{c.chain, &d}
+	return &Duration{c.chain.enter("MaxAge()"), &d}
+}
+
+// Secure returns a new Boolean object that may be used to inspect
+// cookie Secure attribute.
+//
+// Example:
+//  cookie := NewCookie(t, &http.Cookie{...})
+//  cookie.Secure().True()
+func (c *Cookie) Secure() *Boolean {
+	if c.chain.failed() {
+		return &Boolean{c.chain.enter("Secure()"), false}
+	}
+	return &Boolean{c.chain.enter("Secure()"), c.value.Secure}
+}
+
+// HttpOnly returns a new Boolean object that may be used to inspect
+// cookie HttpOnly attribute.
+//
+// Example:
+//  cookie := NewCookie(t, &http.Cookie{...})
+//  cookie.HttpOnly().True()
+func (c *Cookie) HttpOnly() *Boolean {
+	if c.chain.failed() {
+		return &Boolean{c.chain.enter("HttpOnly()"), false}
+	}
+	return &Boolean{c.chain.enter("HttpOnly()"), c.value.HttpOnly}
+}
+
+// SameSite returns a new String object that may be used to inspect
+// cookie SameSite attribute.
+//
+// Known values are normalized to "Strict", "Lax", and "None". If attribute
+// is missing or has no value, returned String is empty.
+//
+// Example:
+//  cookie := NewCookie(t, &http.Cookie{...})
+//  cookie.SameSite().Equal("Strict")
+func (c *Cookie) SameSite() *String {
+	if c.chain.failed() {
+		return &String{c.chain.enter("SameSite()"), ""}
+	}
+	value, _ := lookupCookieAttribute(c.attributes(), "SameSite")
+	for _, mode := range []string{"Strict", "Lax", "None"} {
+		if strings.EqualFold(value, mode) {
+			value = mode
+		}
+	}
+	return &String{c.chain.enter("SameSite()"), value}
+}
+
+// Partitioned returns a new Boolean object that may be used to inspect
+// cookie Partitioned attribute, used by CHIPS (Cookies Having Independent
+// Partitioned State).
+//
+// Example:
+//  cookie := NewCookie(t, &http.Cookie{...})
+//  cookie.Partitioned().True()
+func (c *Cookie) Partitioned() *Boolean {
+	if c.chain.failed() {
+		return &Boolean{c.chain.enter("Partitioned()"), false}
+	}
+	_, ok := lookupCookieAttribute(c.attributes(), "Partitioned")
+	return &Boolean{c.chain.enter("Partitioned()"), ok}
+}
+
+// ContainsAttribute succeeds if cookie has given attribute.
+// Attribute names are case-insensitive.
+//
+// Attributes are taken from the original Set-Cookie header, if cookie was
+// parsed from response. Otherwise, they're taken from the cookie fields.
+//
+// Example:
+//  cookie := NewCookie(t, &http.Cookie{...})
+//  cookie.ContainsAttribute("HttpOnly")
+func (c *Cookie) ContainsAttribute(name string) *Cookie {
+	if c.chain.failed() {
+		return c
+	}
+	if _, ok := lookupCookieAttribute(c.attributes(), name); !ok {
+		c.chain.fail("\nexpected cookie containing %q attribute, but got:\n %s",
+			name, c.format())
+	}
+	return c
+}
+
+// NotContainsAttribute succeeds if cookie doesn't have given attribute.
+// See ContainsAttribute.
+//
+// Example:
+//  cookie := NewCookie(t, &http.Cookie{...})
+//  cookie.NotContainsAttribute("Domain")
+func (c *Cookie) NotContainsAttribute(name string) *Cookie {
+	if c.chain.failed() {
+		return c
+	}
+	if _, ok := lookupCookieAttribute(c.attributes(), name); ok {
+		c.chain.fail("\nexpected cookie not containing %q attribute, but got:\n %s",
+			name, c.format())
+	}
+	return c
+}
+
+// Attribute returns a new String object that may be used to inspect raw
+// value of given attribute, as it was written in Set-Cookie header. Value
+// of attribute without value, like "Secure", is empty string.
+//
+// If attribute is missing, failure is reported. See ContainsAttribute.
+//
+// Example:
+//  cookie := resp.Cookie("session")
+//  cookie.Attribute("Priority").Equal("High")
+func (c *Cookie) Attribute(name string) *String {
+	if c.ContainsAttribute(name).chain.failed() {
+		return &String{c.chain.enter("Attribute(%q)", name), ""}
+	}
+	value, _ := lookupCookieAttribute(c.attributes(), name)
+	return &String{c.chain.enter("Attribute(%q)", name), value}
+}
+
+type cookieAttribute struct {
+	name  string
+	value string
+}
+
+// attributes returns attributes of Set-Cookie line from which cookie was
+// parsed, or, if cookie wasn't parsed, of line serialized from cookie.
+func (c *Cookie) attributes() []cookieAttribute {
+	line := c.value.Raw
+	if line == "" {
+		line = c.value.String()
+	}
+
+	parts := strings.Split(line, ";")
+	attrs := make([]cookieAttribute, 0, len(parts))
+
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var attr cookieAttribute
+		if n := strings.IndexByte(part, '='); n >= 0 {
+			attr.name = strings.TrimSpace(part[:n])
+			attr.value = strings.TrimSpace(part[n+1:])
+		} else {
+			attr.name = part
+		}
+		attrs = append(attrs, attr)
+	}
+
+	return attrs
+}
+
+func lookupCookieAttribute(attrs []cookieAttribute, name string) (string, bool) {
+	for _, attr := range attrs {
+		if strings.EqualFold(attr.name, name) {
+			return attr.value, true
+		}
+	}
+	return "", false
+}
+
+func (c *Cookie) format() string {
+	if c.value.Raw != "" {
+		return c.value.Raw
+	}
+	return c.value.String()
 }
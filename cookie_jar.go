@@ -0,0 +1,347 @@
+package httpexpect
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// CookieJar provides methods to inspect and modify cookies that are stored
+// in client cookie jar and are sent with requests to given URL.
+//
+// Note that http.CookieJar returns only names and values of stored cookies,
+// so other cookie attributes can't be inspected. Use Response.Cookie to
+// inspect attributes of cookies set by a response.
+type CookieJar struct {
+	chain chain
+	jar   http.CookieJar
+	url   *url.URL
+}
+
+// NewCookieJar returns a new CookieJar object given a reporter used to
+// report failures, a cookie jar, and URL which cookies are inspected.
+//
+// reporter and jar should not be nil. urlStr should be an absolute URL.
+//
+// Example:
+//  jar := httpexpect.NewJar()
+//  cookies := NewCookieJar(reporter, jar, "http://example.com/")
+//  cookies.Set(&http.Cookie{Name: "session", Value: "abc"})
+//  cookies.Cookie("session").Value().Equal("abc")
+func NewCookieJar(reporter Reporter, jar http.CookieJar, urlStr string) *CookieJar {
+	chain := makeChain(reporter).root("CookieJar")
+	return makeCookieJar(chain, jar, urlStr)
+}
+
+func makeCookieJar(chain chain, jar http.CookieJar, urlStr string) *CookieJar {
+	cj := &CookieJar{chain: chain, jar: jar}
+
+	if chain.failed() {
+		return cj
+	}
+
+	if jar == nil {
+		cj.chain.fail("\nexpected non-nil cookie jar")
+		return cj
+	}
+
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		cj.chain.fail("\nexpected valid URL, but got:\n %q\n\n%s", urlStr, err.Error())
+		return cj
+	}
+	if !u.IsAbs() || u.Host == "" {
+		cj.chain.fail("\nexpected absolute URL, but got:\n %q", urlStr)
+		return cj
+	}
+
+	cj.url = u
+	return cj
+}
+
+// Cookies returns a new CookieJar object that may be used to inspect and
+// modify cookies stored in the client cookie jar for given URL.
+//
+// If urlStr is not an absolute URL, it's treated as path and is appended
+// to Config.BaseURL, like request path. Config.Client should be
+// http.Client with non-nil Jar, like the default client.
+//
+// Example:
+//  e := httpexpect.New(t, "http://example.com")
+//
+//  e.POST("/login").WithForm(credentials).
+//      Expect().
+//      Status(http.StatusOK)
+//
+//  e.Cookies("/").Contains("session")
+//
+//  e.POST("/logout").
+//      Expect().
+//      Status(http.StatusOK)
+//
+//  e.Cookies("/").NotContains("session")
+func (e *Expect) Cookies(urlStr string) *CookieJar {
+	chain := makeChain(e.config.Reporter).root("Cookies(" + strconv.Quote(urlStr) + ")")
+
+	var jar http.CookieJar
+	if client, ok := e.config.Client.(*http.Client); ok {
+		jar = client.Jar
+	}
+	if jar == nil {
+		chain.fail("\nexpected http.Client with non-nil Jar in Config.Client")
+		return &CookieJar{chain: chain}
+	}
+
+	if u, err := url.Parse(urlStr); err == nil && !u.IsAbs() {
+		base, err := url.Parse(e.config.BaseURL)
+		if err != nil {
+			chain.fail(err.Error())
+			return &CookieJar{chain: chain}
+		}
+		base.Path = concatPaths(base.Path, u.Path)
+		base.RawPath = ""
+		base.RawQuery = u.RawQuery
+		urlStr = base.String()
+	}
+
+	return makeCookieJar(chain, jar, urlStr)
+}
+
+// Raw returns cookies stored in jar for URL.
+func (cj *CookieJar) Raw() []*http.Cookie {
+	if cj.chain.failed() {
+		return nil
+	}
+	return cj.jar.Cookies(cj.url)
+}
+
+// Names returns a new Array object with names of all cookies stored in jar
+// for URL. Returned Array contains a String value for every cookie name.
+//
+// Example:
+//  cookies := NewCookieJar(t, jar, "http://example.com/")
+//  cookies.Names().ContainsOnly("session", "lang")
+func (cj *CookieJar) Names() *Array {
+	if cj.chain.failed() {
+		return &Array{cj.chain.enter("Names()"), nil}
+	}
+	names := []interface{}{}
+	for _, c := range cj.Raw() {
+		names = append(names, c.Name)
+	}
+	return &Array{cj.chain.enter("Names()"), names}
+}
+
+// Empty succeeds if jar has no cookies for URL.
+//
+// Example:
+//  cookies := NewCookieJar(t, jar, "http://example.com/")
+//  cookies.Empty()
+func (cj *CookieJar) Empty() *CookieJar {
+	if cj.chain.failed() {
+		return cj
+	}
+	if names := cj.names(); len(names) != 0 {
+		cj.chain.fail("\nexpected cookie jar without cookies for:\n %q\n\nbut got:\n%s",
+			cj.url.String(), dumpValue(names))
+	}
+	return cj
+}
+
+// NotEmpty succeeds if jar has some cookies for URL.
+//
+// Example:
+//  cookies := NewCookieJar(t, jar, "http://example.com/")
+//  cookies.NotEmpty()
+func (cj *CookieJar) NotEmpty() *CookieJar {
+	if cj.chain.failed() {
+		return cj
+	}
+	if len(cj.names()) == 0 {
+		cj.chain.fail("\nexpected cookie jar with cookies for:\n %q\n\nbut got none",
+			cj.url.String())
+	}
+	return cj
+}
+
+// Contains succeeds if jar has cookie with given name for URL.
+//
+// Example:
+//  cookies := NewCookieJar(t, jar, "http://example.com/")
+//  cookies.Contains("session")
+func (cj *CookieJar) Contains(name string) *CookieJar {
+	if cj.chain.failed() {
+		return cj
+	}
+	if cj.lookup(name) == nil {
+		cj.chain.fail(
+			"\nexpected cookie jar with cookie:\n %q\n\nfor:\n %q"+
+				"\n\nbut got only cookies:\n%s",
+			name, cj.url.String(), dumpValue(cj.names()))
+	}
+	return cj
+}
+
+// NotContains succeeds if jar has no cookie with given name for URL.
+//
+// Example:
+//  cookies := NewCookieJar(t, jar, "http://example.com/")
+//  cookies.NotContains("session")
+func (cj *CookieJar) NotContains(name string) *CookieJar {
+	if cj.chain.failed() {
+		return cj
+	}
+	if cj.lookup(name) != nil {
+		cj.chain.fail(
+			"\nexpected cookie jar without cookie:\n %q\n\nfor:\n %q",
+			name, cj.url.String())
+	}
+	return cj
+}
+
+// Cookie returns a new Cookie object that may be used to inspect given
+// cookie stored in jar for URL. Only name and value of returned cookie
+// are set.
+//
+// If cookie is missing, failure is reported.
+//
+// Example:
+//  cookies := NewCookieJar(t, jar, "http://example.com/")
+//  cookies.Cookie("session").Value().NotEmpty()
+func (cj *CookieJar) Cookie(name string) *Cookie {
+	if cj.Contains(name).chain.failed() {
+		return &Cookie{cj.chain.enter("Cookie(%q)", name), nil}
+	}
+	return &Cookie{cj.chain.enter("Cookie(%q)", name), cj.lookup(name)}
+}
+
+// Set stores given cookies in jar, as if they were set by a response
+// from URL.
+//
+// Example:
+//  cookies := NewCookieJar(t, jar, "http://example.com/")
+//  cookies.Set(&http.Cookie{Name: "session", Value: "expired-token"})
+func (cj *CookieJar) Set(cookies ...*http.Cookie) *CookieJar {
+	if cj.chain.failed() {
+		return cj
+	}
+	for _, c := range cookies {
+		if c == nil {
+			cj.chain.fail("\nunexpected nil cookie in Set")
+			return cj
+		}
+	}
+	cj.jar.SetCookies(cj.url, cookies)
+	return cj
+}
+
+// Delete removes cookies with given names, that are sent to URL, from jar.
+//
+// Since jar doesn't report domain and path of stored cookies, cookies are
+// expired for URL host and its parent domains, and for every prefix of URL
+// path. If cookie is still sent to URL after that, failure is reported.
+//
+// Example:
+//  cookies := NewCookieJar(t, jar, "http://example.com/")
+//  cookies.Delete("session")
+func (cj *CookieJar) Delete(names ...string) *CookieJar {
+	if cj.chain.failed() {
+		return cj
+	}
+
+	var expired []*http.Cookie
+	for _, name := range names {
+		for _, domain := range cookieDomains(cj.url.Hostname()) {
+			for _, path := range cookiePaths(cj.url.Path) {
+				expired = append(expired, &http.Cookie{
+					Name:   name,
+					Domain: domain,
+					Path:   path,
+					MaxAge: -1,
+				})
+			}
+		}
+	}
+	cj.jar.SetCookies(cj.url, expired)
+
+	for _, name := range names {
+		if cj.lookup(name) != nil {
+			cj.chain.fail("\nfailed to delete cookie from jar:\n %q\n\nfor:\n %q",
+				name, cj.url.String())
+			return cj
+		}
+	}
+
+	return cj
+}
+
+// Clear removes all cookies, that are sent to URL, from jar.
+// See Delete.
+//
+// Example:
+//  cookies := NewCookieJar(t, jar, "http://example.com/")
+//  cookies.Clear().Empty()
+func (cj *CookieJar) Clear() *CookieJar {
+	if cj.chain.failed() {
+		return cj
+	}
+	return cj.Delete(cj.names()...)
+}
+
+func (cj *CookieJar) lookup(name string) *http.Cookie {
+	for _, c := range cj.jar.Cookies(cj.url) {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (cj *CookieJar) names() []string {
+	names := []string{}
+	for _, c := range cj.jar.Cookies(cj.url) {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// cookieDomains returns values of Domain attribute that may match cookies
+// sent to given host: empty string for host-only cookies and host itself,
+// and parent domains of host.
+func cookieDomains(host string) []string {
+	domains := []string{""}
+	if net.ParseIP(host) != nil {
+		return domains
+	}
+	for {
+		n := strings.IndexByte(host, '.')
+		if n < 0 {
+			break
+		}
+		host = host[n+1:]
+		if strings.IndexByte(host, '.') < 0 {
+			// top-level domain can't have cookies
+			break
+		}
+		domains = append(domains, host)
+	}
+	return domains
+}
+
+// cookiePaths returns values of Path attribute that may match cookies
+// sent to given path: "/" and every prefix of path ending before slash,
+// and path itself.
+func cookiePaths(path string) []string {
+	paths := []string{"/"}
+	for n := 1; n < len(path); n++ {
+		if path[n] == '/' {
+			paths = append(paths, path[:n])
+		}
+	}
+	if len(path) > 1 && !strings.HasSuffix(path, "/") {
+		paths = append(paths, path)
+	}
+	return paths
+}
@@ -0,0 +1,171 @@
+package httpexpect
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCookieJarFailed(t *testing.T) {
+	chain := makeChain(newMockReporter(t))
+
+	chain.fail("fail")
+
+	value := &CookieJar{chain: chain}
+
+	assert.True(t, value.Raw() == nil)
+	assert.True(t, value.Names() != nil)
+	assert.True(t, value.Cookie("foo") != nil)
+
+	value.Empty()
+	value.NotEmpty()
+	value.Contains("foo")
+	value.NotContains("foo")
+	value.Set(&http.Cookie{Name: "foo"})
+	value.Delete("foo")
+	value.Clear()
+}
+
+func TestCookieJarConstructor(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	NewCookieJar(reporter, nil, "http://example.com").chain.assertFailed(t)
+	NewCookieJar(reporter, NewJar(), "/path").chain.assertFailed(t)
+	NewCookieJar(reporter, NewJar(), "http://[::1").chain.assertFailed(t)
+	NewCookieJar(reporter, NewJar(), "http://example.com").chain.assertOK(t)
+}
+
+func TestCookieJarSetDelete(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	jar := NewJar()
+
+	value := NewCookieJar(reporter, jar, "http://api.example.com/v1/users")
+
+	value.Empty().chain.assertOK(t)
+	value.NotEmpty().chain.assertFailed(t)
+	value.chain.reset()
+
+	value.Set(
+		&http.Cookie{Name: "host", Value: "1"},
+		&http.Cookie{Name: "root", Value: "2", Path: "/"},
+		&http.Cookie{Name: "domain", Value: "3", Domain: "example.com", Path: "/v1"},
+		&http.Cookie{Name: "exact", Value: "4", Path: "/v1/users"},
+	)
+	value.chain.assertOK(t)
+
+	value.Names().ContainsOnly("host", "root", "domain", "exact")
+	value.NotEmpty().chain.assertOK(t)
+	value.Contains("domain").chain.assertOK(t)
+	value.Cookie("domain").Value().Equal("3").chain.assertOK(t)
+
+	NewCookieJar(reporter, jar, "http://www.example.com/v1").
+		Names().ContainsOnly("domain")
+
+	value.Delete("root", "domain").chain.assertOK(t)
+	value.Names().ContainsOnly("host", "exact")
+	value.NotContains("domain").chain.assertOK(t)
+
+	value.Contains("domain").chain.assertFailed(t)
+	value.chain.reset()
+
+	value.Cookie("domain").chain.assertFailed(t)
+	value.chain.reset()
+
+	value.Clear().Empty().chain.assertOK(t)
+
+	value.Set(nil).chain.assertFailed(t)
+}
+
+func TestCookieJarExpect(t *testing.T) {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{
+			Name:     "session",
+			Value:    "token",
+			Path:     "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteStrictMode,
+		})
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{
+			Name:   "session",
+			Path:   "/",
+			MaxAge: -1,
+		})
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("/whoami", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(cookie.Value))
+	})
+
+	e := WithConfig(Config{
+		BaseURL:  "http://example.com",
+		Reporter: NewAssertReporter(t),
+		Client: &http.Client{
+			Transport: NewBinder(mux),
+			Jar:       NewJar(),
+		},
+	})
+
+	e.Cookies("/").Empty()
+
+	cookie := e.POST("/login").
+		Expect().
+		Status(http.StatusNoContent).
+		Cookie("session")
+
+	cookie.HttpOnly().True()
+	cookie.SameSite().Equal("Strict")
+
+	e.Cookies("/").Cookie("session").Value().Equal("token")
+	e.Cookies("http://example.com/whoami").Contains("session")
+
+	e.POST("/logout").
+		Expect().
+		Status(http.StatusNoContent)
+
+	e.Cookies("/").NotContains("session")
+
+	e.Cookies("/").Set(&http.Cookie{Name: "session", Value: "seeded"})
+
+	e.GET("/whoami").
+		Expect().
+		Status(http.StatusOK).
+		Body().Equal("seeded")
+
+	e.Cookies("/").Clear()
+
+	e.GET("/whoami").
+		Expect().
+		Status(http.StatusUnauthorized)
+}
+
+func TestCookieJarExpectNoJar(t *testing.T) {
+	e := WithConfig(Config{
+		BaseURL:  "http://example.com",
+		Reporter: newMockReporter(t),
+		Client:   &http.Client{},
+	})
+
+	e.Cookies("/").chain.assertFailed(t)
+
+	e = WithConfig(Config{
+		BaseURL:  "http://example.com",
+		Reporter: newMockReporter(t),
+		Client:   &mockClient{},
+	})
+
+	e.Cookies("/").chain.assertFailed(t)
+}
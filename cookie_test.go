@@ -23,6 +23,14 @@ func TestCookieFailed(t *testing.T) {
 	assert.True(t, value.Path() != nil)
 	assert.True(t, value.Expires() != nil)
 	assert.True(t, value.MaxAge() != nil)
+	assert.True(t, value.Secure() != nil)
+	assert.True(t, value.HttpOnly() != nil)
+	assert.True(t, value.SameSite() != nil)
+	assert.True(t, value.Partitioned() != nil)
+	assert.True(t, value.Attribute("foo") != nil)
+
+	value.ContainsAttribute("foo")
+	value.NotContainsAttribute("foo")
 }
 
 func TestCookieGetters(t *testing.T) {
@@ -102,3 +110,59 @@ func TestCookieMaxAge(t *testing.T) {
 		value.MaxAge().Equal(3 * time.Second).chain.assertOK(t)
 	})
 }
+
+func TestCookieAttributes(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	header := http.Header{"Set-Cookie": {
+		"session=abc; Path=/; Secure; HttpOnly; SameSite=strict; Partitioned;" +
+			" Priority=High",
+	}}
+	cookies := (&http.Response{Header: header}).Cookies()
+	require.Equal(t, 1, len(cookies))
+
+	value := NewCookie(reporter, cookies[0])
+
+	value.Secure().True().chain.assertOK(t)
+	value.HttpOnly().True().chain.assertOK(t)
+	value.SameSite().Equal("Strict").chain.assertOK(t)
+	value.Partitioned().True().chain.assertOK(t)
+
+	value.ContainsAttribute("secure").chain.assertOK(t)
+	value.ContainsAttribute("partitioned").chain.assertOK(t)
+	value.NotContainsAttribute("Domain").chain.assertOK(t)
+
+	value.Attribute("PRIORITY").Equal("High").chain.assertOK(t)
+	value.Attribute("Secure").Empty().chain.assertOK(t)
+
+	value.Attribute("Domain").chain.assertFailed(t)
+	value.chain.reset()
+
+	value.ContainsAttribute("Max-Age").chain.assertFailed(t)
+	value.chain.reset()
+
+	value.NotContainsAttribute("Path").chain.assertFailed(t)
+	value.chain.reset()
+
+	value = NewCookie(reporter, &http.Cookie{
+		Name:     "session",
+		Value:    "abc",
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	value.Secure().False().chain.assertOK(t)
+	value.HttpOnly().True().chain.assertOK(t)
+	value.SameSite().Equal("Lax").chain.assertOK(t)
+	value.Partitioned().False().chain.assertOK(t)
+	value.Attribute("Path").Equal("/").chain.assertOK(t)
+
+	value = NewCookie(reporter, &http.Cookie{
+		Name:  "session",
+		Value: "abc",
+	})
+
+	value.SameSite().Empty().chain.assertOK(t)
+	value.NotContainsAttribute("SameSite").chain.assertOK(t)
+}
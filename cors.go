@@ -0,0 +1,311 @@
+package httpexpect
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Preflight returns a new Request object for CORS preflight request.
+//
+// The request uses OPTIONS method and has Origin header set to origin,
+// Access-Control-Request-Method header set to method, and, if headers are
+// given, Access-Control-Request-Headers header set to comma-separated list
+// of headers.
+//
+// Like browsers do, the request is sent without credentials: neither
+// Config.Authenticator nor Config.Signer is applied, and request builders
+// registered with Expect.Builder are not invoked. Matchers registered with
+// Expect.Matcher are still attached.
+//
+// Example:
+//  e.Preflight("/users", "https://app.example.com", "PUT", "Content-Type").
+//      Expect().
+//      Status(http.StatusNoContent).
+//      CORS().
+//      AllowsOrigin("https://app.example.com").
+//      AllowsMethod("PUT").
+//      AllowsHeader("Content-Type")
+func (e *Expect) Preflight(path, origin, method string, headers ...string) *Request {
+	config := e.config
+	config.Authenticator = nil
+	config.Signer = nil
+
+	req := NewRequest(config, http.MethodOptions, path)
+
+	for _, matcher := range e.matchers {
+		req.WithMatcher(matcher)
+	}
+
+	req.WithHeader("Origin", origin)
+	req.WithHeader("Access-Control-Request-Method", method)
+
+	if len(headers) != 0 {
+		req.WithHeader("Access-Control-Request-Headers", strings.Join(headers, ", "))
+	}
+
+	return req
+}
+
+// CORS provides methods to inspect CORS policy of response, defined by
+// Access-Control-* headers, as specified in the Fetch standard.
+type CORS struct {
+	chain            chain
+	allowOrigin      string
+	allowCredentials bool
+	allowMethods     []string
+	allowHeaders     []string
+	exposeHeaders    []string
+	maxAge           *time.Duration
+}
+
+// parse fills CORS from response headers and reports policy violations
+// to given chain.
+func (c *CORS) parse(chain *chain, resp *http.Response) {
+	header := resp.Header
+
+	c.allowOrigin = strings.TrimSpace(header.Get("Access-Control-Allow-Origin"))
+	c.allowCredentials = strings.TrimSpace(
+		header.Get("Access-Control-Allow-Credentials")) == "true"
+	c.allowMethods = splitHeaderList(header, "Access-Control-Allow-Methods")
+	c.allowHeaders = splitHeaderList(header, "Access-Control-Allow-Headers")
+	c.exposeHeaders = splitHeaderList(header, "Access-Control-Expose-Headers")
+
+	if value := header.Get("Access-Control-Max-Age"); value != "" {
+		sec, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+		if err != nil {
+			chain.fail(
+				"\nexpected \"Access-Control-Max-Age\" header with integer,"+
+					" but got:\n %q",
+				value)
+			return
+		}
+		maxAge := time.Duration(sec) * time.Second
+		c.maxAge = &maxAge
+	}
+
+	c.checkPolicy(chain, resp)
+}
+
+func (c *CORS) checkPolicy(chain *chain, resp *http.Response) {
+	if c.allowCredentials {
+		for _, list := range []struct {
+			name   string
+			values []string
+		}{
+			{"Access-Control-Allow-Origin", []string{c.allowOrigin}},
+			{"Access-Control-Allow-Methods", c.allowMethods},
+			{"Access-Control-Allow-Headers", c.allowHeaders},
+			{"Access-Control-Expose-Headers", c.exposeHeaders},
+		} {
+			if containsFold(list.values, "*") {
+				chain.fail(
+					"\nexpected CORS policy without wildcard in %q header"+
+						" when credentials are allowed", list.name)
+				return
+			}
+		}
+	}
+
+	if c.allowOrigin == "" || c.allowOrigin == "*" || resp.Request == nil {
+		return
+	}
+
+	origin := resp.Request.Header.Get("Origin")
+	if origin == "" || origin != c.allowOrigin {
+		return
+	}
+
+	vary := splitHeaderList(resp.Header, "Vary")
+	if !containsFold(vary, "Origin") && !containsFold(vary, "*") {
+		chain.fail(
+			"\nexpected \"Vary\" header containing \"Origin\" when request origin"+
+				" is echoed in \"Access-Control-Allow-Origin\" header:\n %q", origin)
+	}
+}
+
+// AllowOrigin returns a new String object that may be used to inspect
+// Access-Control-Allow-Origin header.
+func (c *CORS) AllowOrigin() *String {
+	return &String{c.chain.enter("AllowOrigin()"), c.allowOrigin}
+}
+
+// AllowCredentials returns a new Boolean object that may be used to inspect
+// Access-Control-Allow-Credentials header. It is true only if header is
+// equal to "true".
+func (c *CORS) AllowCredentials() *Boolean {
+	return &Boolean{c.chain.enter("AllowCredentials()"), c.allowCredentials}
+}
+
+// AllowMethods returns a new Array object that may be used to inspect
+// Access-Control-Allow-Methods header. Array contains a String value for
+// every method.
+func (c *CORS) AllowMethods() *Array {
+	return &Array{c.chain.enter("AllowMethods()"), stringsToArray(c.allowMethods)}
+}
+
+// AllowHeaders returns a new Array object that may be used to inspect
+// Access-Control-Allow-Headers header. Array contains a String value for
+// every header name.
+func (c *CORS) AllowHeaders() *Array {
+	return &Array{c.chain.enter("AllowHeaders()"), stringsToArray(c.allowHeaders)}
+}
+
+// ExposeHeaders returns a new Array object that may be used to inspect
+// Access-Control-Expose-Headers header. Array contains a String value for
+// every header name.
+func (c *CORS) ExposeHeaders() *Array {
+	return &Array{c.chain.enter("ExposeHeaders()"), stringsToArray(c.exposeHeaders)}
+}
+
+// MaxAge returns a new Duration object that may be used to inspect
+// Access-Control-Max-Age header. If header is missing, returned Duration
+// is not set.
+func (c *CORS) MaxAge() *Duration {
+	return &Duration{c.chain.enter("MaxAge()"), c.maxAge}
+}
+
+// AllowsOrigin succeeds if policy allows given origin, i.e. if
+// Access-Control-Allow-Origin header is equal to origin, or is "*" and
+// credentials are not allowed.
+//
+// Example:
+//  resp.CORS().AllowsOrigin("https://app.example.com")
+func (c *CORS) AllowsOrigin(origin string) *CORS {
+	if c.chain.failed() {
+		return c
+	}
+	if !c.allowsOrigin(origin) {
+		c.chain.fail("\nexpected CORS policy allowing origin:\n %q\n\n"+
+			"but got \"Access-Control-Allow-Origin\" header:\n %q",
+			origin, c.allowOrigin)
+	}
+	return c
+}
+
+// NotAllowsOrigin succeeds if policy doesn't allow given origin.
+// See AllowsOrigin.
+//
+// Example:
+//  resp.CORS().NotAllowsOrigin("https://evil.example.com")
+func (c *CORS) NotAllowsOrigin(origin string) *CORS {
+	if c.chain.failed() {
+		return c
+	}
+	if c.allowsOrigin(origin) {
+		c.chain.fail("\nexpected CORS policy not allowing origin:\n %q\n\n"+
+			"but got \"Access-Control-Allow-Origin\" header:\n %q",
+			origin, c.allowOrigin)
+	}
+	return c
+}
+
+// AllowsMethod succeeds if policy allows given method, i.e. if method is
+// listed in Access-Control-Allow-Methods header, or the header is "*" and
+// credentials are not allowed. Methods are case-sensitive.
+//
+// CORS-safelisted methods, GET, HEAD, and POST, are always allowed.
+//
+// Example:
+//  resp.CORS().AllowsMethod("DELETE")
+func (c *CORS) AllowsMethod(method string) *CORS {
+	if c.chain.failed() {
+		return c
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost:
+		return c
+	}
+	for _, m := range c.allowMethods {
+		if m == method || (m == "*" && !c.allowCredentials) {
+			return c
+		}
+	}
+	c.chain.fail("\nexpected CORS policy allowing method:\n %q\n\n"+
+		"but got \"Access-Control-Allow-Methods\" header:\n %q",
+		method, strings.Join(c.allowMethods, ", "))
+	return c
+}
+
+// AllowsHeader succeeds if policy allows given request header, i.e. if
+// header is listed in Access-Control-Allow-Headers header, or the header
+// is "*", credentials are not allowed, and given header is not
+// Authorization. Header names are case-insensitive.
+//
+// Example:
+//  resp.CORS().AllowsHeader("X-Requested-With")
+func (c *CORS) AllowsHeader(header string) *CORS {
+	if c.chain.failed() {
+		return c
+	}
+	for _, h := range c.allowHeaders {
+		if strings.EqualFold(h, header) {
+			return c
+		}
+		if h == "*" && !c.allowCredentials &&
+			!strings.EqualFold(header, "Authorization") {
+			return c
+		}
+	}
+	c.chain.fail("\nexpected CORS policy allowing header:\n %q\n\n"+
+		"but got \"Access-Control-Allow-Headers\" header:\n %q",
+		header, strings.Join(c.allowHeaders, ", "))
+	return c
+}
+
+// ExposesHeader succeeds if given response header is listed in
+// Access-Control-Expose-Headers header, or the header is "*" and
+// credentials are not allowed. Header names are case-insensitive.
+//
+// Example:
+//  resp.CORS().ExposesHeader("X-Total-Count")
+func (c *CORS) ExposesHeader(header string) *CORS {
+	if c.chain.failed() {
+		return c
+	}
+	for _, h := range c.exposeHeaders {
+		if strings.EqualFold(h, header) || (h == "*" && !c.allowCredentials) {
+			return c
+		}
+	}
+	c.chain.fail("\nexpected CORS policy exposing header:\n %q\n\n"+
+		"but got \"Access-Control-Expose-Headers\" header:\n %q",
+		header, strings.Join(c.exposeHeaders, ", "))
+	return c
+}
+
+func (c *CORS) allowsOrigin(origin string) bool {
+	return c.allowOrigin == origin || (c.allowOrigin == "*" && !c.allowCredentials)
+}
+
+// splitHeaderList returns elements of comma-separated list from all values
+// of given header.
+func splitHeaderList(header http.Header, name string) []string {
+	var ret []string
+	for _, value := range header[http.CanonicalHeaderKey(name)] {
+		for _, elem := range strings.Split(value, ",") {
+			if elem = strings.TrimSpace(elem); elem != "" {
+				ret = append(ret, elem)
+			}
+		}
+	}
+	return ret
+}
+
+func containsFold(list []string, s string) bool {
+	for _, elem := range list {
+		if strings.EqualFold(elem, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func stringsToArray(list []string) []interface{} {
+	ret := make([]interface{}, 0, len(list))
+	for _, s := range list {
+		ret = append(ret, s)
+	}
+	return ret
+}
@@ -0,0 +1,224 @@
+package httpexpect
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSPolicy(t *testing.T) {
+	req, _ := http.NewRequest("OPTIONS", "http://example.com", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+
+	resp := NewResponse(newMockReporter(t), &http.Response{
+		Header: http.Header{
+			"Access-Control-Allow-Origin":      {"https://app.example.com"},
+			"Access-Control-Allow-Credentials": {"true"},
+			"Access-Control-Allow-Methods":     {"GET, PUT", "DELETE"},
+			"Access-Control-Allow-Headers":     {"Content-Type, X-Requested-With"},
+			"Access-Control-Expose-Headers":    {"X-Total-Count"},
+			"Access-Control-Max-Age":           {"600"},
+			"Vary":                             {"Accept-Encoding, Origin"},
+		},
+		Request: req,
+	})
+
+	cors := resp.CORS()
+	resp.chain.assertOK(t)
+
+	cors.AllowOrigin().Equal("https://app.example.com")
+	cors.AllowCredentials().True()
+	cors.AllowMethods().Equal([]string{"GET", "PUT", "DELETE"})
+	cors.AllowHeaders().Equal([]string{"Content-Type", "X-Requested-With"})
+	cors.ExposeHeaders().Equal([]string{"X-Total-Count"})
+	cors.MaxAge().Equal(10 * time.Minute)
+
+	cors.AllowsOrigin("https://app.example.com").
+		NotAllowsOrigin("https://evil.example.com").
+		AllowsMethod("PUT").
+		AllowsMethod("POST").
+		AllowsHeader("content-type").
+		ExposesHeader("x-total-count")
+	cors.chain.assertOK(t)
+
+	for _, fn := range []func(){
+		func() { cors.AllowsOrigin("https://evil.example.com") },
+		func() { cors.NotAllowsOrigin("https://app.example.com") },
+		func() { cors.AllowsMethod("PATCH") },
+		func() { cors.AllowsMethod("put") },
+		func() { cors.AllowsHeader("Authorization") },
+		func() { cors.ExposesHeader("X-Other") },
+	} {
+		fn()
+		cors.chain.assertFailed(t)
+		cors.chain.reset()
+	}
+}
+
+func TestCORSWildcard(t *testing.T) {
+	req, _ := http.NewRequest("OPTIONS", "http://example.com", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+
+	resp := NewResponse(newMockReporter(t), &http.Response{
+		Header: http.Header{
+			"Access-Control-Allow-Origin":   {"*"},
+			"Access-Control-Allow-Methods":  {"*"},
+			"Access-Control-Allow-Headers":  {"*"},
+			"Access-Control-Expose-Headers": {"*"},
+		},
+		Request: req,
+	})
+
+	cors := resp.CORS()
+	resp.chain.assertOK(t)
+
+	cors.AllowsOrigin("https://any.example.com").
+		AllowsMethod("PATCH").
+		AllowsHeader("X-Foo").
+		ExposesHeader("X-Bar")
+	cors.MaxAge().NotSet()
+	cors.AllowCredentials().False()
+	cors.chain.assertOK(t)
+
+	cors.AllowsHeader("Authorization")
+	cors.chain.assertFailed(t)
+}
+
+func TestCORSInvalid(t *testing.T) {
+	cases := map[string]http.Header{
+		"wildcard origin with credentials": {
+			"Access-Control-Allow-Origin":      {"*"},
+			"Access-Control-Allow-Credentials": {"true"},
+		},
+		"wildcard headers with credentials": {
+			"Access-Control-Allow-Origin":      {"https://app.example.com"},
+			"Access-Control-Allow-Credentials": {"true"},
+			"Access-Control-Allow-Headers":     {"*"},
+			"Vary":                             {"Origin"},
+		},
+		"echoed origin without vary": {
+			"Access-Control-Allow-Origin": {"https://app.example.com"},
+		},
+		"invalid max age": {
+			"Access-Control-Allow-Origin": {"*"},
+			"Access-Control-Max-Age":      {"forever"},
+		},
+	}
+
+	req, _ := http.NewRequest("OPTIONS", "http://example.com", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+
+	for name, header := range cases {
+		resp := NewResponse(newMockReporter(t), &http.Response{
+			Header:  header,
+			Request: req,
+		})
+		resp.CORS()
+		assert.True(t, resp.chain.failed(), name)
+	}
+
+	req, _ = http.NewRequest("OPTIONS", "http://example.com", nil)
+	req.Header.Set("Origin", "https://other.example.com")
+
+	resp := NewResponse(newMockReporter(t), &http.Response{
+		Header: http.Header{
+			"Access-Control-Allow-Origin": {"https://app.example.com"},
+		},
+		Request: req,
+	})
+	resp.CORS().NotAllowsOrigin("https://other.example.com")
+	resp.chain.assertOK(t)
+}
+
+func TestCORSPreflight(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		origin := r.Header.Get("Origin")
+		if origin == "https://app.example.com" {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods",
+				r.Header.Get("Access-Control-Request-Method"))
+			w.Header().Set("Access-Control-Allow-Headers",
+				r.Header.Get("Access-Control-Request-Headers"))
+		}
+		w.Header().Add("Vary", "Origin")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	e := WithConfig(Config{
+		BaseURL:  "http://example.com",
+		Reporter: NewAssertReporter(t),
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+	})
+
+	e.Preflight("/users", "https://app.example.com", "PUT", "Content-Type", "X-Foo").
+		Expect().
+		Status(http.StatusNoContent).
+		CORS().
+		AllowsOrigin("https://app.example.com").
+		AllowsMethod("PUT").
+		AllowsHeader("Content-Type").
+		AllowsHeader("X-Foo")
+
+	e.Preflight("/users", "https://evil.example.com", "DELETE").
+		Expect().
+		Status(http.StatusNoContent).
+		CORS().
+		NotAllowsOrigin("https://evil.example.com").
+		AllowOrigin().Empty()
+}
+
+func TestCORSPreflightCredentials(t *testing.T) {
+	var header http.Header
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		w.Header().Set("Access-Control-Allow-Origin", r.Header.Get("Origin"))
+		w.Header().Set("Vary", "Origin")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	matched := false
+
+	e := WithConfig(Config{
+		BaseURL:       "http://example.com",
+		Reporter:      NewAssertReporter(t),
+		Authenticator: NewBearerAuthenticator("token"),
+		Signer: NewHMACSigner(HMACOpts{
+			Key:    []byte("secret"),
+			Header: "Signature",
+		}),
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+	}).Builder(func(req *Request) {
+		req.WithHeader("X-Foo", "bar")
+	}).Matcher(func(resp *Response) {
+		matched = true
+	})
+
+	e.Preflight("/users", "https://app.example.com", "GET").
+		Expect().
+		Status(http.StatusNoContent).
+		CORS().
+		AllowsOrigin("https://app.example.com")
+
+	assert.Equal(t, "https://app.example.com", header.Get("Origin"))
+	assert.Equal(t, "", header.Get("Authorization"))
+	assert.Equal(t, "", header.Get("Signature"))
+	assert.Equal(t, "", header.Get("X-Foo"))
+	assert.True(t, matched)
+
+	e.GET("/users").Expect()
+
+	assert.Equal(t, "Bearer token", header.Get("Authorization"))
+	assert.NotEqual(t, "", header.Get("Signature"))
+	assert.Equal(t, "bar", header.Get("X-Foo"))
+}
@@ -22,7 +22,7 @@ type DateTime struct {
 //   time.Sleep(time.Second)
 //   dt.Lt(time.Now())
 func NewDateTime(reporter Reporter, value time.Time) *DateTime {
-	return &DateTime{makeChain(reporter), value}
+	return &DateTime{makeChain(reporter).root("DateTime"), value}
 }
 
 // Raw returns underlying time.Time value attached to DateTime.
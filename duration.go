@@ -19,7 +19,7 @@ type Duration struct {
 //   d := NewDuration(reporter, time.Second)
 //   d.Le(time.Minute)
 func NewDuration(reporter Reporter, value time.Duration) *Duration {
-	return &Duration{makeChain(reporter), &value}
+	return &Duration{makeChain(reporter).root("Duration"), &value}
 }
 
 // Raw returns underlying time.Duration value attached to Duration.
@@ -66,6 +66,7 @@
 package httpexpect
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/http/cookiejar"
@@ -129,6 +130,64 @@ type Config struct {
 	// you're happy with their format, but want to send logs somewhere
 	// else instead of testing.TB.
 	Printers []Printer
+
+	// Context is used as the parent context for all requests.
+	// May be nil.
+	//
+	// If non-nil, every request inherits this context, so cancelling it
+	// aborts all pending requests. Can be overridden for a single request
+	// using Request.WithContext.
+	Context context.Context
+
+	// RequestTimeout defines the default timeout for every request.
+	// May be zero, which means no timeout.
+	//
+	// The timeout covers sending request, receiving response headers and
+	// reading response body (or WebSocket handshake). Can be overridden
+	// for a single request using Request.WithTimeout.
+	//
+	// If RetryPolicy is used, the timeout is applied to every attempt.
+	RequestTimeout time.Duration
+
+	// RetryPolicy defines whether and how failed requests are retried.
+	// May be nil, which means that requests are never retried.
+	//
+	// Can be overridden for a single request using Request.WithRetryPolicy.
+	RetryPolicy *RetryPolicy
+
+	// OpenAPI defines the API contract that requests and responses are
+	// validated against. May be nil, which means that no validation is done.
+	//
+	// Can be overridden for a single request using Request.WithOpenAPI.
+	OpenAPI *OpenAPI
+
+	// Authenticator adds credentials to every request. May be nil, which
+	// means that credentials are added only explicitly, e.g. by
+	// Request.WithBasicAuth.
+	//
+	// You can use BearerAuthenticator, APIKeyAuthenticator,
+	// DigestAuthenticator, OAuth2Authenticator, or provide custom
+	// implementation. Can be overridden for a single request using
+	// Request.WithAuthenticator.
+	Authenticator Authenticator
+
+	// Signer signs every request. May be nil, which means that requests
+	// are not signed.
+	//
+	// Requests are signed after they are fully built and credentials are
+	// added, and before they are passed to printers. You can use
+	// AWSSigV4Signer, HMACSigner, HTTPSignatureSigner, or provide custom
+	// implementation. Can be overridden for a single request using
+	// Request.WithSigner.
+	Signer Signer
+
+	// ContentCodings defines additional content codings, like "zstd", or
+	// overrides built-in ones, by name. May be nil.
+	//
+	// Content codings are used to decompress response body according to
+	// Content-Encoding header, and to compress request body when
+	// Request.WithCompression is used. See ContentCoding for details.
+	ContentCodings map[string]ContentCoding
 }
 
 // RequestFactory is used to create all http.Request objects.
@@ -172,6 +231,19 @@ type WebsocketDialer interface {
 	Dial(url string, reqH http.Header) (*websocket.Conn, *http.Response, error)
 }
 
+// WebsocketDialerContext is an optional interface that may be implemented
+// by WebsocketDialer to support request context.
+//
+// If WebsocketDialer doesn't implement this interface, Dial is invoked in
+// background and is abandoned when the context is done.
+type WebsocketDialerContext interface {
+	WebsocketDialer
+
+	// DialContext is like Dial, but aborts dialing when ctx is done.
+	DialContext(ctx context.Context, url string, reqH http.Header) (
+		*websocket.Conn, *http.Response, error)
+}
+
 // Printer is used to print requests and responses.
 // CompactPrinter, DebugPrinter, and CurlPrinter implement this interface.
 type Printer interface {
@@ -187,7 +259,7 @@ type Printer interface {
 // If WebSocket connection is used, all Printers that also implement WebsocketPrinter
 // are invoked on every WebSocket message read or written.
 //
-// DebugPrinter implements this interface.
+// DebugPrinter and HARPrinter implement this interface.
 type WebsocketPrinter interface {
 	Printer
 
@@ -213,6 +285,19 @@ type Reporter interface {
 	Errorf(message string, args ...interface{})
 }
 
+// FailureReporter is an optional interface that may be implemented by
+// Reporter to receive structured failures.
+//
+// If Reporter implements FailureReporter, ReportFailure is invoked instead
+// of Errorf for every failure.
+type FailureReporter interface {
+	Reporter
+
+	// ReportFailure reports failure.
+	// Allowed to return normally or terminate test using t.FailNow().
+	ReportFailure(failure *AssertionFailure)
+}
+
 // LoggerReporter combines Logger and Reporter interfaces.
 type LoggerReporter interface {
 	Logger
@@ -351,7 +436,9 @@ func NewJar() http.CookieJar {
 //     Status(http.StatusOK)
 func (e *Expect) Builder(builder func(*Request)) *Expect {
 	ret := *e
-	ret.builders = append(e.builders, builder)
+	// full slice expression forces a copy, so that copies derived from
+	// the same instance don't overwrite each other's builders
+	ret.builders = append(e.builders[:len(e.builders):len(e.builders)], builder)
 	return &ret
 }
 
@@ -375,10 +462,127 @@ func (e *Expect) Builder(builder func(*Request)) *Expect {
 // 	    Status(http.StatusNotFound)
 func (e *Expect) Matcher(matcher func(*Response)) *Expect {
 	ret := *e
-	ret.matchers = append(e.matchers, matcher)
+	ret.matchers = append(e.matchers[:len(e.matchers):len(e.matchers)], matcher)
 	return &ret
 }
 
+// Soft invokes given function with a copy of Expect instance that collects
+// failures instead of reporting them immediately.
+//
+// Every chain created inside the function still stops at its first failure,
+// but failures of independent chains don't affect each other, even if the
+// reporter terminates the test on failure, like RequireReporter. When the
+// function returns, all collected failures are sent to the reporter as
+// a single combined failure, listing every failed assertion with its request.
+//
+// Example:
+//  e := httpexpect.WithConfig(httpexpect.Config{
+//      BaseURL:  "http://example.com",
+//      Reporter: httpexpect.NewRequireReporter(t),
+//  })
+//
+//  e.Soft(func(e *httpexpect.Expect) {
+//      obj := e.GET("/user").Expect().JSON().Object()
+//
+//      obj.ValueEqual("name", "john")  // reported later
+//      obj.ValueEqual("age", 42)       // reported later too
+//  })
+func (e *Expect) Soft(fn func(e *Expect)) {
+	recorder := newRecordingReporter()
+
+	soft := *e
+	soft.config.Reporter = recorder
+
+	defer recorder.replayCombined(e.config.Reporter, "Expect.Soft")
+
+	fn(&soft)
+}
+
+// Session returns a copy of Expect instance that represents a separate
+// client session, e.g. another user of the tested service.
+//
+// Returned copy shares Config, builders, and matchers with the original
+// instance, with the following exceptions:
+//  - if Config.Client is http.Client with non-nil Jar, session uses a copy
+//    of the client with its own empty jar, so that cookies aren't shared;
+//    other clients can't be isolated, so failure is reported for them
+//  - Config.Authenticator is not inherited; use WithAuthenticator to set
+//    session credentials
+//  - every failure reported by session is tagged with session name
+//
+// Session-specific default headers may be added using WithHeader.
+//
+// Example:
+//  e := httpexpect.New(t, "http://example.com")
+//
+//  alice := e.Session("alice").
+//      WithAuthenticator(httpexpect.NewBearerAuthenticator(aliceToken))
+//  bob := e.Session("bob").
+//      WithAuthenticator(httpexpect.NewBearerAuthenticator(bobToken))
+//
+//  alice.POST("/chats/1/messages").WithJSON(msg).
+//      Expect().
+//      Status(http.StatusCreated)
+//
+//  bob.GET("/chats/1/messages").
+//      Expect().
+//      Status(http.StatusOK).JSON().Array().Length().Equal(1)
+func (e *Expect) Session(name string) *Expect {
+	ret := *e
+
+	reporter := e.config.Reporter
+	if parent, ok := reporter.(*sessionReporter); ok {
+		reporter = parent.reporter
+	}
+	ret.config.Reporter = &sessionReporter{name: name, reporter: reporter}
+
+	if client, ok := e.config.Client.(*http.Client); ok {
+		if client.Jar != nil {
+			sessionClient := *client
+			sessionClient.Jar = NewJar()
+			ret.config.Client = &sessionClient
+		}
+	} else {
+		chain := makeChain(ret.config.Reporter)
+		chain.fail(
+			"\nsession can't isolate cookies: expected Config.Client to be"+
+				" *http.Client, but got %T", e.config.Client)
+	}
+
+	ret.config.Authenticator = nil
+
+	return &ret
+}
+
+// WithAuthenticator returns a copy of Expect instance that uses given
+// authenticator for all requests, instead of Config.Authenticator.
+//
+// Example:
+//  e := httpexpect.New(t, "http://example.com")
+//
+//  admin := e.Session("admin").
+//      WithAuthenticator(httpexpect.NewDigestAuthenticator("admin", "secret"))
+func (e *Expect) WithAuthenticator(authenticator Authenticator) *Expect {
+	ret := *e
+	ret.config.Authenticator = authenticator
+	return &ret
+}
+
+// WithHeader returns a copy of Expect instance that adds given header
+// to all requests. It is a shorthand for a Builder that invokes
+// Request.WithHeader.
+//
+// Example:
+//  e := httpexpect.New(t, "http://example.com")
+//
+//  alice := e.Session("alice").WithHeader("Accept-Language", "en")
+//  bob := e.Session("bob").WithHeader("Accept-Language", "de")
+func (e *Expect) WithHeader(k, v string) *Expect {
+	return e.Builder(func(req *Request) {
+		req.WithHeader(k, v)
+	})
+}
+
 // Request returns a new Request object.
 // Arguments a similar to NewRequest.
 // After creating request, all builders attached to Expect object are invoked.
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -124,6 +125,264 @@ func TestExpectMatchers(t *testing.T) {
 	assert.Equal(t, resp2, resps2[0])
 }
 
+func TestExpectBranchesBuilders(t *testing.T) {
+	e := WithConfig(Config{
+		Client:   &mockClient{},
+		Reporter: NewAssertReporter(t),
+	})
+
+	// three builders and matchers, so that slices have spare capacity
+	for n := 0; n < 3; n++ {
+		e = e.Builder(func(*Request) {}).Matcher(func(*Response) {})
+	}
+
+	var built, matched []string
+
+	e1 := e.
+		Builder(func(*Request) { built = append(built, "e1") }).
+		Matcher(func(*Response) { matched = append(matched, "e1") })
+	e2 := e.
+		Builder(func(*Request) { built = append(built, "e2") }).
+		Matcher(func(*Response) { matched = append(matched, "e2") })
+
+	e1.GET("/").Expect()
+	e2.GET("/").Expect()
+
+	assert.Equal(t, []string{"e1", "e2"}, built)
+	assert.Equal(t, []string{"e1", "e2"}, matched)
+}
+
+func TestExpectSoft(t *testing.T) {
+	client := &mockClient{
+		resp: http.Response{
+			StatusCode: http.StatusOK,
+		},
+	}
+
+	reporter := newMockFailureReporter(t)
+
+	config := Config{
+		BaseURL:  "http://example.com",
+		Client:   client,
+		Reporter: reporter,
+	}
+
+	e := WithConfig(config)
+
+	var inner *Expect
+
+	e.Soft(func(e *Expect) {
+		inner = e
+
+		e.GET("/foo").Expect().Status(http.StatusNotFound)
+		e.GET("/bar").Expect().Status(http.StatusOK)
+		e.POST("/baz").Expect().Status(http.StatusCreated)
+
+		e.Number(123).Equal(456).Equal(789)
+
+		assert.False(t, reporter.reported)
+	})
+
+	assert.True(t, reporter.reported)
+	assert.Equal(t, 1, len(reporter.failures))
+
+	failure := reporter.failures[0]
+
+	assert.Equal(t, "Expect.Soft", failure.Assertion)
+	assert.Equal(t, 3, len(failure.Failures))
+
+	assert.Equal(t, "Response.Status", failure.Failures[0].Assertion)
+	assert.Equal(t, "GET", failure.Failures[0].Request.Method)
+	assert.Equal(t, "Response.Status", failure.Failures[1].Assertion)
+	assert.Equal(t, "POST", failure.Failures[1].Request.Method)
+	assert.Equal(t, "Number.Equal", failure.Failures[2].Assertion)
+	assert.Nil(t, failure.Failures[2].Request)
+
+	assert.Contains(t, failure.Message, "3 assertions failed")
+	assert.Contains(t, failure.Message, "GET http://example.com/foo")
+	assert.Contains(t, failure.Message, "POST http://example.com/baz")
+	assert.NotContains(t, failure.Message, "/bar")
+
+	assert.Equal(t, reporter, e.config.Reporter)
+	assert.NotEqual(t, reporter, inner.config.Reporter)
+}
+
+func TestExpectSoftSuccess(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	e := WithConfig(Config{
+		Reporter: reporter,
+	})
+
+	e.Soft(func(e *Expect) {
+		e.Number(123).Equal(123)
+		e.String("foo").Equal("foo")
+	})
+
+	assert.False(t, reporter.reported)
+}
+
+func TestExpectSoftFallback(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	e := WithConfig(Config{
+		Reporter: reporter,
+	})
+
+	e.Soft(func(e *Expect) {
+		e.Number(123).Equal(456)
+	})
+
+	assert.True(t, reporter.reported)
+}
+
+func TestExpectSession(t *testing.T) {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{
+			Name:  "user",
+			Value: r.URL.Query().Get("name"),
+			Path:  "/",
+		})
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("/whoami", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("user")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(cookie.Value + " " +
+			r.Header.Get("Authorization") + " " + r.Header.Get("X-Lang")))
+	})
+
+	client := &http.Client{
+		Transport: NewBinder(mux),
+		Jar:       NewJar(),
+	}
+
+	e := WithConfig(Config{
+		BaseURL:       "http://example.com",
+		Reporter:      NewAssertReporter(t),
+		Client:        client,
+		Authenticator: NewBearerAuthenticator("base"),
+	}).WithHeader("X-Lang", "en")
+
+	alice := e.Session("alice").
+		WithAuthenticator(NewBearerAuthenticator("alice"))
+	bob := e.Session("bob").
+		WithHeader("X-Lang", "de")
+
+	alice.POST("/login").WithQuery("name", "alice").
+		Expect().
+		Status(http.StatusNoContent)
+
+	bob.POST("/login").WithQuery("name", "bob").
+		Expect().
+		Status(http.StatusNoContent)
+
+	alice.GET("/whoami").
+		Expect().
+		Status(http.StatusOK).Body().Equal("alice Bearer alice en")
+
+	bob.GET("/whoami").
+		Expect().
+		Status(http.StatusOK).Body().Equal("bob  en")
+
+	e.GET("/whoami").
+		Expect().
+		Status(http.StatusUnauthorized)
+
+	e.Cookies("/").Empty()
+	alice.Cookies("/").Cookie("user").Value().Equal("alice")
+	bob.Cookies("/").Cookie("user").Value().Equal("bob")
+
+	assert.True(t, client.Jar != alice.config.Client.(*http.Client).Jar)
+	assert.True(t, client.Transport == alice.config.Client.(*http.Client).Transport)
+}
+
+func TestExpectSessionFailures(t *testing.T) {
+	reporter := newMockFailureReporter(t)
+
+	handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	e := WithConfig(Config{
+		BaseURL: "http://example.com",
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+		Reporter: reporter,
+	})
+
+	alice := e.Session("alice")
+	admin := alice.Session("admin")
+
+	alice.GET("/foo").Expect().Status(http.StatusNotFound)
+	admin.Number(1).Equal(2)
+
+	alice.Soft(func(e *Expect) {
+		e.Number(1).Equal(2)
+	})
+
+	if assert.Equal(t, 3, len(reporter.failures)) {
+		assert.Equal(t, "alice", reporter.failures[0].Session)
+		assert.Equal(t, "Response.Status", reporter.failures[0].Assertion)
+		assert.Equal(t, "GET", reporter.failures[0].Request.Method)
+		assert.True(t, strings.HasPrefix(reporter.failures[0].Message,
+			"\n[session alice]\n"))
+
+		assert.Equal(t, "admin", reporter.failures[1].Session)
+		assert.NotContains(t, reporter.failures[1].Message, "alice")
+
+		assert.Equal(t, "alice", reporter.failures[2].Session)
+		assert.Equal(t, "Expect.Soft", reporter.failures[2].Assertion)
+	}
+
+	reporter = newMockFailureReporter(t)
+
+	e = WithConfig(Config{
+		Reporter: reporter,
+	})
+
+	e.Number(1).Equal(2)
+
+	if assert.Equal(t, 1, len(reporter.failures)) {
+		assert.Equal(t, "", reporter.failures[0].Session)
+	}
+
+	reporter = newMockFailureReporter(t)
+
+	e = WithConfig(Config{
+		Client:   &mockClient{},
+		Reporter: reporter,
+	})
+
+	e.Session("carol")
+
+	if assert.Equal(t, 1, len(reporter.failures)) {
+		assert.Equal(t, "carol", reporter.failures[0].Session)
+		assert.Contains(t, reporter.failures[0].Message, "*httpexpect.mockClient")
+	}
+}
+
+func TestExpectSessionBuilders(t *testing.T) {
+	e := WithConfig(Config{
+		BaseURL:  "http://example.com",
+		Client:   &http.Client{},
+		Reporter: NewAssertReporter(t),
+	}).WithHeader("X-Base", "1")
+
+	alice := e.Session("alice").WithHeader("X-User", "alice")
+	bob := e.Session("bob").WithHeader("X-User", "bob")
+
+	assert.Equal(t, "alice", alice.GET("/").http.Header.Get("X-User"))
+	assert.Equal(t, "bob", bob.GET("/").http.Header.Get("X-User"))
+	assert.Equal(t, "1", bob.GET("/").http.Header.Get("X-Base"))
+	assert.Equal(t, "", e.GET("/").http.Header.Get("X-User"))
+}
+
 func TestExpectValues(t *testing.T) {
 	client := &mockClient{}
 
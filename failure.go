@@ -0,0 +1,92 @@
+package httpexpect
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// AssertionFailure describes a failed assertion.
+//
+// It is passed to Reporter, if it implements FailureReporter interface.
+// Otherwise, only Message is passed to Reporter.Errorf.
+type AssertionFailure struct {
+	// Assertion is the name of the function or method that reported the
+	// failure, e.g. "Object.ValueEqual" or "Request.Expect".
+	Assertion string
+
+	// Path is the chain of calls that produced the failed object, e.g.
+	// `Response.JSON().Object().Value("items")`.
+	Path string
+
+	// JSONPath is the location of the checked value inside the JSON
+	// document, e.g. "$.data[3].id", if available.
+	JSONPath string
+
+	// Expected is the expected value, if available.
+	Expected interface{}
+
+	// Actual is the actual value, if available.
+	Actual interface{}
+
+	// Diff is the difference between expected and actual values,
+	// if available.
+	Diff string
+
+	// Message is the formatted human-readable description of the failure.
+	Message string
+
+	// Request is the HTTP request related to the failure, if any.
+	Request *http.Request
+
+	// Response is the HTTP response related to the failure, if any.
+	Response *http.Response
+
+	// Session is the name of the session which request failed, if the
+	// request was created using Expect.Session.
+	Session string
+
+	// Failures contains all collected failures, if this failure combines
+	// several failures, e.g. in Expect.Soft.
+	Failures []*AssertionFailure
+}
+
+// reportFailure passes failure to reporter, using FailureReporter interface
+// if it's implemented.
+func reportFailure(reporter Reporter, failure *AssertionFailure) {
+	if fr, ok := reporter.(FailureReporter); ok {
+		fr.ReportFailure(failure)
+	} else {
+		reporter.Errorf("%s", failure.Message)
+	}
+}
+
+// formatFailures returns a message listing all failures, every failure
+// with its assertion, path, and request.
+func formatFailures(failures []*AssertionFailure) string {
+	var b strings.Builder
+
+	if len(failures) == 1 {
+		fmt.Fprintf(&b, "\n1 assertion failed")
+	} else {
+		fmt.Fprintf(&b, "\n%d assertions failed", len(failures))
+	}
+
+	for n, failure := range failures {
+		fmt.Fprintf(&b, "\n\n--- failure %d of %d", n+1, len(failures))
+		if failure.Assertion != "" {
+			fmt.Fprintf(&b, ": %s", failure.Assertion)
+		}
+		if failure.Path != "" {
+			fmt.Fprintf(&b, "\n\npath:\n %s", failure.Path)
+		}
+		if failure.Request != nil && failure.Request.URL != nil {
+			fmt.Fprintf(&b, "\n\nrequest:\n %s %s",
+				failure.Request.Method, failure.Request.URL)
+		}
+		b.WriteString("\n\n")
+		b.WriteString(strings.TrimLeft(failure.Message, "\n"))
+	}
+
+	return b.String()
+}
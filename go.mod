@@ -4,6 +4,8 @@ go 1.12
 
 require (
 	github.com/ajg/form v1.5.1
+	github.com/andybalholm/brotli v1.0.6
+	github.com/antchfx/xpath v1.2.0
 	github.com/fasthttp-contrib/websocket v0.0.0-20160511215533-1f3b11f56072
 	github.com/fatih/structs v1.0.0
 	github.com/google/go-querystring v1.0.0
@@ -19,11 +21,10 @@ require (
 	github.com/xeipuuv/gojsonpointer v0.0.0-20180127040702-4e3ac2762d5f // indirect
 	github.com/xeipuuv/gojsonreference v0.0.0-20180127040603-bd5ef7bd5415 // indirect
 	github.com/xeipuuv/gojsonschema v1.1.0
-	github.com/yalp/jsonpath v0.0.0-20180802001716-5cc68e5049a0
 	github.com/yudai/gojsondiff v1.0.0
 	github.com/yudai/golcs v0.0.0-20170316035057-ecda9a501e82 // indirect
 	github.com/yudai/pp v2.0.1+incompatible // indirect
 	golang.org/x/net v0.0.0-20190724013045-ca1201d0de80
-	gopkg.in/yaml.v2 v2.2.2 // indirect
+	gopkg.in/yaml.v2 v2.4.0
 	moul.io/http2curl v1.0.1-0.20190925090545-5cd742060b0e
 )
@@ -0,0 +1,671 @@
+package httpexpect
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// GraphQLTransportWS is the "graphql-transport-ws" subscription protocol,
+	// implemented by https://github.com/enisdenjo/graphql-ws.
+	GraphQLTransportWS = "graphql-transport-ws"
+
+	// GraphQLWS is the legacy "graphql-ws" subscription protocol,
+	// implemented by https://github.com/apollographql/subscriptions-transport-ws.
+	GraphQLWS = "graphql-ws"
+)
+
+// GraphQLOpts defines additional parameters of GraphQL operation.
+type GraphQLOpts struct {
+	// OperationName selects operation to execute when query contains
+	// multiple operations.
+	OperationName string
+
+	// Extensions are sent in "extensions" field of the request.
+	Extensions map[string]interface{}
+
+	// PersistedQuery enables automatic persisted queries. The request is
+	// first sent with SHA-256 hash of the query in "extensions" field and
+	// without query text. If server doesn't know the hash, it replies with
+	// PersistedQueryNotFound error, and the request is sent again with both
+	// hash and query text.
+	PersistedQuery bool
+}
+
+// GraphQLWebsocketOpts defines parameters of GraphQL subscription connection.
+type GraphQLWebsocketOpts struct {
+	// Protocol is the subscription protocol, GraphQLTransportWS or GraphQLWS.
+	// If empty, the protocol negotiated during WebSocket handshake is used,
+	// or GraphQLTransportWS if none was negotiated.
+	Protocol string
+
+	// InitPayload is sent in "connection_init" message, e.g. to authenticate.
+	InitPayload interface{}
+}
+
+// GraphQL sends GraphQL operation with given query and variables in POST
+// request to given path and returns a new GraphQLResponse object to inspect
+// the result.
+//
+// variables may be nil, a map, or a struct that can be marshaled to JSON.
+//
+// Example:
+//  e := httpexpect.New(t, "http://example.com")
+//
+//  resp := e.GraphQL("/graphql", `query ($id: ID!) { user(id: $id) { name } }`,
+//      map[string]interface{}{"id": 1})
+//
+//  resp.NoErrors()
+//  resp.Data().Path("$.user.name").String().Equal("john")
+func (e *Expect) GraphQL(
+	path, query string, variables interface{}, opts ...GraphQLOpts,
+) *GraphQLResponse {
+	var opt GraphQLOpts
+	if len(opts) != 0 {
+		opt = opts[0]
+	}
+
+	if opt.PersistedQuery {
+		req := e.POST(path).
+			WithJSON(makeGraphQLPayload(query, variables, opt, false))
+
+		// matchers are invoked only if the response is returned, since
+		// the server rejects hash-only request until query is registered
+		resp := req.roundTrip()
+		if resp == nil || !isGraphQLPersistedQueryNotFound(resp) {
+			return req.matchResponse(resp).GraphQL()
+		}
+	}
+
+	return e.POST(path).
+		WithJSON(makeGraphQLPayload(query, variables, opt, true)).
+		Expect().
+		GraphQL()
+}
+
+// GraphQLWebsocket establishes WebSocket connection to given path, offering
+// GraphQL subscription protocols, performs the protocol handshake, and
+// returns a new GraphQLWebsocket object to start subscriptions.
+//
+// Example:
+//  conn := e.GraphQLWebsocket("/graphql")
+//  defer conn.Disconnect()
+//
+//  sub := conn.Subscribe(`subscription { messages { text } }`, nil)
+//  sub.Expect().Data().Path("$.messages.text").String().Equal("hello")
+func (e *Expect) GraphQLWebsocket(
+	path string, opts ...GraphQLWebsocketOpts,
+) *GraphQLWebsocket {
+	protocols := []string{GraphQLTransportWS, GraphQLWS}
+	if len(opts) != 0 && opts[0].Protocol != "" {
+		protocols = []string{opts[0].Protocol}
+	}
+
+	return e.GET(path).
+		WithWebsocketUpgrade().
+		WithHeader("Sec-WebSocket-Protocol", strings.Join(protocols, ", ")).
+		Expect().
+		Websocket().
+		GraphQL(opts...)
+}
+
+func makeGraphQLPayload(
+	query string, variables interface{}, opts GraphQLOpts, withQuery bool,
+) map[string]interface{} {
+	payload := map[string]interface{}{}
+
+	if withQuery {
+		payload["query"] = query
+	}
+	if variables != nil {
+		payload["variables"] = variables
+	}
+	if opts.OperationName != "" {
+		payload["operationName"] = opts.OperationName
+	}
+
+	extensions := map[string]interface{}{}
+	for k, v := range opts.Extensions {
+		extensions[k] = v
+	}
+	if opts.PersistedQuery {
+		hash := sha256.Sum256([]byte(query))
+		extensions["persistedQuery"] = map[string]interface{}{
+			"version":    1,
+			"sha256Hash": hex.EncodeToString(hash[:]),
+		}
+	}
+	if len(extensions) != 0 {
+		payload["extensions"] = extensions
+	}
+
+	return payload
+}
+
+func isGraphQLPersistedQueryNotFound(resp *Response) bool {
+	if resp.chain.failed() {
+		return false
+	}
+
+	var result struct {
+		Errors []struct {
+			Message    string
+			Extensions struct {
+				Code string
+			}
+		}
+	}
+	if json.Unmarshal(resp.content, &result) != nil {
+		return false
+	}
+
+	for _, err := range result.Errors {
+		if err.Message == "PersistedQueryNotFound" ||
+			err.Extensions.Code == "PERSISTED_QUERY_NOT_FOUND" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GraphQLResponse provides methods to inspect result of GraphQL operation.
+type GraphQLResponse struct {
+	chain chain
+	value map[string]interface{}
+}
+
+// NewGraphQLResponse returns a new GraphQLResponse object given a reporter
+// used to report failures and GraphQL result to be inspected, i.e. decoded
+// JSON object with "data" and/or "errors" fields.
+//
+// reporter should not be nil.
+//
+// Example:
+//  resp := NewGraphQLResponse(t, map[string]interface{}{
+//      "data": map[string]interface{}{"user": nil},
+//  })
+//  resp.NoErrors()
+func NewGraphQLResponse(
+	reporter Reporter, value map[string]interface{},
+) *GraphQLResponse {
+	chain := makeChain(reporter).root("GraphQLResponse")
+	checkGraphQLResult(&chain, value)
+	return &GraphQLResponse{chain, value}
+}
+
+func makeGraphQLResponse(chain chain, value interface{}) *GraphQLResponse {
+	return &GraphQLResponse{chain, checkGraphQLResult(&chain, value)}
+}
+
+func checkGraphQLResult(chain *chain, value interface{}) map[string]interface{} {
+	if chain.failed() {
+		return nil
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		chain.fail("\nexpected GraphQL result object, but got:\n%s",
+			dumpValue(value))
+		return nil
+	}
+
+	_, hasData := object["data"]
+	_, hasErrors := object["errors"]
+	if !hasData && !hasErrors {
+		chain.fail("\nexpected GraphQL result with \"data\" or \"errors\","+
+			" but got:\n%s", dumpValue(object))
+		return nil
+	}
+
+	return object
+}
+
+// GraphQL returns a new GraphQLResponse object that may be used to inspect
+// GraphQL result in response.
+//
+// GraphQL succeeds if response contains "application/json" or
+// "application/graphql-response+json" Content-Type header with empty or
+// "utf-8" charset and if body is a JSON object with "data" and/or "errors"
+// fields. Response status is not checked, since GraphQL servers may use
+// non-2xx statuses for results with errors.
+//
+// Example:
+//  resp := NewResponse(t, response)
+//  resp.GraphQL().NoErrors()
+func (r *Response) GraphQL(opts ...ContentOpts) *GraphQLResponse {
+	value := r.getGraphQL(opts...)
+	return makeGraphQLResponse(r.chain.enter("GraphQL()"), value)
+}
+
+func (r *Response) getGraphQL(opts ...ContentOpts) interface{} {
+	if r.chain.failed() {
+		return nil
+	}
+
+	expectedType := "application/json"
+	mediaType, _, err := mime.ParseMediaType(r.resp.Header.Get("Content-Type"))
+	if err == nil && mediaType == "application/graphql-response+json" {
+		expectedType = mediaType
+	}
+
+	if !r.checkContentOpts(opts, expectedType) || !r.checkContent() {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(r.content, &value); err != nil {
+		r.chain.fail(err.Error())
+		return nil
+	}
+
+	return value
+}
+
+// Raw returns underlying GraphQL result.
+func (g *GraphQLResponse) Raw() map[string]interface{} {
+	return g.value
+}
+
+// Data returns a new Value object that may be used to inspect "data" field
+// of GraphQL result.
+//
+// Example:
+//  resp := NewGraphQLResponse(t, result)
+//  resp.Data().Path("$.user.name").String().Equal("john")
+func (g *GraphQLResponse) Data() *Value {
+	chain := g.chain.enter("Data()").rootJSON().enterKey("data")
+	return &Value{chain, g.value["data"]}
+}
+
+// Errors returns a new Array object that may be used to inspect "errors"
+// field of GraphQL result. If there are no errors, the array is empty.
+//
+// Example:
+//  resp := NewGraphQLResponse(t, result)
+//  resp.Errors().Length().Equal(1)
+//  resp.Errors().First().Object().ValueEqual("message", "not found")
+func (g *GraphQLResponse) Errors() *Array {
+	chain := g.chain.enter("Errors()").rootJSON().enterKey("errors")
+	return &Array{chain, g.getErrors(&chain)}
+}
+
+// NoErrors succeeds if GraphQL result has no errors.
+//
+// Example:
+//  resp := NewGraphQLResponse(t, result)
+//  resp.NoErrors()
+func (g *GraphQLResponse) NoErrors() *GraphQLResponse {
+	errors := g.getErrors(&g.chain)
+	if len(errors) != 0 {
+		g.chain.fail("\nexpected GraphQL result without errors, but got:\n%s",
+			dumpValue(errors))
+	}
+	return g
+}
+
+// ErrorWithCode returns a new Object for the first error of GraphQL result
+// with given "extensions.code" field.
+//
+// If there is no such error, failure is reported.
+//
+// Example:
+//  resp := NewGraphQLResponse(t, result)
+//  resp.ErrorWithCode("NOT_FOUND").ValueEqual("path", []string{"user"})
+func (g *GraphQLResponse) ErrorWithCode(code string) *Object {
+	chain := g.chain.enter("ErrorWithCode(%q)", code).rootJSON().enterKey("errors")
+
+	errors := g.getErrors(&chain)
+	if chain.failed() {
+		return &Object{chain, nil}
+	}
+
+	for n, e := range errors {
+		object, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		extensions, _ := object["extensions"].(map[string]interface{})
+		if c, ok := extensions["code"]; ok && fmt.Sprint(c) == code {
+			return &Object{chain.enterIndex(n), object}
+		}
+	}
+
+	chain.fail("\nexpected GraphQL result with error code %q, but got errors:\n%s",
+		code, dumpValue(errors))
+
+	return &Object{chain, nil}
+}
+
+func (g *GraphQLResponse) getErrors(chain *chain) []interface{} {
+	if chain.failed() {
+		return nil
+	}
+
+	value, ok := g.value["errors"]
+	if !ok || value == nil {
+		return []interface{}{}
+	}
+
+	errors, ok := value.([]interface{})
+	if !ok {
+		chain.fail("\nexpected GraphQL \"errors\" array, but got:\n%s",
+			dumpValue(value))
+		return nil
+	}
+
+	return errors
+}
+
+// GraphQLWebsocket provides methods to start GraphQL subscriptions over
+// WebSocket connection.
+//
+// Both GraphQLTransportWS and GraphQLWS protocols are supported. Server
+// keep-alive and ping messages are handled automatically, and messages
+// for different subscriptions are dispatched by subscription ID, so that
+// multiple subscriptions may be inspected independently.
+type GraphQLWebsocket struct {
+	chain    chain
+	ws       *Websocket
+	protocol string
+	lastID   int
+	pending  map[string][]graphqlMessage
+}
+
+type graphqlMessage struct {
+	ID      string      `json:"id,omitempty"`
+	Type    string      `json:"type"`
+	Payload interface{} `json:"payload,omitempty"`
+}
+
+// GraphQL performs GraphQL subscription protocol handshake on WebSocket
+// connection and returns a new GraphQLWebsocket object to start
+// subscriptions.
+//
+// The connection should be established with "Sec-WebSocket-Protocol"
+// header listing desired protocols. Expect.GraphQLWebsocket does it
+// automatically.
+//
+// Example:
+//  ws := e.GET("/graphql").
+//      WithWebsocketUpgrade().
+//      WithHeader("Sec-WebSocket-Protocol", httpexpect.GraphQLTransportWS).
+//      Expect().
+//      Websocket()
+//
+//  conn := ws.GraphQL(httpexpect.GraphQLWebsocketOpts{
+//      InitPayload: map[string]interface{}{"token": "secret"},
+//  })
+func (c *Websocket) GraphQL(opts ...GraphQLWebsocketOpts) *GraphQLWebsocket {
+	g := &GraphQLWebsocket{
+		chain:   c.chain.enter("GraphQL()"),
+		ws:      c,
+		pending: map[string][]graphqlMessage{},
+	}
+
+	if g.chain.failed() {
+		return g
+	}
+
+	if c.conn == nil {
+		g.chain.fail("\nunexpected GraphQL handshake on failed WebSocket connection")
+		return g
+	}
+
+	var opt GraphQLWebsocketOpts
+	if len(opts) != 0 {
+		opt = opts[0]
+	}
+
+	g.protocol = opt.Protocol
+	if g.protocol == "" {
+		g.protocol = c.conn.Subprotocol()
+	}
+	if g.protocol == "" {
+		g.protocol = GraphQLTransportWS
+	}
+
+	if g.protocol != GraphQLTransportWS && g.protocol != GraphQLWS {
+		g.chain.fail("\nunsupported GraphQL subscription protocol %q", g.protocol)
+		return g
+	}
+
+	if !g.write(&g.chain, graphqlMessage{
+		Type:    "connection_init",
+		Payload: opt.InitPayload,
+	}) {
+		return g
+	}
+
+	msg, ok := g.read(&g.chain, "")
+	if ok && msg.Type != "connection_ack" {
+		g.chain.fail("\nexpected GraphQL \"connection_ack\" message, but got:\n%s",
+			dumpValue(msg))
+	}
+
+	return g
+}
+
+// Websocket returns underlying Websocket object.
+func (g *GraphQLWebsocket) Websocket() *Websocket {
+	return g.ws
+}
+
+// Protocol returns a new String object that may be used to inspect
+// GraphQL subscription protocol used for the connection.
+func (g *GraphQLWebsocket) Protocol() *String {
+	return &String{g.chain.enter("Protocol()"), g.protocol}
+}
+
+// Subscribe starts GraphQL subscription with given query and variables and
+// returns a new GraphQLSubscription object to inspect subscription events.
+//
+// Example:
+//  conn := e.GraphQLWebsocket("/graphql")
+//  defer conn.Disconnect()
+//
+//  sub := conn.Subscribe(`subscription ($room: ID!) { messages(room: $room) { text } }`,
+//      map[string]interface{}{"room": 1})
+//
+//  sub.Expect().Data().Path("$.messages.text").String().Equal("hello")
+//  sub.Unsubscribe()
+func (g *GraphQLWebsocket) Subscribe(
+	query string, variables interface{}, opts ...GraphQLOpts,
+) *GraphQLSubscription {
+	g.lastID++
+
+	sub := &GraphQLSubscription{
+		chain: g.chain.enter("Subscribe()"),
+		conn:  g,
+		id:    strconv.Itoa(g.lastID),
+	}
+
+	if sub.chain.failed() {
+		return sub
+	}
+
+	var opt GraphQLOpts
+	if len(opts) != 0 {
+		opt = opts[0]
+	}
+
+	typ := "subscribe"
+	if g.protocol == GraphQLWS {
+		typ = "start"
+	}
+
+	g.write(&sub.chain, graphqlMessage{
+		ID:      sub.id,
+		Type:    typ,
+		Payload: makeGraphQLPayload(query, variables, opt, true),
+	})
+
+	return sub
+}
+
+// Disconnect closes the underlying WebSocket connection.
+// See Websocket.Disconnect.
+func (g *GraphQLWebsocket) Disconnect() *GraphQLWebsocket {
+	g.ws.Disconnect()
+	return g
+}
+
+func (g *GraphQLWebsocket) write(chain *chain, msg graphqlMessage) bool {
+	if chain.failed() {
+		return false
+	}
+	g.ws.WriteJSON(msg)
+	if g.ws.chain.failed() {
+		chain.failbit = true
+		return false
+	}
+	return true
+}
+
+// read returns next message for given subscription ID, or next connection
+// message if ID is empty. Messages for other subscriptions are queued.
+func (g *GraphQLWebsocket) read(chain *chain, id string) (graphqlMessage, bool) {
+	if chain.failed() {
+		return graphqlMessage{}, false
+	}
+
+	if queue := g.pending[id]; len(queue) != 0 {
+		g.pending[id] = queue[1:]
+		return queue[0], true
+	}
+
+	for {
+		m := g.ws.Expect()
+		if g.ws.chain.failed() {
+			chain.failbit = true
+			return graphqlMessage{}, false
+		}
+
+		if m.typ == websocket.CloseMessage {
+			chain.fail("\nexpected GraphQL message, but WebSocket connection"+
+				" was closed with code %d:\n %q", m.closeCode, string(m.content))
+			return graphqlMessage{}, false
+		}
+
+		var msg graphqlMessage
+		if err := json.Unmarshal(m.content, &msg); err != nil {
+			chain.fail("\nexpected GraphQL message, but got:\n %q\n\n%s",
+				string(m.content), err.Error())
+			return graphqlMessage{}, false
+		}
+
+		switch msg.Type {
+		case "ka", "pong":
+			continue
+
+		case "ping":
+			if !g.write(chain, graphqlMessage{Type: "pong"}) {
+				return graphqlMessage{}, false
+			}
+			continue
+
+		case "connection_error":
+			chain.fail("\nGraphQL connection error:\n%s", dumpValue(msg.Payload))
+			return graphqlMessage{}, false
+		}
+
+		if msg.ID == id {
+			return msg, true
+		}
+
+		g.pending[msg.ID] = append(g.pending[msg.ID], msg)
+	}
+}
+
+// GraphQLSubscription provides methods to inspect events of GraphQL
+// subscription.
+type GraphQLSubscription struct {
+	chain chain
+	conn  *GraphQLWebsocket
+	id    string
+}
+
+// ID returns subscription ID used in protocol messages.
+func (s *GraphQLSubscription) ID() string {
+	return s.id
+}
+
+// Expect reads next event of the subscription and returns a new
+// GraphQLResponse object to inspect it.
+//
+// If the subscription was completed by server, failure is reported.
+// Subscription errors are returned as GraphQL result with "errors" field.
+//
+// Example:
+//  sub := conn.Subscribe(`subscription { messages { text } }`, nil)
+//  sub.Expect().NoErrors().Data().Path("$.messages.text").String().Equal("hello")
+func (s *GraphQLSubscription) Expect() *GraphQLResponse {
+	chain := s.chain.enter("Expect()")
+
+	msg, ok := s.conn.read(&chain, s.id)
+	if !ok {
+		return &GraphQLResponse{chain, nil}
+	}
+
+	switch msg.Type {
+	case "next", "data":
+		return makeGraphQLResponse(chain, msg.Payload)
+
+	case "error":
+		errors, ok := msg.Payload.([]interface{})
+		if !ok {
+			errors = []interface{}{msg.Payload}
+		}
+		return makeGraphQLResponse(chain, map[string]interface{}{
+			"errors": errors,
+		})
+
+	case "complete":
+		chain.fail("\nexpected GraphQL subscription %s event,"+
+			" but subscription was completed", s.id)
+
+	default:
+		chain.fail("\nexpected GraphQL subscription %s event, but got:\n%s",
+			s.id, dumpValue(msg))
+	}
+
+	return &GraphQLResponse{chain, nil}
+}
+
+// ExpectComplete reads next message of the subscription and succeeds if
+// server has completed the subscription.
+//
+// Example:
+//  sub := conn.Subscribe(`subscription { countdown(from: 1) }`, nil)
+//  sub.Expect().Data().Path("$.countdown").Number().Equal(1)
+//  sub.ExpectComplete()
+func (s *GraphQLSubscription) ExpectComplete() *GraphQLSubscription {
+	msg, ok := s.conn.read(&s.chain, s.id)
+	if ok && msg.Type != "complete" {
+		s.chain.fail("\nexpected GraphQL subscription %s to complete, but got:\n%s",
+			s.id, dumpValue(msg))
+	}
+	return s
+}
+
+// Unsubscribe asks server to stop the subscription.
+//
+// Example:
+//  sub := conn.Subscribe(`subscription { messages { text } }`, nil)
+//  sub.Expect()
+//  sub.Unsubscribe()
+func (s *GraphQLSubscription) Unsubscribe() *GraphQLSubscription {
+	typ := "complete"
+	if s.conn.protocol == GraphQLWS {
+		typ = "stop"
+	}
+	s.conn.write(&s.chain, graphqlMessage{ID: s.id, Type: typ})
+	return s
+}
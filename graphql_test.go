@@ -0,0 +1,405 @@
+package httpexpect
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type graphqlHandler struct {
+	requests []map[string]interface{}
+	queries  map[string]string
+}
+
+func (h *graphqlHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	h.requests = append(h.requests, req)
+
+	query, _ := req["query"].(string)
+
+	if ext, ok := req["extensions"].(map[string]interface{}); ok {
+		pq := ext["persistedQuery"].(map[string]interface{})
+		hash := pq["sha256Hash"].(string)
+		if query != "" {
+			h.queries[hash] = query
+		} else if query = h.queries[hash]; query == "" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"errors": [{"message": "PersistedQueryNotFound",` +
+				` "extensions": {"code": "PERSISTED_QUERY_NOT_FOUND"}}]}`))
+			return
+		}
+	}
+
+	var result interface{}
+
+	switch query {
+	case "{ user }":
+		vars, _ := req["variables"].(map[string]interface{})
+		result = map[string]interface{}{
+			"data": map[string]interface{}{
+				"user": map[string]interface{}{"id": vars["id"], "name": "john"},
+			},
+		}
+	case "{ missing }":
+		result = map[string]interface{}{
+			"data": nil,
+			"errors": []interface{}{
+				map[string]interface{}{"message": "bad request"},
+				map[string]interface{}{
+					"message":    "not found",
+					"path":       []interface{}{"missing"},
+					"extensions": map[string]interface{}{"code": "NOT_FOUND"},
+				},
+			},
+		}
+	default:
+		result = map[string]interface{}{"foo": "bar"}
+	}
+
+	w.Header().Set("Content-Type", "application/graphql-response+json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func TestGraphQLQuery(t *testing.T) {
+	handler := &graphqlHandler{}
+
+	e := WithConfig(newBinderConfig(t, handler))
+
+	resp := e.GraphQL("/graphql", "{ user }", map[string]interface{}{"id": 1},
+		GraphQLOpts{OperationName: "GetUser"})
+
+	resp.chain.assertOK(t)
+
+	resp.NoErrors().chain.assertOK(t)
+	resp.Errors().Empty().chain.assertOK(t)
+	resp.Data().Path("$.user.name").String().Equal("john").chain.assertOK(t)
+	resp.Data().Object().Value("user").Object().ValueEqual("id", 1).
+		chain.assertOK(t)
+
+	resp.ErrorWithCode("NOT_FOUND").chain.assertFailed(t)
+
+	require.Equal(t, 1, len(handler.requests))
+	assert.Equal(t, map[string]interface{}{
+		"query":         "{ user }",
+		"variables":     map[string]interface{}{"id": 1.0},
+		"operationName": "GetUser",
+	}, handler.requests[0])
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	e := WithConfig(newBinderConfig(t, &graphqlHandler{}))
+
+	resp := e.GraphQL("/graphql", "{ missing }", nil)
+	resp.chain.assertOK(t)
+
+	resp.Data().Null().chain.assertOK(t)
+	resp.Errors().Length().Equal(2).chain.assertOK(t)
+
+	resp.ErrorWithCode("NOT_FOUND").
+		ValueEqual("message", "not found").
+		chain.assertOK(t)
+
+	resp.ErrorWithCode("INTERNAL").chain.assertFailed(t)
+
+	resp.NoErrors().chain.assertFailed(t)
+
+	failure := &mockFailureReporter{mockReporter: *newMockReporter(t)}
+
+	NewGraphQLResponse(failure, map[string]interface{}{
+		"errors": []interface{}{
+			map[string]interface{}{
+				"message":    "not found",
+				"extensions": map[string]interface{}{"code": "NOT_FOUND"},
+			},
+		},
+	}).ErrorWithCode("NOT_FOUND").ValueEqual("message", "forbidden")
+
+	require.Equal(t, 1, len(failure.failures))
+	assert.Equal(t, "$.errors[0].message", failure.failures[0].JSONPath)
+}
+
+func TestGraphQLInvalid(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	e := WithConfig(newBinderConfig(t, &graphqlHandler{}))
+
+	e.GraphQL("/graphql", "{ other }", nil).chain.assertFailed(t)
+
+	NewGraphQLResponse(reporter, map[string]interface{}{}).
+		chain.assertFailed(t)
+
+	NewGraphQLResponse(reporter, map[string]interface{}{"errors": "oops"}).
+		Errors().chain.assertFailed(t)
+
+	for _, body := range []string{`[]`, `{`} {
+		httpResp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header(map[string][]string{
+				"Content-Type": {"application/json"},
+			}),
+			Body: ioutil.NopCloser(bytes.NewBufferString(body)),
+		}
+
+		NewResponse(reporter, httpResp).GraphQL().chain.assertFailed(t)
+	}
+
+	httpResp := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Header: http.Header(map[string][]string{
+			"Content-Type": {"text/plain"},
+		}),
+		Body: ioutil.NopCloser(bytes.NewBufferString(`{"data": {}}`)),
+	}
+
+	NewResponse(reporter, httpResp).GraphQL().chain.assertFailed(t)
+}
+
+func TestGraphQLPersistedQuery(t *testing.T) {
+	handler := &graphqlHandler{queries: map[string]string{}}
+
+	reporter := newMockReporter(t)
+
+	config := newBinderConfig(t, handler)
+	config.Reporter = reporter
+
+	matched := 0
+
+	// matchers should not see the hash-only request rejected by server
+	e := WithConfig(config).Matcher(func(resp *Response) {
+		matched++
+		resp.GraphQL().NoErrors()
+	})
+
+	opts := GraphQLOpts{
+		PersistedQuery: true,
+		Extensions:     map[string]interface{}{"foo": "bar"},
+	}
+
+	hash := "2dcb091ed5adc2b586e90da347555be138fde9aa8b895797fdc37b4d75f376b3"
+
+	e.GraphQL("/graphql", "{ user }", nil, opts).
+		Data().Path("$.user.name").String().Equal("john").
+		chain.assertOK(t)
+
+	require.Equal(t, 2, len(handler.requests))
+
+	assert.Equal(t, 1, matched)
+	assert.False(t, reporter.reported)
+
+	assert.Nil(t, handler.requests[0]["query"])
+	assert.Equal(t, "{ user }", handler.requests[1]["query"])
+
+	for _, req := range handler.requests {
+		ext := req["extensions"].(map[string]interface{})
+		assert.Equal(t, "bar", ext["foo"])
+		assert.Equal(t, map[string]interface{}{
+			"version": 1.0, "sha256Hash": hash,
+		}, ext["persistedQuery"])
+	}
+
+	e.GraphQL("/graphql", "{ user }", nil, opts).
+		NoErrors().
+		chain.assertOK(t)
+
+	require.Equal(t, 3, len(handler.requests))
+
+	assert.Equal(t, 2, matched)
+	assert.False(t, reporter.reported)
+	assert.Nil(t, handler.requests[2]["query"])
+}
+
+type graphqlWSHandler struct {
+	mu   sync.Mutex
+	init []interface{}
+}
+
+func (h *graphqlWSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	upgrader := &websocket.Upgrader{
+		Subprotocols: []string{GraphQLTransportWS, GraphQLWS},
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	legacy := conn.Subprotocol() == GraphQLWS
+
+	for {
+		var msg graphqlMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+
+		switch msg.Type {
+		case "connection_init":
+			h.mu.Lock()
+			h.init = append(h.init, msg.Payload)
+			h.mu.Unlock()
+			_ = conn.WriteJSON(graphqlMessage{Type: "connection_ack"})
+			if legacy {
+				_ = conn.WriteJSON(graphqlMessage{Type: "ka"})
+			} else {
+				_ = conn.WriteJSON(graphqlMessage{Type: "ping"})
+			}
+
+		case "subscribe", "start":
+			payload := msg.Payload.(map[string]interface{})
+
+			next := "next"
+			if legacy {
+				next = "data"
+			}
+
+			switch payload["query"] {
+			case "subscription { countdown }":
+				for i := 2; i > 0; i-- {
+					_ = conn.WriteJSON(graphqlMessage{
+						ID:   msg.ID,
+						Type: next,
+						Payload: map[string]interface{}{
+							"data": map[string]interface{}{"countdown": i},
+						},
+					})
+				}
+				_ = conn.WriteJSON(graphqlMessage{ID: msg.ID, Type: "complete"})
+
+			case "subscription { messages }":
+				_ = conn.WriteJSON(graphqlMessage{
+					ID:   msg.ID,
+					Type: next,
+					Payload: map[string]interface{}{
+						"data": map[string]interface{}{
+							"messages": payload["variables"],
+						},
+					},
+				})
+
+			default:
+				var errors interface{} = []interface{}{
+					map[string]interface{}{"message": "unknown subscription"},
+				}
+				if legacy {
+					errors = map[string]interface{}{"message": "unknown subscription"}
+				}
+				_ = conn.WriteJSON(graphqlMessage{
+					ID:      msg.ID,
+					Type:    "error",
+					Payload: errors,
+				})
+			}
+
+		case "pong":
+			_ = conn.WriteJSON(graphqlMessage{Type: "pong"})
+
+		case "complete", "stop":
+			_ = conn.WriteJSON(graphqlMessage{ID: msg.ID, Type: "complete"})
+		}
+	}
+}
+
+func TestGraphQLSubscription(t *testing.T) {
+	for _, protocol := range []string{GraphQLTransportWS, GraphQLWS} {
+		t.Run(protocol, func(t *testing.T) {
+			handler := &graphqlWSHandler{}
+
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			e := WithConfig(newBinderConfig(t, handler)).
+				Builder(func(req *Request) {
+					req.WithURL(server.URL)
+				})
+
+			conn := e.GraphQLWebsocket("/graphql", GraphQLWebsocketOpts{
+				Protocol:    protocol,
+				InitPayload: map[string]interface{}{"token": "secret"},
+			})
+			defer conn.Disconnect()
+
+			conn.chain.assertOK(t)
+			conn.Protocol().Equal(protocol).chain.assertOK(t)
+
+			handler.mu.Lock()
+			assert.Equal(t, []interface{}{
+				map[string]interface{}{"token": "secret"},
+			}, handler.init)
+			handler.mu.Unlock()
+
+			countdown := conn.Subscribe("subscription { countdown }", nil)
+			messages := conn.Subscribe("subscription { messages }",
+				map[string]interface{}{"text": "hello"})
+
+			assert.Equal(t, "1", countdown.ID())
+			assert.Equal(t, "2", messages.ID())
+
+			messages.Expect().NoErrors().
+				Data().Path("$.messages.text").String().Equal("hello").
+				chain.assertOK(t)
+
+			countdown.Expect().
+				Data().Path("$.countdown").Number().Equal(2).
+				chain.assertOK(t)
+			countdown.Expect().
+				Data().Path("$.countdown").Number().Equal(1).
+				chain.assertOK(t)
+			countdown.ExpectComplete().chain.assertOK(t)
+
+			messages.Unsubscribe().ExpectComplete().chain.assertOK(t)
+
+			unknown := conn.Subscribe("subscription { unknown }", nil)
+			unknown.Expect().Errors().Length().Equal(1).chain.assertOK(t)
+
+			completed := conn.Subscribe("subscription { countdown }", nil)
+			completed.Expect().chain.assertOK(t)
+			completed.ExpectComplete().chain.assertFailed(t)
+			completed.Expect().chain.assertFailed(t)
+		})
+	}
+}
+
+func TestGraphQLSubscriptionFailed(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := &websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			panic(err)
+		}
+		defer conn.Close()
+		_, _, _ = conn.ReadMessage()
+		_ = conn.WriteJSON(graphqlMessage{
+			Type:    "connection_error",
+			Payload: map[string]interface{}{"message": "unauthorized"},
+		})
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	e := WithConfig(newBinderConfig(t, handler)).
+		Builder(func(req *Request) {
+			req.WithURL(server.URL)
+		})
+
+	conn := e.GraphQLWebsocket("/graphql")
+	conn.chain.assertFailed(t)
+	conn.Disconnect()
+
+	conn.Subscribe("subscription { countdown }", nil).
+		Expect().
+		chain.assertFailed(t)
+
+	e.GraphQLWebsocket("/graphql", GraphQLWebsocketOpts{Protocol: "unknown"}).
+		chain.assertFailed(t)
+}
@@ -0,0 +1,438 @@
+package httpexpect
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+)
+
+// HARPrinter implements Printer and WebsocketPrinter. It records every
+// request and response, including headers, cookies, bodies, and timings,
+// as well as WebSocket messages, and writes them in HTTP Archive (HAR) 1.2
+// format, which can be opened in browser devtools and other HAR viewers.
+//
+// Request and response bodies are read into memory and replaced with
+// in-memory readers, so that they can still be read by the client and
+// by assertions.
+//
+// Responses are matched to requests using http.Response.Request field,
+// following redirects back to the original request; responses that don't
+// match any recorded request are dropped. Requests that failed without
+// a response are recorded with zero status.
+// WebSocket messages are recorded in "_webSocketMessages" field of the
+// entry of the handshake request, like browsers do.
+//
+// The archive is written to the file when Close is called, typically when
+// the test ends.
+//
+// Example:
+//  har := httpexpect.NewHARPrinter("traffic.har")
+//  defer har.Close()
+//
+//  e := httpexpect.WithConfig(httpexpect.Config{
+//      BaseURL:  "http://example.com",
+//      Reporter: httpexpect.NewAssertReporter(t),
+//      Printers: []httpexpect.Printer{har},
+//  })
+type HARPrinter struct {
+	filename string
+
+	mu        sync.Mutex
+	entries   []*harEntry
+	pending   []*harPending
+	websocket *harEntry
+}
+
+type harPending struct {
+	req   *http.Request
+	entry *harEntry
+}
+
+type harLog struct {
+	Log harLogBody `json:"log"`
+}
+
+type harLogBody struct {
+	Version string      `json:"version"`
+	Creator harCreator  `json:"creator"`
+	Entries []*harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string                `json:"startedDateTime"`
+	Time            float64               `json:"time"`
+	Request         harRequest            `json:"request"`
+	Response        harResponse           `json:"response"`
+	Cache           struct{}              `json:"cache"`
+	Timings         harTimings            `json:"timings"`
+	Messages        []harWebsocketMessage `json:"_webSocketMessages,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harCookie    `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harCookie    `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+type harWebsocketMessage struct {
+	Type   string  `json:"type"`
+	Time   float64 `json:"time"`
+	Opcode int     `json:"opcode"`
+	Data   string  `json:"data"`
+}
+
+// NewHARPrinter returns a new HARPrinter that writes archive to given
+// file when closed.
+func NewHARPrinter(filename string) *HARPrinter {
+	return &HARPrinter{filename: filename}
+}
+
+// Request implements Printer.Request.
+func (p *HARPrinter) Request(req *http.Request) {
+	if req == nil {
+		return
+	}
+
+	entry := &harEntry{
+		StartedDateTime: time.Now().Format(time.RFC3339Nano),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Cookies:     harRequestCookies(req),
+			Headers:     harHeaders(req.Header),
+			QueryString: harQuery(req),
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+	}
+
+	if body, ok := readRequestBody(req); ok {
+		entry.Request.BodySize = len(body)
+		if len(body) != 0 {
+			entry.Request.PostData = &harPostData{
+				MimeType: req.Header.Get("Content-Type"),
+				Text:     string(body),
+			}
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.entries = append(p.entries, entry)
+	p.pending = append(p.pending, &harPending{req: req, entry: entry})
+}
+
+// Response implements Printer.Response.
+func (p *HARPrinter) Response(resp *http.Response, duration time.Duration) {
+	if resp == nil {
+		return
+	}
+
+	response := harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Cookies:     harResponseCookies(resp),
+		Headers:     harHeaders(resp.Header),
+		Content: harContent{
+			MimeType: resp.Header.Get("Content-Type"),
+		},
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+
+	if body, ok := readResponseBody(resp); ok {
+		response.BodySize = len(body)
+
+		if decoded, err := decodeContent(nil, resp.Header, body); err == nil {
+			body = decoded
+		}
+
+		response.Content.Size = len(body)
+		if utf8.Valid(body) {
+			response.Content.Text = string(body)
+		} else {
+			response.Content.Text = base64.StdEncoding.EncodeToString(body)
+			response.Content.Encoding = "base64"
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry := p.popPending(resp.Request)
+	if entry == nil {
+		return
+	}
+
+	ms := float64(duration) / float64(time.Millisecond)
+
+	entry.Response = response
+	entry.Time = ms
+	entry.Timings = harTimings{Wait: ms}
+
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		p.websocket = entry
+	}
+}
+
+// WebsocketWrite implements WebsocketPrinter.WebsocketWrite.
+func (p *HARPrinter) WebsocketWrite(typ int, content []byte, closeCode int) {
+	p.addMessage("send", typ, content, closeCode)
+}
+
+// WebsocketRead implements WebsocketPrinter.WebsocketRead.
+func (p *HARPrinter) WebsocketRead(typ int, content []byte, closeCode int) {
+	p.addMessage("receive", typ, content, closeCode)
+}
+
+// WriteTo writes recorded archive in HAR format to given writer.
+func (p *HARPrinter) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+
+	archive := harLog{
+		Log: harLogBody{
+			Version: "1.2",
+			Creator: harCreator{Name: "httpexpect", Version: "2"},
+			Entries: append([]*harEntry{}, p.entries...),
+		},
+	}
+
+	data, err := json.MarshalIndent(archive, "", "  ")
+
+	p.mu.Unlock()
+
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+// Close writes recorded archive to the file.
+func (p *HARPrinter) Close() error {
+	f, err := os.Create(p.filename)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.WriteTo(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// popPending removes and returns entry waiting for given request response.
+// If the request was redirected by client, the redirect chain is walked
+// back to the original request. If no entry matches, nil is returned.
+func (p *HARPrinter) popPending(req *http.Request) *harEntry {
+	for req != nil {
+		for n, pending := range p.pending {
+			if pending.req == req {
+				p.pending = append(p.pending[:n], p.pending[n+1:]...)
+				return pending.entry
+			}
+		}
+
+		if req.Response == nil {
+			break
+		}
+		req = req.Response.Request
+	}
+
+	return nil
+}
+
+func (p *HARPrinter) addMessage(kind string, typ int, content []byte, closeCode int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.websocket == nil {
+		return
+	}
+
+	data := string(content)
+	if typ == websocket.CloseMessage {
+		data = string(websocket.FormatCloseMessage(closeCode, string(content)))
+	}
+	if typ == websocket.BinaryMessage || !utf8.ValidString(data) {
+		data = base64.StdEncoding.EncodeToString([]byte(data))
+	}
+
+	p.websocket.Messages = append(p.websocket.Messages, harWebsocketMessage{
+		Type:   kind,
+		Time:   float64(time.Now().UnixNano()) / float64(time.Second),
+		Opcode: typ,
+		Data:   data,
+	})
+}
+
+// readRequestBody returns request body without consuming it.
+func readRequestBody(req *http.Request) ([]byte, bool) {
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, false
+		}
+		defer body.Close()
+
+		data, err := ioutil.ReadAll(body)
+		return data, err == nil
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, true
+	}
+
+	data, err := ioutil.ReadAll(req.Body)
+	_ = req.Body.Close()
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	return data, err == nil
+}
+
+// readResponseBody returns response body without consuming it.
+//
+// Event stream body is not read, since it's read later by SSE.
+func readResponseBody(resp *http.Response) ([]byte, bool) {
+	if resp.Body == nil || resp.Body == http.NoBody || isEventStream(resp.Header) {
+		return nil, true
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	return data, err == nil
+}
+
+func harHeaders(header http.Header) []harNameValue {
+	ret := []harNameValue{}
+	for _, name := range sortedKeys(header) {
+		for _, value := range header[name] {
+			ret = append(ret, harNameValue{Name: name, Value: value})
+		}
+	}
+	return ret
+}
+
+func harQuery(req *http.Request) []harNameValue {
+	query := req.URL.Query()
+	ret := []harNameValue{}
+	for _, name := range sortedKeys(query) {
+		for _, value := range query[name] {
+			ret = append(ret, harNameValue{Name: name, Value: value})
+		}
+	}
+	return ret
+}
+
+func harRequestCookies(req *http.Request) []harCookie {
+	ret := []harCookie{}
+	for _, c := range req.Cookies() {
+		ret = append(ret, harCookie{Name: c.Name, Value: c.Value})
+	}
+	return ret
+}
+
+func harResponseCookies(resp *http.Response) []harCookie {
+	ret := []harCookie{}
+	for _, c := range resp.Cookies() {
+		cookie := harCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Domain:   c.Domain,
+			HTTPOnly: c.HttpOnly,
+			Secure:   c.Secure,
+		}
+		if !c.Expires.IsZero() {
+			cookie.Expires = c.Expires.Format(time.RFC3339)
+		}
+		ret = append(ret, cookie)
+	}
+	return ret
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
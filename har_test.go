@@ -0,0 +1,330 @@
+package httpexpect
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readHAR(t *testing.T, printer *HARPrinter) map[string]interface{} {
+	var buf bytes.Buffer
+	_, err := printer.WriteTo(&buf)
+	require.Nil(t, err)
+
+	var archive map[string]interface{}
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &archive))
+
+	return archive
+}
+
+func mustParseURL(s string) *url.URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func TestHARPrinter(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", HttpOnly: true})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(b)
+	})
+
+	printer := NewHARPrinter("")
+
+	config := Config{
+		BaseURL:        "http://example.com",
+		RequestFactory: DefaultRequestFactory{},
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+		Reporter: newMockReporter(t),
+		Printers: []Printer{printer},
+	}
+
+	resp := NewRequest(config, "POST", "/path").
+		WithQuery("q", "1").
+		WithHeader("X-Foo", "bar").
+		WithCookie("token", "xyz").
+		WithJSON(map[string]interface{}{"foo": 123}).
+		Expect()
+
+	resp.Status(http.StatusCreated)
+	resp.JSON().Object().ValueEqual("foo", 123)
+	resp.chain.assertOK(t)
+
+	chunked := NewRequest(config, "PUT", "/chunked").
+		WithChunked(ioutil.NopCloser(strings.NewReader(`"hello"`))).
+		Expect()
+
+	chunked.Body().Equal(`"hello"`)
+	chunked.chain.assertOK(t)
+
+	archive := readHAR(t, printer)
+
+	log := archive["log"].(map[string]interface{})
+	assert.Equal(t, "1.2", log["version"])
+
+	entries := log["entries"].([]interface{})
+	require.Equal(t, 2, len(entries))
+
+	entry := entries[0].(map[string]interface{})
+
+	_, err := time.Parse(time.RFC3339Nano, entry["startedDateTime"].(string))
+	assert.Nil(t, err)
+	assert.True(t, entry["time"].(float64) >= 0)
+
+	request := entry["request"].(map[string]interface{})
+	assert.Equal(t, "POST", request["method"])
+	assert.Equal(t, "http://example.com/path?q=1", request["url"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "q", "value": "1"},
+	}, request["queryString"])
+	assert.Contains(t, request["headers"], map[string]interface{}{
+		"name": "X-Foo", "value": "bar",
+	})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "token", "value": "xyz"},
+	}, request["cookies"])
+	assert.Equal(t, map[string]interface{}{
+		"mimeType": "application/json; charset=utf-8",
+		"text":     `{"foo":123}`,
+	}, request["postData"])
+
+	response := entry["response"].(map[string]interface{})
+	assert.Equal(t, 201.0, response["status"])
+	assert.Equal(t, "Created", response["statusText"])
+	assert.Equal(t, map[string]interface{}{
+		"size":     11.0,
+		"mimeType": "application/json",
+		"text":     `{"foo":123}`,
+	}, response["content"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "session", "value": "abc", "httpOnly": true},
+	}, response["cookies"])
+
+	entry = entries[1].(map[string]interface{})
+
+	request = entry["request"].(map[string]interface{})
+	assert.Equal(t, "PUT", request["method"])
+	assert.Equal(t, `"hello"`,
+		request["postData"].(map[string]interface{})["text"])
+}
+
+func TestHARPrinterFailure(t *testing.T) {
+	printer := NewHARPrinter("")
+
+	config := Config{
+		RequestFactory: DefaultRequestFactory{},
+		Client: &mockClient{
+			err: assert.AnError,
+		},
+		Reporter: newMockReporter(t),
+		Printers: []Printer{printer},
+	}
+
+	resp := NewRequest(config, "GET", "http://example.com").Expect()
+	resp.chain.assertFailed(t)
+
+	entries := readHAR(t, printer)["log"].(map[string]interface{})["entries"]
+	require.Equal(t, 1, len(entries.([]interface{})))
+
+	entry := entries.([]interface{})[0].(map[string]interface{})
+	response := entry["response"].(map[string]interface{})
+	assert.Equal(t, 0.0, response["status"])
+}
+
+func TestHARPrinterBinary(t *testing.T) {
+	printer := NewHARPrinter("")
+
+	req := &http.Request{
+		Method: "GET",
+		URL:    mustParseURL("http://example.com"),
+		Header: http.Header{},
+	}
+
+	printer.Request(req)
+	printer.Response(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte{0xff, 0xfe})),
+		Request:    req,
+	}, time.Second)
+
+	entries := readHAR(t, printer)["log"].(map[string]interface{})["entries"]
+	entry := entries.([]interface{})[0].(map[string]interface{})
+
+	assert.Equal(t, 1000.0, entry["time"])
+	assert.Equal(t, map[string]interface{}{
+		"send": 0.0, "wait": 1000.0, "receive": 0.0,
+	}, entry["timings"])
+
+	content := entry["response"].(map[string]interface{})["content"]
+	assert.Equal(t, map[string]interface{}{
+		"size":     2.0,
+		"mimeType": "",
+		"text":     "//4=",
+		"encoding": "base64",
+	}, content)
+}
+
+func TestHARPrinterWebsocket(t *testing.T) {
+	printer := NewHARPrinter("")
+
+	printer.WebsocketWrite(websocket.TextMessage, []byte("ignored"), 0)
+
+	req := &http.Request{
+		Method: "GET",
+		URL:    mustParseURL("ws://example.com"),
+		Header: http.Header{},
+	}
+
+	printer.Request(req)
+	printer.Response(&http.Response{
+		StatusCode: http.StatusSwitchingProtocols,
+		Header:     http.Header{},
+		Request:    req,
+	}, time.Millisecond)
+
+	printer.WebsocketWrite(websocket.TextMessage, []byte("hello"), 0)
+	printer.WebsocketRead(websocket.BinaryMessage, []byte("world"), 0)
+
+	entries := readHAR(t, printer)["log"].(map[string]interface{})["entries"]
+	entry := entries.([]interface{})[0].(map[string]interface{})
+
+	messages := entry["_webSocketMessages"].([]interface{})
+	require.Equal(t, 2, len(messages))
+
+	msg1 := messages[0].(map[string]interface{})
+	assert.Equal(t, "send", msg1["type"])
+	assert.Equal(t, 1.0, msg1["opcode"])
+	assert.Equal(t, "hello", msg1["data"])
+
+	msg2 := messages[1].(map[string]interface{})
+	assert.Equal(t, "receive", msg2["type"])
+	assert.Equal(t, 2.0, msg2["opcode"])
+	assert.Equal(t, "d29ybGQ=", msg2["data"])
+}
+
+func TestHARPrinterMatching(t *testing.T) {
+	printer := NewHARPrinter("")
+
+	newRequest := func(url string) *http.Request {
+		return &http.Request{
+			Method: "GET",
+			URL:    mustParseURL(url),
+			Header: http.Header{},
+		}
+	}
+
+	newResponse := func(status int, req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{},
+			Request:    req,
+		}
+	}
+
+	req1 := newRequest("http://example.com/1")
+	req2 := newRequest("http://example.com/2")
+	req3 := newRequest("http://example.com/3")
+
+	printer.Request(req1)
+	printer.Request(req2)
+	printer.Request(req3)
+
+	// response for the first request arrives after the second one
+	printer.Response(newResponse(http.StatusAccepted, req2), time.Second)
+
+	// client followed redirect and replaced the request
+	redirect := newRequest("http://example.com/redirected")
+	redirect.Response = newResponse(http.StatusFound, req1)
+	printer.Response(newResponse(http.StatusOK, redirect), time.Second)
+
+	// response for unknown request is dropped
+	printer.Response(newResponse(http.StatusTeapot, newRequest("http://foo")), 0)
+	printer.Response(newResponse(http.StatusTeapot, nil), 0)
+
+	entries := readHAR(t, printer)["log"].(map[string]interface{})["entries"]
+	require.Equal(t, 3, len(entries.([]interface{})))
+
+	statuses := []interface{}{}
+	for _, entry := range entries.([]interface{}) {
+		response := entry.(map[string]interface{})["response"]
+		statuses = append(statuses, response.(map[string]interface{})["status"])
+	}
+
+	assert.Equal(t, []interface{}{200.0, 202.0, 0.0}, statuses)
+}
+
+func TestHARPrinterCompressed(t *testing.T) {
+	printer := NewHARPrinter("")
+
+	req := &http.Request{
+		Method: "GET",
+		URL:    mustParseURL("http://example.com"),
+		Header: http.Header{},
+	}
+
+	content := strings.Repeat("hello", 100)
+	compressed := mustGzip([]byte(content))
+
+	printer.Request(req)
+	printer.Response(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Encoding": {"gzip"}},
+		Body:       ioutil.NopCloser(bytes.NewReader(compressed)),
+		Request:    req,
+	}, time.Second)
+
+	entries := readHAR(t, printer)["log"].(map[string]interface{})["entries"]
+	entry := entries.([]interface{})[0].(map[string]interface{})
+	response := entry["response"].(map[string]interface{})
+
+	assert.Equal(t, float64(len(compressed)), response["bodySize"])
+	assert.Equal(t, map[string]interface{}{
+		"size":     float64(len(content)),
+		"mimeType": "",
+		"text":     content,
+	}, response["content"])
+}
+
+func TestHARPrinterClose(t *testing.T) {
+	f, err := ioutil.TempFile("", "httpexpect")
+	require.Nil(t, err)
+	require.Nil(t, f.Close())
+	defer os.Remove(f.Name())
+
+	printer := NewHARPrinter(f.Name())
+
+	printer.Request(&http.Request{
+		Method: "GET",
+		URL:    mustParseURL("http://example.com"),
+		Header: http.Header{},
+	})
+
+	require.Nil(t, printer.Close())
+
+	data, err := ioutil.ReadFile(f.Name())
+	require.Nil(t, err)
+
+	var archive map[string]interface{}
+	require.Nil(t, json.Unmarshal(data, &archive))
+
+	entries := archive["log"].(map[string]interface{})["entries"]
+	assert.Equal(t, 1, len(entries.([]interface{})))
+}
@@ -7,7 +7,6 @@ import (
 	"regexp"
 
 	"github.com/xeipuuv/gojsonschema"
-	"github.com/yalp/jsonpath"
 	"github.com/yudai/gojsondiff"
 	"github.com/yudai/gojsondiff/formatter"
 )
@@ -25,16 +24,77 @@ func toString(str interface{}) (s string, ok bool) {
 
 func getPath(chain *chain, value interface{}, path string) *Value {
 	if chain.failed() {
-		return &Value{*chain, nil}
+		return &Value{chain.enter("Path(%q)", path).enterQuery(path), nil}
 	}
 
-	result, err := jsonpath.Read(value, path)
+	result, err := evalJSONPath(value, path)
 	if err != nil {
-		chain.fail(err.Error())
-		return &Value{*chain, nil}
+		chain.fail("\n%s", err.Error())
+		return &Value{chain.enter("Path(%q)", path).enterQuery(path), nil}
+	}
+
+	return &Value{chain.enter("Path(%q)", path).enterQuery(path), result}
+}
+
+func getPathAll(chain *chain, value interface{}, path string) *Array {
+	if chain.failed() {
+		return &Array{chain.enter("PathAll(%q)", path).enterQuery(path), nil}
+	}
+
+	query, err := parseJSONPath(path)
+	if err != nil {
+		chain.fail("\n%s", err.Error())
+		return &Array{chain.enter("PathAll(%q)", path).enterQuery(path), nil}
 	}
 
-	return &Value{*chain, result}
+	return &Array{chain.enter("PathAll(%q)", path).enterQuery(path),
+		query.eval(value, value)}
+}
+
+func getPointer(chain *chain, value interface{}, pointer string) *Value {
+	if chain.failed() {
+		return &Value{chain.enter("Pointer(%q)", pointer), nil}
+	}
+
+	tokens, err := parseJSONPointer(pointer)
+	if err != nil {
+		chain.fail("\n%s", err.Error())
+		return &Value{chain.enter("Pointer(%q)", pointer), nil}
+	}
+
+	result, keys, err := resolveJSONPointer(value, pointer, tokens)
+	if err != nil {
+		chain.fail("\n%s", err.Error())
+		return &Value{chain.enter("Pointer(%q)", pointer), nil}
+	}
+
+	valueChain := chain.enter("Pointer(%q)", pointer)
+	for _, key := range keys {
+		switch k := key.(type) {
+		case string:
+			valueChain = valueChain.enterKey(k)
+		case int:
+			valueChain = valueChain.enterIndex(k)
+		}
+	}
+
+	return &Value{valueChain, result}
+}
+
+// evalJSONPath evaluates JSONPath query. If query is singular, i.e. it
+// may select at most one node, selected node is returned, or error if
+// there is no such node. Otherwise, list of selected nodes is returned.
+func evalJSONPath(value interface{}, path string) (interface{}, error) {
+	query, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.isSingular() {
+		return query.resolve(value)
+	}
+
+	return query.eval(value, value), nil
 }
 
 func checkSchema(chain *chain, value, schema interface{}) {
@@ -149,6 +209,8 @@ func dumpValue(value interface{}) string {
 	return " " + string(b)
 }
 
+const diffUnavailable = " (unavailable)"
+
 func diffValues(expected, actual interface{}) string {
 	differ := gojsondiff.New()
 
@@ -158,16 +220,16 @@ func diffValues(expected, actual interface{}) string {
 		if va, ok := actual.(map[string]interface{}); ok {
 			diff = differ.CompareObjects(ve, va)
 		} else {
-			return " (unavailable)"
+			return diffUnavailable
 		}
 	} else if ve, ok := expected.([]interface{}); ok {
 		if va, ok := actual.([]interface{}); ok {
 			diff = differ.CompareArrays(ve, va)
 		} else {
-			return " (unavailable)"
+			return diffUnavailable
 		}
 	} else {
-		return " (unavailable)"
+		return diffUnavailable
 	}
 
 	config := formatter.AsciiFormatterConfig{
@@ -177,7 +239,7 @@ func diffValues(expected, actual interface{}) string {
 
 	str, err := f.Format(diff)
 	if err != nil {
-		return " (unavailable)"
+		return diffUnavailable
 	}
 
 	return "--- expected\n+++ actual\n" + str
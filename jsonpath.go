@@ -0,0 +1,1306 @@
+package httpexpect
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// jsonPath is a parsed JSONPath query, as defined in RFC 9535.
+//
+// As an extension, for compatibility with previously used implementation,
+// member names in brackets may be unquoted, e.g. $[name].
+type jsonPath struct {
+	query    string
+	relative bool
+	segments []jsonPathSegment
+}
+
+type jsonPathSegment struct {
+	text       string
+	descendant bool
+	selectors  []jsonPathSelector
+}
+
+// jsonPathSelector selects children of a node. Selected children are
+// identified by member names (string) or array indexes (int).
+type jsonPathSelector interface {
+	selectKeys(root, node interface{}, out []interface{}) []interface{}
+}
+
+// jsonPathLocation is a node selected by query, together with its parent
+// and member name or array index in the parent.
+type jsonPathLocation struct {
+	parent interface{}
+	key    interface{}
+	value  interface{}
+}
+
+type jsonPathName struct {
+	name string
+}
+
+type jsonPathWildcard struct{}
+
+type jsonPathIndex struct {
+	index int64
+}
+
+type jsonPathSlice struct {
+	start, end, step *int64
+}
+
+type jsonPathFilter struct {
+	expr jsonPathLogical
+}
+
+// jsonPathLogical is a logical expression of filter selector.
+type jsonPathLogical interface {
+	test(root, current interface{}) bool
+}
+
+type jsonPathOr []jsonPathLogical
+
+type jsonPathAnd []jsonPathLogical
+
+type jsonPathNot struct {
+	expr jsonPathLogical
+}
+
+type jsonPathComparison struct {
+	op          string
+	left, right interface{}
+}
+
+// jsonPathTest is a test expression: existence test of a query, or
+// a function returning LogicalType or NodesType.
+type jsonPathTest struct {
+	operand interface{}
+}
+
+type jsonPathLiteral struct {
+	value interface{}
+}
+
+type jsonPathCall struct {
+	name string
+	fn   *jsonPathFunction
+	args []interface{}
+}
+
+// jsonPathBare is a parsed operand that is not a part of comparison yet.
+// Depending on context, it becomes a test expression or function argument.
+type jsonPathBare struct {
+	operand interface{}
+}
+
+type jsonPathType int
+
+const (
+	jsonPathValueType jsonPathType = iota
+	jsonPathLogicalType
+	jsonPathNodesType
+)
+
+// jsonPathResult is a value of function argument or result.
+type jsonPathResult struct {
+	value   interface{}
+	nothing bool
+	logical bool
+	nodes   []interface{}
+}
+
+type jsonPathFunction struct {
+	params []jsonPathType
+	result jsonPathType
+	call   func(args []jsonPathResult) jsonPathResult
+}
+
+var jsonPathFunctions = map[string]*jsonPathFunction{
+	"length": {
+		params: []jsonPathType{jsonPathValueType},
+		result: jsonPathValueType,
+		call: func(args []jsonPathResult) jsonPathResult {
+			switch v := args[0].value.(type) {
+			case string:
+				return jsonPathResult{value: float64(utf8.RuneCountInString(v))}
+			case []interface{}:
+				return jsonPathResult{value: float64(len(v))}
+			case map[string]interface{}:
+				return jsonPathResult{value: float64(len(v))}
+			}
+			return jsonPathResult{nothing: true}
+		},
+	},
+	"count": {
+		params: []jsonPathType{jsonPathNodesType},
+		result: jsonPathValueType,
+		call: func(args []jsonPathResult) jsonPathResult {
+			return jsonPathResult{value: float64(len(args[0].nodes))}
+		},
+	},
+	"match": {
+		params: []jsonPathType{jsonPathValueType, jsonPathValueType},
+		result: jsonPathLogicalType,
+		call: func(args []jsonPathResult) jsonPathResult {
+			return jsonPathResult{logical: iregexpMatch(args[0], args[1], true)}
+		},
+	},
+	"search": {
+		params: []jsonPathType{jsonPathValueType, jsonPathValueType},
+		result: jsonPathLogicalType,
+		call: func(args []jsonPathResult) jsonPathResult {
+			return jsonPathResult{logical: iregexpMatch(args[0], args[1], false)}
+		},
+	},
+	"value": {
+		params: []jsonPathType{jsonPathNodesType},
+		result: jsonPathValueType,
+		call: func(args []jsonPathResult) jsonPathResult {
+			if len(args[0].nodes) != 1 {
+				return jsonPathResult{nothing: true}
+			}
+			return jsonPathResult{value: args[0].nodes[0]}
+		},
+	},
+}
+
+// iregexpMatch implements match() and search() functions. Pattern is an
+// I-Regexp, as defined in RFC 9485, which is translated to Go regexp.
+func iregexpMatch(value, pattern jsonPathResult, full bool) bool {
+	s, ok := value.value.(string)
+	if !ok || value.nothing {
+		return false
+	}
+	p, ok := pattern.value.(string)
+	if !ok || pattern.nothing {
+		return false
+	}
+
+	var b strings.Builder
+	inClass := false
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; {
+		case c == '\\' && i+1 < len(p):
+			b.WriteByte(c)
+			i++
+			b.WriteByte(p[i])
+		case c == '[':
+			inClass = true
+			b.WriteByte(c)
+		case c == ']':
+			inClass = false
+			b.WriteByte(c)
+		case c == '.' && !inClass:
+			// in I-Regexp, dot doesn't match CR and LF
+			b.WriteString(`[^\n\r]`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	expr := b.String()
+	if full {
+		expr = `^(?:` + expr + `)$`
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(s)
+}
+
+// parseJSONPath parses JSONPath query.
+func parseJSONPath(query string) (*jsonPath, error) {
+	p := &jsonPathParser{query: query}
+
+	if !p.consume("$") {
+		return nil, p.errorf("expected '$'")
+	}
+
+	q, err := p.parseSegments(false)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos != len(p.query) {
+		return nil, p.errorf("unexpected character")
+	}
+
+	return q, nil
+}
+
+// isSingular reports whether query always produces at most one node.
+func (q *jsonPath) isSingular() bool {
+	for _, seg := range q.segments {
+		if seg.descendant || len(seg.selectors) != 1 {
+			return false
+		}
+		switch seg.selectors[0].(type) {
+		case jsonPathName, jsonPathIndex:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// eval returns all nodes selected by query.
+func (q *jsonPath) eval(root, current interface{}) []interface{} {
+	if len(q.segments) == 0 {
+		if q.relative {
+			return []interface{}{current}
+		}
+		return []interface{}{root}
+	}
+
+	locations := q.locate(root, current)
+
+	nodes := make([]interface{}, 0, len(locations))
+	for _, loc := range locations {
+		nodes = append(nodes, loc.value)
+	}
+	return nodes
+}
+
+// locate returns locations of all nodes selected by query. If query has
+// no segments, it selects start node, which has no location, so nothing
+// is returned.
+func (q *jsonPath) locate(root, current interface{}) []jsonPathLocation {
+	start := root
+	if q.relative {
+		start = current
+	}
+
+	var locations []jsonPathLocation
+
+	selectChildren := func(seg jsonPathSegment, node interface{}) {
+		var keys []interface{}
+		for _, sel := range seg.selectors {
+			keys = sel.selectKeys(root, node, keys)
+		}
+		for _, key := range keys {
+			locations = append(locations, jsonPathLocation{
+				parent: node,
+				key:    key,
+				value:  jsonChild(node, key),
+			})
+		}
+	}
+
+	nodes := []interface{}{start}
+	for _, seg := range q.segments {
+		locations = nil
+		for _, node := range nodes {
+			if seg.descendant {
+				walkJSON(node, func(n interface{}) {
+					selectChildren(seg, n)
+				})
+			} else {
+				selectChildren(seg, node)
+			}
+		}
+		nodes = nodes[:0]
+		for _, loc := range locations {
+			nodes = append(nodes, loc.value)
+		}
+	}
+
+	return locations
+}
+
+// resolve returns the node selected by singular query, or error describing
+// which segment failed to resolve.
+func (q *jsonPath) resolve(root interface{}) (interface{}, error) {
+	node := root
+
+	for _, seg := range q.segments {
+		var err error
+		switch sel := seg.selectors[0].(type) {
+		case jsonPathName:
+			node, err = resolveJSONMember(node, sel.name)
+		case jsonPathIndex:
+			node, err = resolveJSONIndex(node, sel.index)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("JSONPath %q: segment %q failed to resolve: %s",
+				q.query, seg.text, err.Error())
+		}
+	}
+
+	return node, nil
+}
+
+func resolveJSONMember(node interface{}, name string) (interface{}, error) {
+	object, ok := node.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected object, but got %s", jsonTypeName(node))
+	}
+	value, ok := object[name]
+	if !ok {
+		return nil, fmt.Errorf("member %q not found in object", name)
+	}
+	return value, nil
+}
+
+func resolveJSONIndex(node interface{}, index int64) (interface{}, error) {
+	array, ok := node.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected array, but got %s", jsonTypeName(node))
+	}
+	n := index
+	if n < 0 {
+		n += int64(len(array))
+	}
+	if n < 0 || n >= int64(len(array)) {
+		return nil, fmt.Errorf("index %d out of range for array of length %d",
+			index, len(array))
+	}
+	return array[n], nil
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// walkJSON invokes fn for node and all its descendants, in document order.
+// Object members are visited in order of their names.
+func walkJSON(node interface{}, fn func(interface{})) {
+	fn(node)
+	switch v := node.(type) {
+	case []interface{}:
+		for _, elem := range v {
+			walkJSON(elem, fn)
+		}
+	case map[string]interface{}:
+		for _, key := range sortedMembers(v) {
+			walkJSON(v[key], fn)
+		}
+	}
+}
+
+// jsonChildKeys returns member names of object or indexes of array.
+func jsonChildKeys(node interface{}) []interface{} {
+	switch v := node.(type) {
+	case []interface{}:
+		keys := make([]interface{}, 0, len(v))
+		for n := range v {
+			keys = append(keys, n)
+		}
+		return keys
+	case map[string]interface{}:
+		keys := make([]interface{}, 0, len(v))
+		for _, key := range sortedMembers(v) {
+			keys = append(keys, key)
+		}
+		return keys
+	}
+	return nil
+}
+
+// jsonChild returns child of node by member name or array index.
+func jsonChild(node, key interface{}) interface{} {
+	switch k := key.(type) {
+	case string:
+		return node.(map[string]interface{})[k]
+	case int:
+		return node.([]interface{})[k]
+	}
+	return nil
+}
+
+func (s jsonPathName) selectKeys(
+	_, node interface{}, out []interface{},
+) []interface{} {
+	if object, ok := node.(map[string]interface{}); ok {
+		if _, ok := object[s.name]; ok {
+			out = append(out, s.name)
+		}
+	}
+	return out
+}
+
+func (s jsonPathWildcard) selectKeys(
+	_, node interface{}, out []interface{},
+) []interface{} {
+	return append(out, jsonChildKeys(node)...)
+}
+
+func (s jsonPathIndex) selectKeys(
+	_, node interface{}, out []interface{},
+) []interface{} {
+	array, ok := node.([]interface{})
+	if !ok {
+		return out
+	}
+	n := s.index
+	if n < 0 {
+		n += int64(len(array))
+	}
+	if n >= 0 && n < int64(len(array)) {
+		out = append(out, int(n))
+	}
+	return out
+}
+
+func (s jsonPathSlice) selectKeys(
+	_, node interface{}, out []interface{},
+) []interface{} {
+	array, ok := node.([]interface{})
+	if !ok {
+		return out
+	}
+
+	n := int64(len(array))
+
+	step := int64(1)
+	if s.step != nil {
+		step = *s.step
+	}
+	if step == 0 {
+		return out
+	}
+
+	normalize := func(i int64) int64 {
+		if i < 0 {
+			return n + i
+		}
+		return i
+	}
+	clamp := func(i, lo, hi int64) int64 {
+		if i < lo {
+			return lo
+		}
+		if i > hi {
+			return hi
+		}
+		return i
+	}
+
+	if step > 0 {
+		start, end := int64(0), n
+		if s.start != nil {
+			start = *s.start
+		}
+		if s.end != nil {
+			end = *s.end
+		}
+		lower := clamp(normalize(start), 0, n)
+		upper := clamp(normalize(end), 0, n)
+		for i := lower; i < upper; i += step {
+			out = append(out, int(i))
+		}
+	} else {
+		start, end := n-1, -n-1
+		if s.start != nil {
+			start = *s.start
+		}
+		if s.end != nil {
+			end = *s.end
+		}
+		upper := clamp(normalize(start), -1, n-1)
+		lower := clamp(normalize(end), -1, n-1)
+		for i := upper; lower < i; i += step {
+			out = append(out, int(i))
+		}
+	}
+
+	return out
+}
+
+func (s jsonPathFilter) selectKeys(
+	root, node interface{}, out []interface{},
+) []interface{} {
+	for _, key := range jsonChildKeys(node) {
+		if s.expr.test(root, jsonChild(node, key)) {
+			out = append(out, key)
+		}
+	}
+	return out
+}
+
+func (e jsonPathOr) test(root, current interface{}) bool {
+	for _, expr := range e {
+		if expr.test(root, current) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e jsonPathAnd) test(root, current interface{}) bool {
+	for _, expr := range e {
+		if !expr.test(root, current) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *jsonPathNot) test(root, current interface{}) bool {
+	return !e.expr.test(root, current)
+}
+
+func (e *jsonPathTest) test(root, current interface{}) bool {
+	switch operand := e.operand.(type) {
+	case *jsonPath:
+		return len(operand.eval(root, current)) != 0
+	case *jsonPathCall:
+		result := operand.call(root, current)
+		if operand.fn.result == jsonPathNodesType {
+			return len(result.nodes) != 0
+		}
+		return result.logical
+	}
+	return false
+}
+
+func (e *jsonPathComparison) test(root, current interface{}) bool {
+	left, lok := jsonPathComparable(e.left, root, current)
+	right, rok := jsonPathComparable(e.right, root, current)
+
+	switch e.op {
+	case "==":
+		return jsonPathEqual(left, lok, right, rok)
+	case "!=":
+		return !jsonPathEqual(left, lok, right, rok)
+	case "<":
+		return lok && rok && jsonPathLess(left, right)
+	case "<=":
+		return lok && rok &&
+			(jsonPathLess(left, right) || jsonPathEqual(left, lok, right, rok))
+	case ">":
+		return lok && rok && jsonPathLess(right, left)
+	case ">=":
+		return lok && rok &&
+			(jsonPathLess(right, left) || jsonPathEqual(left, lok, right, rok))
+	}
+	return false
+}
+
+// jsonPathComparable evaluates comparable, returning false if its value
+// is Nothing.
+func jsonPathComparable(operand, root, current interface{}) (interface{}, bool) {
+	switch op := operand.(type) {
+	case *jsonPathLiteral:
+		return op.value, true
+	case *jsonPath:
+		nodes := op.eval(root, current)
+		if len(nodes) != 1 {
+			return nil, false
+		}
+		return nodes[0], true
+	case *jsonPathCall:
+		result := op.call(root, current)
+		return result.value, !result.nothing
+	}
+	return nil, false
+}
+
+func jsonPathEqual(left interface{}, lok bool, right interface{}, rok bool) bool {
+	if !lok || !rok {
+		return !lok && !rok
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+func jsonPathLess(left, right interface{}) bool {
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		return ok && l < r
+	case string:
+		r, ok := right.(string)
+		return ok && l < r
+	}
+	return false
+}
+
+func (c *jsonPathCall) call(root, current interface{}) jsonPathResult {
+	args := make([]jsonPathResult, len(c.args))
+
+	for n, arg := range c.args {
+		switch c.fn.params[n] {
+		case jsonPathValueType:
+			value, ok := jsonPathComparable(arg, root, current)
+			args[n] = jsonPathResult{value: value, nothing: !ok}
+		case jsonPathLogicalType:
+			args[n] = jsonPathResult{logical: arg.(jsonPathLogical).test(root, current)}
+		case jsonPathNodesType:
+			switch a := arg.(type) {
+			case *jsonPath:
+				args[n] = jsonPathResult{nodes: a.eval(root, current)}
+			case *jsonPathCall:
+				args[n] = a.call(root, current)
+			}
+		}
+	}
+
+	return c.fn.call(args)
+}
+
+type jsonPathParser struct {
+	query string
+	pos   int
+}
+
+func (p *jsonPathParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid JSONPath %q: %s at position %d",
+		p.query, fmt.Sprintf(format, args...), p.pos)
+}
+
+func (p *jsonPathParser) peek() byte {
+	if p.pos < len(p.query) {
+		return p.query[p.pos]
+	}
+	return 0
+}
+
+func (p *jsonPathParser) consume(s string) bool {
+	if strings.HasPrefix(p.query[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *jsonPathParser) skipSpace() {
+	for p.pos < len(p.query) && strings.IndexByte(" \t\n\r", p.query[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// parseSegments parses segments following root or current node identifier.
+func (p *jsonPathParser) parseSegments(relative bool) (*jsonPath, error) {
+	start := p.pos
+	if start > 0 {
+		start--
+	}
+
+	q := &jsonPath{relative: relative}
+
+	for {
+		save := p.pos
+		p.skipSpace()
+
+		if c := p.peek(); c != '.' && c != '[' {
+			p.pos = save
+			break
+		}
+
+		segStart := p.pos
+		seg, err := p.parseSegment()
+		if err != nil {
+			return nil, err
+		}
+		seg.text = p.query[segStart:p.pos]
+
+		q.segments = append(q.segments, seg)
+	}
+
+	q.query = p.query[start:p.pos]
+	return q, nil
+}
+
+func (p *jsonPathParser) parseSegment() (jsonPathSegment, error) {
+	var seg jsonPathSegment
+
+	if p.consume("..") {
+		seg.descendant = true
+		switch p.peek() {
+		case '[':
+			return p.parseBracketed(seg)
+		case '*':
+			p.pos++
+			seg.selectors = []jsonPathSelector{jsonPathWildcard{}}
+			return seg, nil
+		}
+		name, ok := p.parseMemberName()
+		if !ok {
+			return seg, p.errorf("expected member name, '*', or '[' after '..'")
+		}
+		seg.selectors = []jsonPathSelector{jsonPathName{name}}
+		return seg, nil
+	}
+
+	if p.consume(".") {
+		if p.consume("*") {
+			seg.selectors = []jsonPathSelector{jsonPathWildcard{}}
+			return seg, nil
+		}
+		name, ok := p.parseMemberName()
+		if !ok {
+			return seg, p.errorf("expected member name or '*' after '.'")
+		}
+		seg.selectors = []jsonPathSelector{jsonPathName{name}}
+		return seg, nil
+	}
+
+	return p.parseBracketed(seg)
+}
+
+// parseMemberName parses member-name-shorthand.
+func (p *jsonPathParser) parseMemberName() (string, bool) {
+	start := p.pos
+	for p.pos < len(p.query) {
+		r, size := utf8.DecodeRuneInString(p.query[p.pos:])
+		if !(r == '_' || r >= 0x80 ||
+			(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
+			(p.pos > start && r >= '0' && r <= '9')) {
+			break
+		}
+		p.pos += size
+	}
+	return p.query[start:p.pos], p.pos > start
+}
+
+func (p *jsonPathParser) parseBracketed(seg jsonPathSegment) (jsonPathSegment, error) {
+	if !p.consume("[") {
+		return seg, p.errorf("expected '['")
+	}
+
+	for {
+		p.skipSpace()
+
+		sel, err := p.parseSelector()
+		if err != nil {
+			return seg, err
+		}
+		seg.selectors = append(seg.selectors, sel)
+
+		p.skipSpace()
+
+		if p.consume("]") {
+			return seg, nil
+		}
+		if !p.consume(",") {
+			return seg, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *jsonPathParser) parseSelector() (jsonPathSelector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		name, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return jsonPathName{name}, nil
+
+	case c == '*':
+		p.pos++
+		return jsonPathWildcard{}, nil
+
+	case c == '?':
+		p.pos++
+		p.skipSpace()
+		expr, err := p.parseLogical()
+		if err != nil {
+			return nil, err
+		}
+		return jsonPathFilter{expr}, nil
+
+	case c == '-' || c == ':' || (c >= '0' && c <= '9'):
+		return p.parseIndexOrSlice()
+	}
+
+	// non-standard unquoted member name, e.g. $[name]
+	if name, ok := p.parseMemberName(); ok {
+		return jsonPathName{name}, nil
+	}
+
+	return nil, p.errorf("expected selector")
+}
+
+func (p *jsonPathParser) parseIndexOrSlice() (jsonPathSelector, error) {
+	var bounds [3]*int64
+
+	for n := 0; n < 3; n++ {
+		p.skipSpace()
+		if c := p.peek(); c == '-' || (c >= '0' && c <= '9') {
+			i, err := p.parseInt()
+			if err != nil {
+				return nil, err
+			}
+			bounds[n] = &i
+		}
+		p.skipSpace()
+
+		if n == 0 && p.peek() != ':' {
+			if bounds[0] == nil {
+				return nil, p.errorf("expected index or slice")
+			}
+			return jsonPathIndex{*bounds[0]}, nil
+		}
+		if n == 2 || !p.consume(":") {
+			break
+		}
+	}
+
+	return jsonPathSlice{bounds[0], bounds[1], bounds[2]}, nil
+}
+
+// parseInt parses integer in I-JSON range, without leading zeros.
+func (p *jsonPathParser) parseInt() (int64, error) {
+	start := p.pos
+	p.consume("-")
+	digits := p.pos
+	for p.pos < len(p.query) && p.query[p.pos] >= '0' && p.query[p.pos] <= '9' {
+		p.pos++
+	}
+
+	s := p.query[start:p.pos]
+	if p.pos == digits ||
+		(p.query[digits] == '0' && (p.pos-digits > 1 || digits > start)) {
+		p.pos = start
+		return 0, p.errorf("invalid integer")
+	}
+
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || i > 1<<53-1 || i < -(1<<53-1) {
+		p.pos = start
+		return 0, p.errorf("integer out of range")
+	}
+	return i, nil
+}
+
+// parseString parses single- or double-quoted string literal.
+func (p *jsonPathParser) parseString() (string, error) {
+	quote := p.query[p.pos]
+	p.pos++
+
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.query) {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.query[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c < 0x20:
+			return "", p.errorf("unescaped control character in string")
+		case c != '\\':
+			b.WriteByte(c)
+			p.pos++
+			continue
+		}
+
+		p.pos++
+		if p.pos >= len(p.query) {
+			return "", p.errorf("unterminated string")
+		}
+		esc := p.query[p.pos]
+		p.pos++
+
+		switch esc {
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '/', '\\':
+			b.WriteByte(esc)
+		case '\'', '"':
+			if esc != quote {
+				return "", p.errorf("invalid escape sequence")
+			}
+			b.WriteByte(esc)
+		case 'u':
+			r, err := p.parseHexRune()
+			if err != nil {
+				return "", err
+			}
+			if utf16.IsSurrogate(r) {
+				if !p.consume(`\u`) {
+					return "", p.errorf("invalid surrogate pair")
+				}
+				low, err := p.parseHexRune()
+				if err != nil {
+					return "", err
+				}
+				if r = utf16.DecodeRune(r, low); r == utf8.RuneError {
+					return "", p.errorf("invalid surrogate pair")
+				}
+			}
+			b.WriteRune(r)
+		default:
+			return "", p.errorf("invalid escape sequence")
+		}
+	}
+}
+
+func (p *jsonPathParser) parseHexRune() (rune, error) {
+	if p.pos+4 > len(p.query) {
+		return 0, p.errorf("invalid unicode escape")
+	}
+	n, err := strconv.ParseUint(p.query[p.pos:p.pos+4], 16, 32)
+	if err != nil {
+		return 0, p.errorf("invalid unicode escape")
+	}
+	p.pos += 4
+	return rune(n), nil
+}
+
+// parseLogical parses logical expression of filter selector.
+func (p *jsonPathParser) parseLogical() (jsonPathLogical, error) {
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	return p.toLogical(expr)
+}
+
+func (p *jsonPathParser) toLogical(expr interface{}) (jsonPathLogical, error) {
+	bare, ok := expr.(*jsonPathBare)
+	if !ok {
+		return expr.(jsonPathLogical), nil
+	}
+	switch operand := bare.operand.(type) {
+	case *jsonPath:
+		return &jsonPathTest{operand}, nil
+	case *jsonPathCall:
+		if operand.fn.result == jsonPathValueType {
+			return nil, p.errorf("result of %s() must be compared", operand.name)
+		}
+		return &jsonPathTest{operand}, nil
+	}
+	return nil, p.errorf("literal must be compared")
+}
+
+func (p *jsonPathParser) parseOr() (interface{}, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	var exprs jsonPathOr
+	for {
+		p.skipSpace()
+		if !p.consume("||") {
+			break
+		}
+		if exprs == nil {
+			l, err := p.toLogical(first)
+			if err != nil {
+				return nil, err
+			}
+			exprs = append(exprs, l)
+		}
+		p.skipSpace()
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l, err := p.toLogical(next)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, l)
+	}
+
+	if exprs == nil {
+		return first, nil
+	}
+	return exprs, nil
+}
+
+func (p *jsonPathParser) parseAnd() (interface{}, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	var exprs jsonPathAnd
+	for {
+		p.skipSpace()
+		if !p.consume("&&") {
+			break
+		}
+		if exprs == nil {
+			l, err := p.toLogical(first)
+			if err != nil {
+				return nil, err
+			}
+			exprs = append(exprs, l)
+		}
+		p.skipSpace()
+		next, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l, err := p.toLogical(next)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, l)
+	}
+
+	if exprs == nil {
+		return first, nil
+	}
+	return exprs, nil
+}
+
+func (p *jsonPathParser) parseUnary() (interface{}, error) {
+	if p.peek() == '!' && !strings.HasPrefix(p.query[p.pos:], "!=") {
+		p.pos++
+		p.skipSpace()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l, err := p.toLogical(expr)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonPathNot{l}, nil
+	}
+
+	if p.consume("(") {
+		p.skipSpace()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.consume(")") {
+			return nil, p.errorf("expected ')'")
+		}
+		return p.toLogical(expr)
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+
+	op := ""
+	for _, candidate := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return &jsonPathBare{left}, nil
+	}
+
+	p.skipSpace()
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, operand := range []interface{}{left, right} {
+		if err := p.checkComparable(operand); err != nil {
+			return nil, err
+		}
+	}
+
+	return &jsonPathComparison{op: op, left: left, right: right}, nil
+}
+
+func (p *jsonPathParser) checkComparable(operand interface{}) error {
+	switch op := operand.(type) {
+	case *jsonPath:
+		if !op.isSingular() {
+			return p.errorf("non-singular query %q can't be compared", op.query)
+		}
+	case *jsonPathCall:
+		if op.fn.result != jsonPathValueType {
+			return p.errorf("result of %s() can't be compared", op.name)
+		}
+	}
+	return nil
+}
+
+// parseOperand parses query, literal, or function call.
+func (p *jsonPathParser) parseOperand() (interface{}, error) {
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		return p.parseSegments(c == '@')
+
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &jsonPathLiteral{s}, nil
+
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+
+	case c >= 'a' && c <= 'z':
+		start := p.pos
+		for p.pos < len(p.query) {
+			c := p.query[p.pos]
+			if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')) {
+				break
+			}
+			p.pos++
+		}
+		name := p.query[start:p.pos]
+
+		if p.peek() == '(' {
+			return p.parseCall(name)
+		}
+
+		switch name {
+		case "true":
+			return &jsonPathLiteral{true}, nil
+		case "false":
+			return &jsonPathLiteral{false}, nil
+		case "null":
+			return &jsonPathLiteral{nil}, nil
+		}
+
+		p.pos = start
+		return nil, p.errorf("unexpected identifier %q", name)
+	}
+
+	return nil, p.errorf("expected query, literal, or function call")
+}
+
+func (p *jsonPathParser) parseNumber() (interface{}, error) {
+	start := p.pos
+	p.consume("-")
+	digits := p.pos
+	for p.pos < len(p.query) &&
+		strings.IndexByte("0123456789.eE+-", p.query[p.pos]) >= 0 {
+		if c := p.query[p.pos]; (c == '+' || c == '-') &&
+			p.query[p.pos-1] != 'e' && p.query[p.pos-1] != 'E' {
+			break
+		}
+		p.pos++
+	}
+
+	s := p.query[start:p.pos]
+	valid := p.pos > digits && p.query[digits] >= '0' && p.query[digits] <= '9' &&
+		!(p.query[digits] == '0' && p.pos > digits+1 &&
+			p.query[digits+1] >= '0' && p.query[digits+1] <= '9') &&
+		!strings.HasSuffix(s, ".") && !strings.Contains(s, ".e") &&
+		!strings.Contains(s, ".E")
+
+	f, err := strconv.ParseFloat(s, 64)
+	if !valid || err != nil {
+		p.pos = start
+		return nil, p.errorf("invalid number")
+	}
+	return &jsonPathLiteral{f}, nil
+}
+
+func (p *jsonPathParser) parseCall(name string) (interface{}, error) {
+	fn, ok := jsonPathFunctions[name]
+	if !ok {
+		return nil, p.errorf("unknown function %s()", name)
+	}
+
+	p.pos++ // '('
+
+	call := &jsonPathCall{name: name, fn: fn}
+
+	for {
+		p.skipSpace()
+		if len(call.args) == 0 && p.consume(")") {
+			break
+		}
+
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		arg, err := p.checkArgument(call, len(call.args), expr)
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+
+		p.skipSpace()
+		if p.consume(")") {
+			break
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected ',' or ')'")
+		}
+	}
+
+	if len(call.args) != len(fn.params) {
+		return nil, p.errorf("%s() expects %d argument(s), but got %d",
+			name, len(fn.params), len(call.args))
+	}
+
+	return call, nil
+}
+
+// checkArgument checks that argument is well-typed for function parameter
+// and converts it to form expected by jsonPathCall.call.
+func (p *jsonPathParser) checkArgument(
+	call *jsonPathCall, n int, expr interface{},
+) (interface{}, error) {
+	if n >= len(call.fn.params) {
+		return nil, p.errorf("too many arguments for %s()", call.name)
+	}
+
+	bare, isBare := expr.(*jsonPathBare)
+
+	switch call.fn.params[n] {
+	case jsonPathValueType:
+		if !isBare {
+			return nil, p.errorf("argument %d of %s() must be a value", n+1, call.name)
+		}
+		if err := p.checkComparable(bare.operand); err != nil {
+			return nil, err
+		}
+		return bare.operand, nil
+
+	case jsonPathLogicalType:
+		return p.toLogical(expr)
+
+	case jsonPathNodesType:
+		if isBare {
+			switch operand := bare.operand.(type) {
+			case *jsonPath:
+				return operand, nil
+			case *jsonPathCall:
+				if operand.fn.result == jsonPathNodesType {
+					return operand, nil
+				}
+			}
+		}
+		return nil, p.errorf("argument %d of %s() must be a query", n+1, call.name)
+	}
+
+	return nil, p.errorf("invalid argument")
+}
@@ -0,0 +1,495 @@
+package httpexpect
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeJSON(t *testing.T, s string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(s), &value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+// examples from RFC 9535, Section 1.5
+const jsonPathBookstore = `{
+  "store": {
+    "book": [
+      {
+        "category": "reference",
+        "author": "Nigel Rees",
+        "title": "Sayings of the Century",
+        "price": 8.95
+      },
+      {
+        "category": "fiction",
+        "author": "Evelyn Waugh",
+        "title": "Sword of Honour",
+        "price": 12.99
+      },
+      {
+        "category": "fiction",
+        "author": "Herman Melville",
+        "title": "Moby Dick",
+        "isbn": "0-553-21311-3",
+        "price": 8.99
+      },
+      {
+        "category": "fiction",
+        "author": "J. R. R. Tolkien",
+        "title": "The Lord of the Rings",
+        "isbn": "0-395-19395-8",
+        "price": 22.99
+      }
+    ],
+    "bicycle": {
+      "color": "red",
+      "price": 399
+    }
+  }
+}`
+
+func TestJSONPathBookstore(t *testing.T) {
+	data := decodeJSON(t, jsonPathBookstore)
+
+	tests := map[string]interface{}{
+		"$.store.book[*].author": []interface{}{
+			"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien",
+		},
+		"$..author": []interface{}{
+			"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien",
+		},
+		"$.store..price": []interface{}{
+			399.0, 8.95, 12.99, 8.99, 22.99,
+		},
+		"$..book[2].author":  []interface{}{"Herman Melville"},
+		"$..book[-1].title":  []interface{}{"The Lord of the Rings"},
+		"$..book[0,1].title": []interface{}{"Sayings of the Century", "Sword of Honour"},
+		"$..book[:2].title":  []interface{}{"Sayings of the Century", "Sword of Honour"},
+		"$..book[?@.isbn].title": []interface{}{
+			"Moby Dick", "The Lord of the Rings",
+		},
+		"$..book[?@.price<10].title": []interface{}{
+			"Sayings of the Century", "Moby Dick",
+		},
+		"$..book[?!@.isbn].title": []interface{}{
+			"Sayings of the Century", "Sword of Honour",
+		},
+		`$.store.book[?@.category == "fiction" && @.price > 20].title`: []interface{}{
+			"The Lord of the Rings",
+		},
+		`$.store.book[?(@.price < 9 || @.price > 20)].price`: []interface{}{
+			8.95, 8.99, 22.99,
+		},
+		"$..book[?@.price > $.store.bicycle.price]": []interface{}{},
+	}
+
+	for path, expected := range tests {
+		t.Run(path, func(t *testing.T) {
+			reporter := newMockReporter(t)
+			value := NewValue(reporter, data)
+
+			actual := value.PathAll(path)
+			actual.chain.assertOK(t)
+			value.chain.assertOK(t)
+
+			assert.Equal(t, expected, actual.Raw())
+		})
+	}
+}
+
+func TestJSONPathFilters(t *testing.T) {
+	data := decodeJSON(t, `{
+	  "a": [3, 5, 1, 2, 4, 6,
+	        {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}],
+	  "o": {"p": 1, "q": 2, "r": 3, "s": 5, "t": {"u": 6}},
+	  "e": "f"
+	}`)
+
+	tests := map[string]interface{}{
+		`$.a[?@.b == 'kilo']`: []interface{}{
+			map[string]interface{}{"b": "kilo"},
+		},
+		`$.a[?(@.b == 'kilo')]`: []interface{}{
+			map[string]interface{}{"b": "kilo"},
+		},
+		`$.a[?@>3.5]`:    []interface{}{5.0, 4.0, 6.0},
+		`$.a[?@ == 1e0]`: []interface{}{1.0},
+		`$.a[?@.b]`: []interface{}{
+			map[string]interface{}{"b": "j"},
+			map[string]interface{}{"b": "k"},
+			map[string]interface{}{"b": map[string]interface{}{}},
+			map[string]interface{}{"b": "kilo"},
+		},
+		`$[?@.*]`: []interface{}{
+			decodeJSON(t, `[3, 5, 1, 2, 4, 6,
+			  {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}]`),
+			decodeJSON(t, `{"p": 1, "q": 2, "r": 3, "s": 5, "t": {"u": 6}}`),
+		},
+		`$[?@[?@.b]]`: []interface{}{
+			decodeJSON(t, `[3, 5, 1, 2, 4, 6,
+			  {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}]`),
+		},
+		`$.o[?@<3, ?@<3]`: []interface{}{1.0, 2.0, 1.0, 2.0},
+		`$.a[?@<2 || @.b == "k"]`: []interface{}{
+			1.0, map[string]interface{}{"b": "k"},
+		},
+		`$.a[?match(@.b, "[jk]")]`: []interface{}{
+			map[string]interface{}{"b": "j"},
+			map[string]interface{}{"b": "k"},
+		},
+		`$.a[?search(@.b, "[jk]")]`: []interface{}{
+			map[string]interface{}{"b": "j"},
+			map[string]interface{}{"b": "k"},
+			map[string]interface{}{"b": "kilo"},
+		},
+		`$.o[?@>1 && @<4]`: []interface{}{2.0, 3.0},
+		`$.o[?@.u || @.x]`: []interface{}{map[string]interface{}{"u": 6.0}},
+		`$.a[?@.b == $.x]`: []interface{}{3.0, 5.0, 1.0, 2.0, 4.0, 6.0},
+		`$.a[?@ == @]`: decodeJSON(t, `[3, 5, 1, 2, 4, 6,
+		  {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}]`),
+		`$.a[?@.b == {}]`:     nil,
+		`$.a[?!(@ > 3)][0:2]`: []interface{}{},
+		`$.a[?length(@.b) == 4]`: []interface{}{
+			map[string]interface{}{"b": "kilo"},
+		},
+		`$[?count(@.*) == 5]`: []interface{}{
+			decodeJSON(t, `{"p": 1, "q": 2, "r": 3, "s": 5, "t": {"u": 6}}`),
+		},
+		`$.a[?value(@..b) == "j"]`: []interface{}{
+			map[string]interface{}{"b": "j"},
+		},
+		`$.a[?@ != 3 && @ < 4]`: []interface{}{1.0, 2.0},
+		`$.a[?@.b < "k"]`:       []interface{}{map[string]interface{}{"b": "j"}},
+		`$.a[?@.b <= "k"]`: []interface{}{
+			map[string]interface{}{"b": "j"},
+			map[string]interface{}{"b": "k"},
+		},
+		`$.a[?@ >= true]`: []interface{}{},
+		`$[?@ == "f"]`:    []interface{}{"f"},
+	}
+
+	for path, expected := range tests {
+		t.Run(path, func(t *testing.T) {
+			reporter := newMockReporter(t)
+			value := NewValue(reporter, data)
+
+			actual := value.PathAll(path)
+			if expected == nil {
+				actual.chain.assertFailed(t)
+				value.chain.assertFailed(t)
+				return
+			}
+
+			actual.chain.assertOK(t)
+			value.chain.assertOK(t)
+
+			assert.Equal(t, expected, actual.Raw())
+		})
+	}
+}
+
+func TestJSONPathSlices(t *testing.T) {
+	data := decodeJSON(t, `["a", "b", "c", "d", "e", "f", "g"]`)
+
+	tests := map[string][]interface{}{
+		"$[1:3]":     {"b", "c"},
+		"$[5:]":      {"f", "g"},
+		"$[1:5:2]":   {"b", "d"},
+		"$[5:1:-2]":  {"f", "d"},
+		"$[::-1]":    {"g", "f", "e", "d", "c", "b", "a"},
+		"$[-2:]":     {"f", "g"},
+		"$[:100]":    {"a", "b", "c", "d", "e", "f", "g"},
+		"$[-100:2]":  {"a", "b"},
+		"$[1:3:0]":   {},
+		"$[3:1]":     {},
+		"$[ 1 : 3 ]": {"b", "c"},
+		"$[0, -1]":   {"a", "g"},
+		"$[7]":       {},
+		"$[-8]":      {},
+	}
+
+	for path, expected := range tests {
+		t.Run(path, func(t *testing.T) {
+			reporter := newMockReporter(t)
+			value := NewValue(reporter, data)
+
+			actual := value.PathAll(path)
+			actual.chain.assertOK(t)
+
+			assert.Equal(t, expected, actual.Raw())
+		})
+	}
+}
+
+func TestJSONPathNames(t *testing.T) {
+	data := decodeJSON(t, `{
+	  "o": {"j j": {"k.k": 3}},
+	  "'": {"@": 2},
+	  "☺": 4,
+	  "a\nb": 5,
+	  "snake_case1": 6
+	}`)
+
+	tests := map[string]interface{}{
+		`$.o['j j']`:           map[string]interface{}{"k.k": 3.0},
+		`$.o['j j']['k.k']`:    3.0,
+		`$.o["j j"]["k.k"]`:    3.0,
+		`$["'"]["@"]`:          2.0,
+		`$['\'']['@']`:         2.0,
+		`$.☺`:                  4.0,
+		`$['☺']`:               4.0,
+		`$["a\nb"]`:            5.0,
+		`$.snake_case1`:        6.0,
+		`$ .o ['j j'] ['k.k']`: 3.0,
+	}
+
+	for path, expected := range tests {
+		t.Run(path, func(t *testing.T) {
+			reporter := newMockReporter(t)
+			value := NewValue(reporter, data)
+
+			actual := value.Path(path)
+			actual.chain.assertOK(t)
+
+			assert.Equal(t, expected, actual.Raw())
+		})
+	}
+}
+
+func TestJSONPathSyntaxErrors(t *testing.T) {
+	data := decodeJSON(t, `{"a": [1, 2, 3], "b": "c"}`)
+
+	paths := []string{
+		"",
+		"a",
+		"$.",
+		"$..",
+		"$[",
+		"$[1",
+		"$[1,]",
+		"$['a]",
+		`$["a\x"]`,
+		`$['a\"']`,
+		"$[01]",
+		"$[-0]",
+		"$[9007199254740992]",
+		"$[1:2:3:4]",
+		"$.a[?@ == 01]",
+		"$.a[?1]",
+		"$.a[?'a']",
+		"$.a[?true]",
+		"$.a[?@ == foo]",
+		"$.a[?(@ == 1]",
+		"$.a[?length(@) ]",
+		"$.a[?length(@.*) == 1]",
+		"$.a[?count(1) == 1]",
+		"$.a[?count(@, @) == 1]",
+		"$.a[?foo(@) == 1]",
+		"$.a[?@.* == 1]",
+		"$.a[?@..x == 1]",
+		"$.a[?match(@, 'a') == true]",
+		"$.a b",
+	}
+
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			reporter := newMockReporter(t)
+			value := NewValue(reporter, data)
+
+			pathValue := value.Path(path)
+			pathValue.chain.assertFailed(t)
+			value.chain.assertFailed(t)
+
+			value.chain.reset()
+
+			pathAll := value.PathAll(path)
+			pathAll.chain.assertFailed(t)
+			value.chain.assertFailed(t)
+
+			assert.Nil(t, pathValue.Raw())
+			assert.Nil(t, pathAll.Raw())
+		})
+	}
+}
+
+func TestJSONPathSegmentErrors(t *testing.T) {
+	data := decodeJSON(t, `{"a": [{"b": "c"}], "d": "e"}`)
+
+	tests := map[string]string{
+		"$.x": `JSONPath "$.x": segment ".x" failed to resolve:` +
+			` member "x" not found in object`,
+		"$.a[3].b": `JSONPath "$.a[3].b": segment "[3]" failed to resolve:` +
+			` index 3 out of range for array of length 1`,
+		"$.a[0].b.c": `JSONPath "$.a[0].b.c": segment ".c" failed to resolve:` +
+			` expected object, but got string`,
+		"$['d'][0]": `JSONPath "$['d'][0]": segment "[0]" failed to resolve:` +
+			` expected array, but got string`,
+	}
+
+	for path, message := range tests {
+		t.Run(path, func(t *testing.T) {
+			reporter := newMockFailureReporter(t)
+			value := NewValue(reporter, data)
+
+			actual := value.Path(path)
+			actual.chain.assertFailed(t)
+			value.chain.assertFailed(t)
+
+			assert.Equal(t, 1, len(reporter.failures))
+			assert.Contains(t, reporter.failures[0].Message, message)
+
+			value.chain.reset()
+
+			all := value.PathAll(path)
+			all.chain.assertOK(t)
+			assert.Equal(t, []interface{}{}, all.Raw())
+		})
+	}
+}
+
+func TestJSONPathAll(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	data := decodeJSON(t, `{"users": [{"name": "john"}, {"name": "bob"}]}`)
+
+	value := NewValue(reporter, data)
+
+	value.PathAll("$.users[0].name").ContainsOnly("john")
+	value.PathAll("$.users[*].name").Equal([]interface{}{"john", "bob"})
+	value.PathAll("$").Equal([]interface{}{data})
+	value.PathAll("$.users[5]").Empty()
+	value.chain.assertOK(t)
+
+	for _, path := range []string{"$.users", "$.users[0]"} {
+		NewObject(reporter, data.(map[string]interface{})).
+			PathAll(path).Length().Equal(1)
+	}
+
+	NewArray(reporter, []interface{}{1, 2}).PathAll("$[*]").Elements(1, 2)
+	NewString(reporter, "foo").PathAll("$").Elements("foo")
+	NewNumber(reporter, 1).PathAll("$").Elements(1)
+	NewBoolean(reporter, true).PathAll("$").Elements(true)
+
+	value.chain.assertOK(t)
+
+	failed := NewValue(reporter, data)
+	failed.chain.fail("fail")
+	failed.PathAll("$.users").chain.assertFailed(t)
+}
+
+func TestJSONPointer(t *testing.T) {
+	// examples from RFC 6901, Section 5
+	data := decodeJSON(t, `{
+	  "foo": ["bar", "baz"],
+	  "": 0,
+	  "a/b": 1,
+	  "c%d": 2,
+	  "e^f": 3,
+	  "g|h": 4,
+	  "i\\j": 5,
+	  "k\"l": 6,
+	  " ": 7,
+	  "m~n": 8
+	}`)
+
+	tests := map[string]interface{}{
+		"":       data,
+		"/foo":   []interface{}{"bar", "baz"},
+		"/foo/0": "bar",
+		"/":      0.0,
+		"/a~1b":  1.0,
+		"/c%d":   2.0,
+		"/e^f":   3.0,
+		"/g|h":   4.0,
+		"/i\\j":  5.0,
+		"/k\"l":  6.0,
+		"/ ":     7.0,
+		"/m~0n":  8.0,
+	}
+
+	for pointer, expected := range tests {
+		t.Run(pointer, func(t *testing.T) {
+			reporter := newMockReporter(t)
+			value := NewValue(reporter, data)
+
+			actual := value.Pointer(pointer)
+			actual.chain.assertOK(t)
+			value.chain.assertOK(t)
+
+			assert.Equal(t, expected, actual.Raw())
+		})
+	}
+}
+
+func TestJSONPointerTypes(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	data := map[string]interface{}{
+		"a": []interface{}{map[string]interface{}{"b": "c"}},
+	}
+
+	NewObject(reporter, data).Pointer("/a/0/b").String().Equal("c")
+	NewArray(reporter, []interface{}{"x", "y"}).Pointer("/1").String().Equal("y")
+	NewString(reporter, "foo").Pointer("").String().Equal("foo")
+	NewNumber(reporter, 1).Pointer("").Number().Equal(1)
+	NewBoolean(reporter, true).Pointer("").Boolean().True()
+
+	value := NewValue(reporter, data)
+	elem := value.Pointer("/a/0/b")
+	elem.chain.assertOK(t)
+	assert.Equal(t, "$.a[0].b", elem.chain.jsonPath)
+
+	failed := NewValue(reporter, data)
+	failed.chain.fail("fail")
+	failed.Pointer("/a").chain.assertFailed(t)
+}
+
+func TestJSONPointerErrors(t *testing.T) {
+	data := decodeJSON(t, `{"a": [{"b": "c"}], "d~": 1}`)
+
+	tests := map[string]string{
+		"a":    `invalid JSON Pointer "a": expected leading '/'`,
+		"/d~":  `invalid JSON Pointer "/d~": invalid escape sequence in "d~"`,
+		"/d~2": `invalid JSON Pointer "/d~2": invalid escape sequence in "d~2"`,
+		"/x": `JSON Pointer "/x": segment "/x" failed to resolve:` +
+			` member "x" not found in object`,
+		"/a/1": `JSON Pointer "/a/1": segment "/1" failed to resolve:` +
+			` index 1 out of range for array of length 1`,
+		"/a/01": `JSON Pointer "/a/01": segment "/01" failed to resolve:` +
+			` invalid array index "01"`,
+		"/a/-1": `JSON Pointer "/a/-1": segment "/-1" failed to resolve:` +
+			` invalid array index "-1"`,
+		"/a/-0": `JSON Pointer "/a/-0": segment "/-0" failed to resolve:` +
+			` invalid array index "-0"`,
+		"/a/+0": `JSON Pointer "/a/+0": segment "/+0" failed to resolve:` +
+			` invalid array index "+0"`,
+		"/a/": `JSON Pointer "/a/": segment "/" failed to resolve:` +
+			` invalid array index ""`,
+		"/a/99999999999999999999": `segment "/99999999999999999999" failed to resolve:` +
+			` invalid array index "99999999999999999999"`,
+		"/a/-": `JSON Pointer "/a/-": segment "/-" failed to resolve:` +
+			` index "-" refers to nonexistent element after the last one`,
+		"/a/0/b/c": `JSON Pointer "/a/0/b/c": segment "/c" failed to resolve:` +
+			` expected object or array, but got string`,
+	}
+
+	for pointer, message := range tests {
+		t.Run(pointer, func(t *testing.T) {
+			reporter := newMockFailureReporter(t)
+			value := NewValue(reporter, data)
+
+			actual := value.Pointer(pointer)
+			actual.chain.assertFailed(t)
+			value.chain.assertFailed(t)
+
+			assert.Nil(t, actual.Raw())
+			assert.Equal(t, 1, len(reporter.failures))
+			assert.Contains(t, reporter.failures[0].Message, message)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package httpexpect
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseJSONPointer parses JSON Pointer, as defined in RFC 6901, and returns
+// its unescaped reference tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON Pointer %q: expected leading '/'", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+
+	for n, token := range tokens {
+		for i := 0; i < len(token); i++ {
+			if token[i] == '~' &&
+				(i+1 == len(token) || (token[i+1] != '0' && token[i+1] != '1')) {
+				return nil, fmt.Errorf(
+					"invalid JSON Pointer %q: invalid escape sequence in %q",
+					pointer, token)
+			}
+		}
+		token = strings.Replace(token, "~1", "/", -1)
+		token = strings.Replace(token, "~0", "~", -1)
+		tokens[n] = token
+	}
+
+	return tokens, nil
+}
+
+// resolveJSONPointer returns value referenced by JSON Pointer tokens, and
+// list of member names (strings) and array indices (ints) leading to it.
+func resolveJSONPointer(
+	value interface{}, pointer string, tokens []string,
+) (interface{}, []interface{}, error) {
+	node := value
+	keys := make([]interface{}, 0, len(tokens))
+
+	segments := strings.Split(pointer, "/")
+
+	fail := func(n int, err error) (interface{}, []interface{}, error) {
+		return nil, nil, fmt.Errorf("JSON Pointer %q: segment %q failed to resolve: %s",
+			pointer, "/"+segments[n+1], err.Error())
+	}
+
+	for n, token := range tokens {
+		switch v := node.(type) {
+		case map[string]interface{}:
+			child, ok := v[token]
+			if !ok {
+				return fail(n, fmt.Errorf("member %q not found in object", token))
+			}
+			keys = append(keys, token)
+			node = child
+
+		case []interface{}:
+			if token == "-" {
+				return fail(n, fmt.Errorf(
+					"index \"-\" refers to nonexistent element after the last one"))
+			}
+			if !isJSONPointerIndex(token) {
+				return fail(n, fmt.Errorf("invalid array index %q", token))
+			}
+			index, err := strconv.Atoi(token)
+			if err != nil {
+				return fail(n, fmt.Errorf("invalid array index %q", token))
+			}
+			if index >= len(v) {
+				return fail(n, fmt.Errorf("index %d out of range for array of length %d",
+					index, len(v)))
+			}
+			keys = append(keys, index)
+			node = v[index]
+
+		default:
+			return fail(n, fmt.Errorf("expected object or array, but got %s",
+				jsonTypeName(node)))
+		}
+	}
+
+	return node, keys, nil
+}
+
+// isJSONPointerIndex reports whether token is an array index allowed by
+// RFC 6901, i.e. "0" or digits without leading zero.
+func isJSONPointerIndex(token string) bool {
+	if token == "" || (token[0] == '0' && len(token) > 1) {
+		return false
+	}
+	for _, c := range token {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,286 @@
+package httpexpect
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Links provides methods to inspect web links from Link header, as defined
+// in RFC 8288.
+//
+// Link relation types are case-insensitive. Link with several relation
+// types, like rel="next last", is available under every type. If several
+// links have the same relation type, the first one is used.
+type Links struct {
+	chain chain
+	links []webLink
+}
+
+type webLink struct {
+	target string
+	params []linkParam
+}
+
+type linkParam struct {
+	name  string
+	value string
+}
+
+// NewLinks returns a new Links object given a reporter used to report
+// failures and Link header value to be inspected.
+//
+// reporter should not be nil.
+//
+// Example:
+//  links := NewLinks(reporter,
+//      `</users?page=2>; rel="next", </users?page=5>; rel="last"`)
+//  links.URL("next").Equal("/users?page=2")
+func NewLinks(reporter Reporter, value string) *Links {
+	chain := makeChain(reporter).root("Links")
+	links := parseLinks(&chain, http.Header{"Link": {value}}, nil)
+	return &Links{chain, links}
+}
+
+// parseLinks parses Link header and reports failure to given chain if
+// it's malformed. If base is non-nil, link targets are resolved against it.
+func parseLinks(chain *chain, header http.Header, base *url.URL) []webLink {
+	links, err := parseLinkHeader(header, base)
+	if err != nil {
+		chain.fail("\nexpected valid \"Link\" header, but got:\n %q\n\n%s",
+			strings.Join(header["Link"], ", "), err.Error())
+		return nil
+	}
+	return links
+}
+
+// Raw returns links as a map of relation type to link target.
+func (l *Links) Raw() map[string]string {
+	ret := map[string]string{}
+	for _, link := range l.links {
+		for _, rel := range link.rels() {
+			if _, ok := ret[rel]; !ok {
+				ret[rel] = link.target
+			}
+		}
+	}
+	return ret
+}
+
+// Rels returns a new Array object with relation types of all links.
+// Returned Array contains a lower-cased String value for every type.
+//
+// Example:
+//  links := NewLinks(t, `</users?page=2>; rel="next", </users?page=5>; rel="last"`)
+//  links.Rels().ContainsOnly("next", "last")
+func (l *Links) Rels() *Array {
+	rels := []interface{}{}
+	seen := map[string]bool{}
+	for _, link := range l.links {
+		for _, rel := range link.rels() {
+			if !seen[rel] {
+				seen[rel] = true
+				rels = append(rels, rel)
+			}
+		}
+	}
+	return &Array{l.chain.enter("Rels()"), rels}
+}
+
+// Contains succeeds if there is link with given relation type.
+//
+// Example:
+//  links := NewLinks(t, `</users?page=2>; rel="next"`)
+//  links.Contains("next")
+func (l *Links) Contains(rel string) *Links {
+	if l.chain.failed() {
+		return l
+	}
+	if l.lookup(rel) == nil {
+		l.chain.fail("\nexpected \"Link\" header containing %q relation, but got:\n%s",
+			rel, dumpValue(l.Raw()))
+	}
+	return l
+}
+
+// NotContains succeeds if there is no link with given relation type.
+//
+// Example:
+//  links := NewLinks(t, `</users?page=2>; rel="next"`)
+//  links.NotContains("prev")
+func (l *Links) NotContains(rel string) *Links {
+	if l.chain.failed() {
+		return l
+	}
+	if l.lookup(rel) != nil {
+		l.chain.fail(
+			"\nexpected \"Link\" header not containing %q relation, but got:\n%s",
+			rel, dumpValue(l.Raw()))
+	}
+	return l
+}
+
+// URL returns a new String object that may be used to inspect target of
+// link with given relation type.
+//
+// If links were obtained using Response.Links, relative targets are
+// resolved against request URL.
+//
+// If link is missing, failure is reported.
+//
+// Example:
+//  links := NewLinks(t, `</users?page=2>; rel="next"`)
+//  links.URL("next").Equal("/users?page=2")
+func (l *Links) URL(rel string) *String {
+	if l.Contains(rel).chain.failed() {
+		return &String{l.chain.enter("URL(%q)", rel), ""}
+	}
+	return &String{l.chain.enter("URL(%q)", rel), l.lookup(rel).target}
+}
+
+// Param returns a new String object that may be used to inspect given
+// target attribute, like "title" or "type", of link with given relation
+// type. Attribute names are case-insensitive.
+//
+// If link or attribute is missing, failure is reported.
+//
+// Example:
+//  links := NewLinks(t, `</docs>; rel="help"; title="Documentation"`)
+//  links.Param("help", "title").Equal("Documentation")
+func (l *Links) Param(rel, name string) *String {
+	if l.Contains(rel).chain.failed() {
+		return &String{l.chain.enter("Param(%q, %q)", rel, name), ""}
+	}
+	value, ok := l.lookup(rel).param(name)
+	if !ok {
+		l.chain.fail("\nexpected %q link with %q attribute, but got:\n %s",
+			rel, name, l.lookup(rel).format())
+	}
+	return &String{l.chain.enter("Param(%q, %q)", rel, name), value}
+}
+
+func (l *Links) lookup(rel string) *webLink {
+	rel = strings.ToLower(rel)
+	for n := range l.links {
+		for _, r := range l.links[n].rels() {
+			if r == rel {
+				return &l.links[n]
+			}
+		}
+	}
+	return nil
+}
+
+func (link *webLink) rels() []string {
+	value, _ := link.param("rel")
+	return strings.Fields(strings.ToLower(value))
+}
+
+func (link *webLink) param(name string) (string, bool) {
+	for _, p := range link.params {
+		if strings.EqualFold(p.name, name) {
+			return p.value, true
+		}
+	}
+	return "", false
+}
+
+func (link *webLink) format() string {
+	var b strings.Builder
+	b.WriteString("<" + link.target + ">")
+	for _, p := range link.params {
+		b.WriteString("; " + p.name + "=" + quoteAuthParam(p.value))
+	}
+	return b.String()
+}
+
+// parseLinkHeader parses all values of Link header, every value being
+// a comma-separated list of links in form of:
+//  <target>; name=token; name="quoted string"
+func parseLinkHeader(header http.Header, base *url.URL) ([]webLink, error) {
+	var links []webLink
+
+	for _, value := range header["Link"] {
+		s := value
+		for {
+			s = strings.TrimLeft(s, " \t,")
+			if s == "" {
+				break
+			}
+
+			if s[0] != '<' {
+				return nil, fmt.Errorf("expected '<' at %q", s)
+			}
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated link target at %q", s)
+			}
+
+			link := webLink{target: strings.TrimSpace(s[1:end])}
+			s = s[end+1:]
+
+			if base != nil {
+				ref, err := url.Parse(link.target)
+				if err != nil {
+					return nil, err
+				}
+				link.target = base.ResolveReference(ref).String()
+			}
+
+			for {
+				s = strings.TrimLeft(s, " \t")
+				if s == "" || s[0] == ',' {
+					break
+				}
+				if s[0] != ';' {
+					return nil, fmt.Errorf("expected ';' or ',' at %q", s)
+				}
+				s = strings.TrimLeft(s[1:], " \t")
+
+				n := strings.IndexAny(s, "=;,")
+				if n < 0 {
+					n = len(s)
+				}
+				param := linkParam{name: strings.TrimSpace(s[:n])}
+				s = s[n:]
+
+				if strings.HasPrefix(s, "=") {
+					s = strings.TrimLeft(s[1:], " \t")
+					param.value, s = parseLinkParamValue(s)
+				}
+
+				if param.name != "" {
+					link.params = append(link.params, param)
+				}
+			}
+
+			links = append(links, link)
+		}
+	}
+
+	return links, nil
+}
+
+func parseLinkParamValue(s string) (value, rest string) {
+	if !strings.HasPrefix(s, `"`) {
+		n := strings.IndexAny(s, ";,")
+		if n < 0 {
+			n = len(s)
+		}
+		return strings.TrimSpace(s[:n]), s[n:]
+	}
+
+	var b strings.Builder
+	i := 1
+	for ; i < len(s) && s[i] != '"'; i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	if i < len(s) {
+		i++
+	}
+	return b.String(), s[i:]
+}
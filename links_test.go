@@ -0,0 +1,102 @@
+package httpexpect
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinksFailed(t *testing.T) {
+	chain := makeChain(newMockReporter(t))
+
+	chain.fail("fail")
+
+	value := &Links{chain: chain}
+
+	assert.True(t, value.Rels() != nil)
+	assert.True(t, value.URL("next") != nil)
+	assert.True(t, value.Param("next", "title") != nil)
+
+	value.Contains("next")
+	value.NotContains("next")
+}
+
+func TestLinksParse(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	links := NewLinks(reporter,
+		`</users?page=2>; rel="next", </users?page=1>; rel="prev first",`+
+			` <https://example.com/docs;v=1>; rel=help;`+
+			` title="Docs, \"v1\""; type=text/html`)
+	links.chain.assertOK(t)
+
+	assert.Equal(t, map[string]string{
+		"next":  "/users?page=2",
+		"prev":  "/users?page=1",
+		"first": "/users?page=1",
+		"help":  "https://example.com/docs;v=1",
+	}, links.Raw())
+
+	links.Rels().Equal([]string{"next", "prev", "first", "help"}).chain.assertOK(t)
+
+	links.Contains("NEXT").chain.assertOK(t)
+	links.NotContains("last").chain.assertOK(t)
+
+	links.URL("first").Equal("/users?page=1").chain.assertOK(t)
+	links.Param("help", "Title").Equal(`Docs, "v1"`).chain.assertOK(t)
+	links.Param("help", "type").Equal("text/html").chain.assertOK(t)
+
+	links.Contains("last").chain.assertFailed(t)
+	links.chain.reset()
+
+	links.NotContains("next").chain.assertFailed(t)
+	links.chain.reset()
+
+	links.URL("last").chain.assertFailed(t)
+	links.chain.reset()
+
+	links.Param("next", "title").chain.assertFailed(t)
+	links.chain.reset()
+
+	NewLinks(reporter, "").Rels().Empty().chain.assertOK(t)
+
+	for _, value := range []string{
+		`/users?page=2; rel="next"`,
+		`</users?page=2; rel="next"`,
+		`</users?page=2> rel="next"`,
+	} {
+		NewLinks(reporter, value).chain.assertFailed(t)
+	}
+}
+
+func TestLinksResponse(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	req, _ := http.NewRequest("GET", "http://example.com/api/users?page=1", nil)
+
+	resp := NewResponse(reporter, &http.Response{
+		Header: http.Header{
+			"Link": {
+				`<?page=2>; rel="next"`,
+				`<http://cdn.example.com/users>; rel="alternate"`,
+			},
+		},
+		Request: req,
+	})
+
+	links := resp.Links()
+	links.URL("next").Equal("http://example.com/api/users?page=2")
+	links.URL("alternate").Equal("http://cdn.example.com/users")
+	links.chain.assertOK(t)
+	resp.chain.assertOK(t)
+
+	resp = NewResponse(reporter, &http.Response{
+		Header: http.Header{
+			"Link": {`bad`},
+		},
+	})
+
+	resp.Links()
+	resp.chain.assertFailed(t)
+}
@@ -21,6 +21,19 @@ func (c *mockClient) Do(req *http.Request) (*http.Response, error) {
 	return nil, c.err
 }
 
+// newBinderConfig returns config that sends requests to given handler
+// and reports failures to mockReporter.
+func newBinderConfig(t *testing.T, handler http.Handler) Config {
+	return Config{
+		BaseURL:        "http://example.com",
+		RequestFactory: DefaultRequestFactory{},
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+		Reporter: newMockReporter(t),
+	}
+}
+
 type mockReporter struct {
 	testing  *testing.T
 	reported bool
@@ -34,3 +47,18 @@ func (r *mockReporter) Errorf(message string, args ...interface{}) {
 	r.testing.Logf("Fail: "+message, args...)
 	r.reported = true
 }
+
+type mockFailureReporter struct {
+	mockReporter
+	failures []*AssertionFailure
+}
+
+func newMockFailureReporter(t *testing.T) *mockFailureReporter {
+	return &mockFailureReporter{mockReporter: mockReporter{testing: t}}
+}
+
+func (r *mockFailureReporter) ReportFailure(failure *AssertionFailure) {
+	r.testing.Logf("Fail: %s", failure.Message)
+	r.reported = true
+	r.failures = append(r.failures, failure)
+}
@@ -0,0 +1,373 @@
+package httpexpect
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/ajg/form"
+)
+
+// Multipart provides methods to inspect parts of multipart content,
+// e.g. "multipart/mixed" or "multipart/form-data" response body.
+type Multipart struct {
+	chain chain
+	parts []MultipartPart
+}
+
+// MultipartPart provides methods to inspect a single part of multipart
+// content.
+type MultipartPart struct {
+	chain    chain
+	header   http.Header
+	formName string
+	fileName string
+	content  []byte
+}
+
+// NewMultipart returns a new Multipart object given a reporter used to
+// report failures, value of Content-Type header with boundary parameter,
+// and multipart content to be inspected.
+//
+// reporter should not be nil. If content can't be parsed, failure is
+// reported.
+//
+// Example:
+//  mp := NewMultipart(t, "multipart/mixed; boundary=foo", content)
+//  mp.Length().Equal(2)
+//  mp.Part(0).JSON().Object().ValueEqual("id", 1)
+func NewMultipart(reporter Reporter, contentType string, content string) *Multipart {
+	chain := makeChain(reporter).root("Multipart")
+	boundary := getBoundary(&chain, contentType, nil)
+	return newMultipart(chain, boundary, []byte(content))
+}
+
+// getBoundary checks that Content-Type header denotes multipart content
+// and returns its boundary.
+func getBoundary(chain *chain, contentType string, opts []ContentOpts) string {
+	if chain.failed() {
+		return ""
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		chain.fail("\ngot invalid \"Content-Type\" header %q", contentType)
+		return ""
+	}
+
+	if len(opts) != 0 && opts[0].MediaType != "" {
+		if mediaType != opts[0].MediaType {
+			chain.fail(
+				"\nexpected \"Content-Type\" header with %q media type,"+
+					"\nbut got %q", opts[0].MediaType, mediaType)
+			return ""
+		}
+	} else if !strings.HasPrefix(mediaType, "multipart/") {
+		chain.fail(
+			"\nexpected \"Content-Type\" header with \"multipart/*\" media type,"+
+				"\nbut got %q", mediaType)
+		return ""
+	}
+
+	boundary := params["boundary"]
+	if boundary == "" {
+		chain.fail(
+			"\nexpected \"Content-Type\" header with boundary parameter,"+
+				"\nbut got %q", contentType)
+	}
+
+	return boundary
+}
+
+func newMultipart(chain chain, boundary string, content []byte) *Multipart {
+	m := &Multipart{chain: chain}
+
+	if chain.failed() {
+		return m
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(content), boundary)
+
+	parts := []MultipartPart{}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			m.chain.fail("\nexpected valid multipart content, but got failure: %s",
+				err.Error())
+			return m
+		}
+
+		data, err := ioutil.ReadAll(part)
+		if err != nil {
+			m.chain.fail("\nexpected valid multipart content, but got failure: %s",
+				err.Error())
+			return m
+		}
+
+		parts = append(parts, MultipartPart{
+			header:   http.Header(part.Header),
+			formName: part.FormName(),
+			fileName: part.FileName(),
+			content:  data,
+		})
+	}
+
+	m.parts = parts
+
+	return m
+}
+
+// Length returns a new Number object that may be used to inspect
+// number of parts.
+//
+// Example:
+//  mp := resp.Multipart()
+//  mp.Length().Equal(2)
+func (m *Multipart) Length() *Number {
+	return &Number{m.chain.enter("Length()"), float64(len(m.parts))}
+}
+
+// Part returns a new MultipartPart object that may be used to inspect
+// part with given index.
+//
+// If index is out of bounds, Part reports failure and returns empty
+// (but non-nil) object.
+//
+// Example:
+//  mp := resp.Multipart()
+//  mp.Part(0).Header("Content-Type").Equal("application/json")
+func (m *Multipart) Part(index int) *MultipartPart {
+	if m.chain.failed() {
+		return &MultipartPart{chain: m.chain.enter("Part(%d)", index)}
+	}
+	if index < 0 || index >= len(m.parts) {
+		m.chain.fail(
+			"\nmultipart index out of bounds:\n  index %d\n\n  bounds [%d; %d)",
+			index,
+			0,
+			len(m.parts))
+		return &MultipartPart{chain: m.chain.enter("Part(%d)", index)}
+	}
+	part := m.parts[index]
+	part.chain = m.chain.enter("Part(%d)", index)
+	return &part
+}
+
+// FormPart returns a new MultipartPart object that may be used to inspect
+// first part with given form name.
+//
+// If there is no such part, FormPart reports failure and returns empty
+// (but non-nil) object.
+//
+// Example:
+//  mp := resp.Multipart()
+//  mp.FormPart("avatar").FileName().Equal("avatar.png")
+func (m *Multipart) FormPart(name string) *MultipartPart {
+	if m.chain.failed() {
+		return &MultipartPart{chain: m.chain.enter("FormPart(%q)", name)}
+	}
+	for _, part := range m.parts {
+		if part.formName == name {
+			part.chain = m.chain.enter("FormPart(%q)", name)
+			return &part
+		}
+	}
+	m.chain.fail(
+		"\nexpected multipart with form part:\n %q\n\nbut got only form parts:\n%s",
+		name, dumpValue(m.formNames()))
+	return &MultipartPart{chain: m.chain.enter("FormPart(%q)", name)}
+}
+
+// FormNames returns a new Array object that may be used to inspect
+// form names of all parts, in order. Returned Array contains a String
+// value for every part, which is empty if part has no form name.
+//
+// Example:
+//  mp := resp.Multipart()
+//  mp.FormNames().Elements("name", "avatar")
+func (m *Multipart) FormNames() *Array {
+	if m.chain.failed() {
+		return &Array{m.chain.enter("FormNames()"), nil}
+	}
+	names := []interface{}{}
+	for _, name := range m.formNames() {
+		names = append(names, name)
+	}
+	return &Array{m.chain.enter("FormNames()"), names}
+}
+
+func (m *Multipart) formNames() []string {
+	names := []string{}
+	for _, part := range m.parts {
+		names = append(names, part.formName)
+	}
+	return names
+}
+
+// Iter returns a new slice of MultipartPart objects attached to parts.
+//
+// Example:
+//  mp := resp.Multipart()
+//  for _, part := range mp.Iter() {
+//      part.Header("Content-Type").Equal("text/plain")
+//  }
+func (m *Multipart) Iter() []MultipartPart {
+	if m.chain.failed() {
+		return []MultipartPart{}
+	}
+	ret := []MultipartPart{}
+	for n, part := range m.parts {
+		part.chain = m.chain.enter("Iter()[%d]", n)
+		ret = append(ret, part)
+	}
+	return ret
+}
+
+// Raw returns underlying part content.
+func (p *MultipartPart) Raw() []byte {
+	return p.content
+}
+
+// Headers returns a new Object that may be used to inspect part header map.
+//
+// Example:
+//  part := resp.Multipart().Part(0)
+//  part.Headers().Value("Content-Type").Array().Elements("text/plain")
+func (p *MultipartPart) Headers() *Object {
+	var value map[string]interface{}
+	if !p.chain.failed() {
+		value, _ = canonMap(&p.chain, p.header)
+	}
+	return &Object{p.chain.enter("Headers()"), value}
+}
+
+// Header returns a new String object that may be used to inspect given
+// part header.
+//
+// Example:
+//  part := resp.Multipart().Part(0)
+//  part.Header("Content-ID").Equal("<item1>")
+func (p *MultipartPart) Header(header string) *String {
+	value := ""
+	if !p.chain.failed() {
+		value = p.header.Get(header)
+	}
+	return &String{p.chain.enter("Header(%q)", header), value}
+}
+
+// FormName returns a new String object that may be used to inspect
+// "name" parameter of "form-data" Content-Disposition header of the part.
+// If part has no such header, the string is empty.
+//
+// Example:
+//  part := resp.Multipart().Part(0)
+//  part.FormName().Equal("avatar")
+func (p *MultipartPart) FormName() *String {
+	return &String{p.chain.enter("FormName()"), p.formName}
+}
+
+// FileName returns a new String object that may be used to inspect
+// "filename" parameter of Content-Disposition header of the part.
+// If part has no such parameter, the string is empty.
+//
+// Example:
+//  part := resp.Multipart().Part(0)
+//  part.FileName().Equal("avatar.png")
+func (p *MultipartPart) FileName() *String {
+	return &String{p.chain.enter("FileName()"), p.fileName}
+}
+
+// ContentType succeeds if part contains Content-Type header with given
+// media type and charset. It's similar to Response.ContentType.
+//
+// As defined by RFC 2046, part without Content-Type header is treated
+// as "text/plain".
+func (p *MultipartPart) ContentType(mediaType string, charset ...string) *MultipartPart {
+	checkContentType(&p.chain, p.contentType(), mediaType, charset...)
+	return p
+}
+
+// Body returns a new String object that may be used to inspect part content.
+//
+// Example:
+//  part := resp.Multipart().Part(0)
+//  part.Body().Equal("hello")
+func (p *MultipartPart) Body() *String {
+	return &String{p.chain.enter("Body()"), string(p.content)}
+}
+
+// Text returns a new String object that may be used to inspect part content.
+// It's similar to Response.Text.
+//
+// Example:
+//  part := resp.Multipart().Part(0)
+//  part.Text().Equal("hello")
+func (p *MultipartPart) Text(opts ...ContentOpts) *String {
+	var content string
+	if checkContentOpts(&p.chain, p.contentType(), opts, "text/plain") {
+		content = string(p.content)
+	}
+	return &String{p.chain.enter("Text()"), content}
+}
+
+// JSON returns a new Value object that may be used to inspect JSON contents
+// of the part. It's similar to Response.JSON.
+//
+// Example:
+//  part := resp.Multipart().Part(0)
+//  part.JSON().Object().ValueEqual("id", 1)
+func (p *MultipartPart) JSON(opts ...ContentOpts) *Value {
+	var value interface{}
+	if checkContentOpts(&p.chain, p.contentType(), opts, jsonMediaType(p.contentType())) {
+		if err := json.Unmarshal(p.content, &value); err != nil {
+			p.chain.fail(err.Error())
+		}
+	}
+	return &Value{p.chain.enter("JSON()").rootJSON(), value}
+}
+
+// Form returns a new Object that may be used to inspect form contents
+// of the part. It's similar to Response.Form.
+//
+// Example:
+//  part := resp.Multipart().Part(0)
+//  part.Form().Value("foo").Equal("bar")
+func (p *MultipartPart) Form(opts ...ContentOpts) *Object {
+	var object map[string]interface{}
+	if checkContentOpts(&p.chain, p.contentType(), opts,
+		"application/x-www-form-urlencoded", "") {
+		decoder := form.NewDecoder(bytes.NewReader(p.content))
+		if err := decoder.Decode(&object); err != nil {
+			p.chain.fail(err.Error())
+			object = nil
+		}
+	}
+	return &Object{p.chain.enter("Form()"), object}
+}
+
+// Multipart returns a new Multipart object that may be used to inspect
+// nested multipart content of the part. It's similar to Response.Multipart.
+//
+// Example:
+//  part := resp.Multipart().Part(0)
+//  part.Multipart().Part(0).Body().Equal("hello")
+func (p *MultipartPart) Multipart(opts ...ContentOpts) *Multipart {
+	boundary := getBoundary(&p.chain, p.contentType(), opts)
+	return newMultipart(p.chain.enter("Multipart()"), boundary, p.content)
+}
+
+func (p *MultipartPart) contentType() string {
+	if contentType := p.header.Get("Content-Type"); contentType != "" {
+		return contentType
+	}
+	return "text/plain"
+}
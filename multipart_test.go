@@ -0,0 +1,271 @@
+package httpexpect
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipartFailed(t *testing.T) {
+	chain := makeChain(newMockReporter(t))
+
+	chain.fail("fail")
+
+	mp := &Multipart{chain: chain}
+
+	mp.Length().chain.assertFailed(t)
+	mp.Part(0).chain.assertFailed(t)
+	mp.FormPart("foo").chain.assertFailed(t)
+	mp.FormNames().chain.assertFailed(t)
+
+	assert.Equal(t, 0, len(mp.Iter()))
+
+	part := &MultipartPart{chain: chain}
+
+	part.Headers().chain.assertFailed(t)
+	part.Header("foo").chain.assertFailed(t)
+	part.FormName().chain.assertFailed(t)
+	part.FileName().chain.assertFailed(t)
+	part.ContentType("text/plain").chain.assertFailed(t)
+	part.Body().chain.assertFailed(t)
+	part.Text().chain.assertFailed(t)
+	part.JSON().chain.assertFailed(t)
+	part.Form().chain.assertFailed(t)
+	part.Multipart().chain.assertFailed(t)
+}
+
+type testMultipartPart struct {
+	header  map[string]string
+	content string
+}
+
+func makeTestMultipart(
+	t *testing.T, boundary string, parts ...testMultipartPart,
+) string {
+	var buf bytes.Buffer
+
+	writer := multipart.NewWriter(&buf)
+	require.Nil(t, writer.SetBoundary(boundary))
+
+	for _, p := range parts {
+		header := textproto.MIMEHeader{}
+		for k, v := range p.header {
+			header.Set(k, v)
+		}
+		w, err := writer.CreatePart(header)
+		require.Nil(t, err)
+		_, _ = w.Write([]byte(p.content))
+	}
+
+	require.Nil(t, writer.Close())
+
+	return buf.String()
+}
+
+func TestMultipartParts(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	content := makeTestMultipart(t, "foo",
+		testMultipartPart{
+			header: map[string]string{
+				"Content-Disposition": `form-data; name="meta"`,
+				"Content-Type":        "application/json",
+			},
+			content: `{"id": 1}`,
+		},
+		testMultipartPart{
+			header: map[string]string{
+				"Content-Disposition": `form-data; name="avatar"; filename="a.png"`,
+				"Content-Type":        "image/png",
+			},
+			content: "\x89PNG",
+		},
+		testMultipartPart{
+			header: map[string]string{
+				"Content-Type": "application/x-www-form-urlencoded",
+			},
+			content: "a=1&b=2",
+		},
+		testMultipartPart{
+			content: "hello",
+		},
+	)
+
+	mp := NewMultipart(reporter, "multipart/form-data; boundary=foo", content)
+	mp.chain.assertOK(t)
+
+	mp.Length().Equal(4).chain.assertOK(t)
+	mp.FormNames().Elements("meta", "avatar", "", "").chain.assertOK(t)
+
+	meta := mp.Part(0)
+	meta.chain.assertOK(t)
+	meta.FormName().Equal("meta").chain.assertOK(t)
+	meta.FileName().Empty().chain.assertOK(t)
+	meta.Header("Content-Type").Equal("application/json").chain.assertOK(t)
+	meta.Headers().ContainsKey("Content-Disposition").chain.assertOK(t)
+	meta.ContentType("application/json").chain.assertOK(t)
+	meta.JSON().Object().ValueEqual("id", 1).chain.assertOK(t)
+	meta.Body().Equal(`{"id": 1}`).chain.assertOK(t)
+
+	meta.Text().chain.assertFailed(t)
+	meta.chain.reset()
+
+	avatar := mp.FormPart("avatar")
+	avatar.chain.assertOK(t)
+	avatar.FileName().Equal("a.png").chain.assertOK(t)
+	avatar.ContentType("image/png").chain.assertOK(t)
+	assert.Equal(t, []byte("\x89PNG"), avatar.Raw())
+
+	avatar.JSON().chain.assertFailed(t)
+	avatar.chain.reset()
+
+	mp.Part(2).Form().ValueEqual("a", "1").ValueEqual("b", "2").chain.assertOK(t)
+
+	text := mp.Part(3)
+	text.ContentType("text/plain").chain.assertOK(t)
+	text.Text().Equal("hello").chain.assertOK(t)
+
+	parts := mp.Iter()
+	require.Equal(t, 4, len(parts))
+	parts[1].FormName().Equal("avatar").chain.assertOK(t)
+	parts[3].Body().Equal("hello").chain.assertOK(t)
+
+	mp.Part(4).chain.assertFailed(t)
+	mp.chain.reset()
+
+	mp.Part(-1).chain.assertFailed(t)
+	mp.chain.reset()
+
+	mp.FormPart("missing").chain.assertFailed(t)
+	mp.chain.reset()
+}
+
+func TestMultipartNested(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	nested := makeTestMultipart(t, "bar",
+		testMultipartPart{content: "first"},
+		testMultipartPart{content: "second"},
+	)
+
+	content := makeTestMultipart(t, "foo",
+		testMultipartPart{
+			header: map[string]string{
+				"Content-Type": "multipart/alternative; boundary=bar",
+			},
+			content: nested,
+		},
+		testMultipartPart{content: "plain"},
+	)
+
+	mp := NewMultipart(reporter, "multipart/mixed; boundary=foo", content)
+	mp.chain.assertOK(t)
+
+	inner := mp.Part(0).Multipart()
+	inner.chain.assertOK(t)
+	inner.Length().Equal(2).chain.assertOK(t)
+	inner.Part(1).Text().Equal("second").chain.assertOK(t)
+
+	mp.Part(0).Multipart(ContentOpts{MediaType: "multipart/alternative"}).
+		chain.assertOK(t)
+
+	mp.Part(0).Multipart(ContentOpts{MediaType: "multipart/mixed"}).
+		chain.assertFailed(t)
+
+	mp.Part(1).Multipart().chain.assertFailed(t)
+}
+
+func TestMultipartInvalid(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	content := makeTestMultipart(t, "foo", testMultipartPart{content: "hello"})
+
+	NewMultipart(reporter, "multipart/mixed; boundary=foo", content).
+		chain.assertOK(t)
+
+	NewMultipart(reporter, "multipart/mixed; boundary=foo", "--foo--\r\n").
+		Length().Equal(0).chain.assertOK(t)
+
+	NewMultipart(reporter, "multipart/mixed; boundary=foo", "").chain.assertFailed(t)
+
+	NewMultipart(reporter, "multipart/mixed", content).chain.assertFailed(t)
+	NewMultipart(reporter, "text/plain; boundary=foo", content).chain.assertFailed(t)
+	NewMultipart(reporter, "", content).chain.assertFailed(t)
+
+	NewMultipart(reporter, "multipart/mixed; boundary=foo",
+		"--foo\r\nbad header\r\n\r\n").chain.assertFailed(t)
+}
+
+func TestMultipartResponse(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	content := makeTestMultipart(t, "foo",
+		testMultipartPart{content: "hello"},
+		testMultipartPart{
+			header:  map[string]string{"Content-Type": "application/json"},
+			content: `[1, 2]`,
+		},
+	)
+
+	for _, contentType := range []string{
+		"multipart/mixed; boundary=foo",
+		"multipart/form-data; boundary=foo",
+		`multipart/related; boundary=foo; type="text/plain"`,
+	} {
+		httpResp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header(map[string][]string{
+				"Content-Type": {contentType},
+			}),
+			Body: ioutil.NopCloser(bytes.NewBufferString(content)),
+		}
+
+		resp := NewResponse(reporter, httpResp)
+
+		mp := resp.Multipart()
+		mp.chain.assertOK(t)
+		mp.Length().Equal(2).chain.assertOK(t)
+		mp.Part(0).Text().Equal("hello").chain.assertOK(t)
+		mp.Part(1).JSON().Array().Elements(1, 2).chain.assertOK(t)
+		resp.chain.assertOK(t)
+	}
+
+	for _, contentType := range []string{
+		"multipart/mixed",
+		"application/json; boundary=foo",
+		"",
+	} {
+		httpResp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header(map[string][]string{
+				"Content-Type": {contentType},
+			}),
+			Body: ioutil.NopCloser(bytes.NewBufferString(content)),
+		}
+
+		resp := NewResponse(reporter, httpResp)
+
+		resp.Multipart().chain.assertFailed(t)
+		resp.chain.assertFailed(t)
+	}
+
+	httpResp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header(map[string][]string{
+			"Content-Type": {"multipart/mixed; boundary=foo"},
+		}),
+		Body: ioutil.NopCloser(bytes.NewBufferString(content)),
+	}
+
+	resp := NewResponse(reporter, httpResp)
+
+	resp.Multipart(ContentOpts{MediaType: "multipart/mixed"}).chain.assertOK(t)
+	resp.Multipart(ContentOpts{MediaType: "multipart/form-data"}).
+		chain.assertFailed(t)
+}
@@ -19,7 +19,7 @@ type Number struct {
 // Example:
 //  number := NewNumber(t, 123.4)
 func NewNumber(reporter Reporter, value float64) *Number {
-	return &Number{makeChain(reporter), value}
+	return &Number{makeChain(reporter).root("Number"), value}
 }
 
 // Raw returns underlying value attached to Number.
@@ -37,6 +37,16 @@ func (n *Number) Path(path string) *Value {
 	return getPath(&n.chain, n.value, path)
 }
 
+// PathAll is similar to Value.PathAll.
+func (n *Number) PathAll(path string) *Array {
+	return getPathAll(&n.chain, n.value, path)
+}
+
+// Pointer is similar to Value.Pointer.
+func (n *Number) Pointer(pointer string) *Value {
+	return getPointer(&n.chain, n.value, pointer)
+}
+
 // Schema is similar to Value.Schema.
 func (n *Number) Schema(schema interface{}) *Number {
 	checkSchema(&n.chain, n.value, schema)
@@ -58,7 +68,8 @@ func (n *Number) Equal(value interface{}) *Number {
 		return n
 	}
 	if !(n.value == v) {
-		n.chain.fail("\nexpected number equal to:\n %v\n\nbut got:\n %v",
+		n.chain.failExpected(v, n.value,
+			"\nexpected number equal to:\n %v\n\nbut got:\n %v",
 			v, n.value)
 	}
 	return n
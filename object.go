@@ -20,7 +20,7 @@ type Object struct {
 // Example:
 //  object := NewObject(t, map[string]interface{}{"foo": 123})
 func NewObject(reporter Reporter, value map[string]interface{}) *Object {
-	chain := makeChain(reporter)
+	chain := makeChain(reporter).root("Object").rootJSON()
 	if value == nil {
 		chain.fail("expected non-nil map value")
 	} else {
@@ -44,6 +44,16 @@ func (o *Object) Path(path string) *Value {
 	return getPath(&o.chain, o.value, path)
 }
 
+// PathAll is similar to Value.PathAll.
+func (o *Object) PathAll(path string) *Array {
+	return getPathAll(&o.chain, o.value, path)
+}
+
+// Pointer is similar to Value.Pointer.
+func (o *Object) Pointer(pointer string) *Value {
+	return getPointer(&o.chain, o.value, pointer)
+}
+
 // Schema is similar to Value.Schema.
 func (o *Object) Schema(schema interface{}) *Object {
 	checkSchema(&o.chain, o.value, schema)
@@ -60,7 +70,7 @@ func (o *Object) Keys() *Array {
 	for k := range o.value {
 		keys = append(keys, k)
 	}
-	return &Array{o.chain, keys}
+	return &Array{o.chain.enter("Keys()"), keys}
 }
 
 // Values returns a new Array object that may be used to inspect objects values.
@@ -73,7 +83,7 @@ func (o *Object) Values() *Array {
 	for _, v := range o.value {
 		values = append(values, v)
 	}
-	return &Array{o.chain, values}
+	return &Array{o.chain.enter("Values()"), values}
 }
 
 // Value returns a new Value object that may be used to inspect single value
@@ -87,9 +97,9 @@ func (o *Object) Value(key string) *Value {
 	if !ok {
 		o.chain.fail("\nexpected object containing key '%s', but got:\n%s",
 			key, dumpValue(o.value))
-		return &Value{o.chain, nil}
+		return &Value{o.chain.enter("Value(%q)", key).enterKey(key), nil}
 	}
-	return &Value{o.chain, value}
+	return &Value{o.chain.enter("Value(%q)", key).enterKey(key), value}
 }
 
 // Empty succeeds if object is empty.
@@ -124,7 +134,8 @@ func (o *Object) Equal(value interface{}) *Object {
 		return o
 	}
 	if !reflect.DeepEqual(expected, o.value) {
-		o.chain.fail("\nexpected object equal to:\n%s\n\nbut got:\n%s\n\ndiff:\n%s",
+		o.chain.failExpected(expected, o.value,
+			"\nexpected object equal to:\n%s\n\nbut got:\n%s\n\ndiff:\n%s",
 			dumpValue(expected),
 			dumpValue(o.value),
 			diffValues(expected, o.value))
@@ -252,12 +263,14 @@ func (o *Object) ValueEqual(key string, value interface{}) *Object {
 		return o
 	}
 	if !reflect.DeepEqual(expected, o.value[key]) {
-		o.chain.fail(
+		chain := o.chain.enterKey(key)
+		chain.failExpected(expected, o.value[key],
 			"\nexpected value for key '%s' equal to:\n%s\n\nbut got:\n%s\n\ndiff:\n%s",
 			key,
 			dumpValue(expected),
 			dumpValue(o.value[key]),
 			diffValues(expected, o.value[key]))
+		o.chain.failbit = chain.failbit
 	}
 	return o
 }
@@ -284,8 +297,10 @@ func (o *Object) ValueNotEqual(key string, value interface{}) *Object {
 		return o
 	}
 	if reflect.DeepEqual(expected, o.value[key]) {
-		o.chain.fail("\nexpected value for key '%s' not equal to:\n%s",
+		chain := o.chain.enterKey(key)
+		chain.fail("\nexpected value for key '%s' not equal to:\n%s",
 			key, dumpValue(expected))
+		o.chain.failbit = chain.failbit
 	}
 	return o
 }
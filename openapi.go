@@ -0,0 +1,704 @@
+package httpexpect
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v2"
+)
+
+// OpenAPI is an OpenAPI 3 document used to validate requests and responses
+// against the API contract.
+//
+// OpenAPI is attached to Config or to a single Request. When attached, every
+// request is matched against operations defined in the document by its method
+// and path. Path, query, and header parameters, and request body are validated
+// before the request is sent. Status code, headers, and body of the received
+// response are validated before returning Response. Mismatches are reported
+// as failures and include the operation ID.
+//
+// Schemas are validated using https://github.com/xeipuuv/gojsonschema.
+// Only local references ("#/components/...") are supported.
+//
+// Example:
+//  spec, err := httpexpect.LoadOpenAPI("testdata/openapi.yaml")
+//  if err != nil {
+//      t.Fatal(err)
+//  }
+//
+//  e := httpexpect.WithConfig(httpexpect.Config{
+//      BaseURL:  "http://example.com",
+//      Reporter: httpexpect.NewAssertReporter(t),
+//      OpenAPI:  spec,
+//  })
+type OpenAPI struct {
+	doc        map[string]interface{}
+	basePaths  []string
+	operations []*openAPIOperation
+
+	mu      sync.Mutex
+	schemas map[uintptr]*gojsonschema.Schema
+}
+
+type openAPIOperation struct {
+	spec       *OpenAPI
+	id         string
+	method     string
+	path       string
+	regexp     *regexp.Regexp
+	names      []string
+	parameters []openAPIParameter
+	body       map[string]interface{}
+	responses  map[string]interface{}
+}
+
+type openAPIParameter struct {
+	name     string
+	in       string
+	required bool
+	schema   map[string]interface{}
+}
+
+// LoadOpenAPI reads and parses OpenAPI 3 document from file.
+// See ParseOpenAPI.
+func LoadOpenAPI(filename string) (*OpenAPI, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseOpenAPI(data)
+}
+
+// ParseOpenAPI parses OpenAPI 3 document in JSON or YAML format.
+func ParseOpenAPI(data []byte) (*OpenAPI, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	doc, ok := canonYAML(raw).(map[string]interface{})
+	if !ok {
+		return nil, errors.New("OpenAPI document is not an object")
+	}
+
+	version, _ := doc["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q", version)
+	}
+
+	convertNullable(doc)
+
+	spec := &OpenAPI{
+		doc:     doc,
+		schemas: make(map[uintptr]*gojsonschema.Schema),
+	}
+
+	if err := spec.parseServers(); err != nil {
+		return nil, err
+	}
+	if err := spec.parsePaths(); err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+// canonYAML converts maps produced by YAML decoder to map[string]interface{},
+// so that the document has the same representation as decoded JSON.
+func canonYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[fmt.Sprint(key)] = canonYAML(val)
+		}
+		return m
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = canonYAML(val)
+		}
+		return v
+	case []interface{}:
+		for n, val := range v {
+			v[n] = canonYAML(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// convertNullable replaces OpenAPI "nullable" keyword with JSON Schema
+// equivalent, e.g. {"type": "string", "nullable": true} is converted to
+// {"type": ["string", "null"]}.
+func convertNullable(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if nullable, ok := v["nullable"].(bool); ok {
+			if typ, ok := v["type"].(string); ok && nullable {
+				v["type"] = []interface{}{typ, "null"}
+			}
+			delete(v, "nullable")
+		}
+		for _, val := range v {
+			convertNullable(val)
+		}
+	case []interface{}:
+		for _, val := range v {
+			convertNullable(val)
+		}
+	}
+}
+
+func (s *OpenAPI) parseServers() error {
+	servers, _ := s.doc["servers"].([]interface{})
+
+	for _, item := range servers {
+		server, _ := item.(map[string]interface{})
+		rawURL, _ := server["url"].(string)
+
+		variables, _ := server["variables"].(map[string]interface{})
+		for name, item := range variables {
+			variable, _ := item.(map[string]interface{})
+			value, _ := variable["default"].(string)
+			rawURL = strings.Replace(rawURL, "{"+name+"}", value, -1)
+		}
+
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return fmt.Errorf("invalid server url %q: %s", rawURL, err.Error())
+		}
+
+		basePath := strings.TrimSuffix(u.Path, "/")
+		if basePath != "" {
+			s.basePaths = append(s.basePaths, basePath)
+		}
+	}
+
+	return nil
+}
+
+var openAPIMethods = []string{
+	"get", "put", "post", "delete", "options", "head", "patch", "trace",
+}
+
+func (s *OpenAPI) parsePaths() error {
+	paths, _ := s.doc["paths"].(map[string]interface{})
+
+	for path, item := range paths {
+		pathItem, err := s.resolve(item)
+		if err != nil {
+			return err
+		}
+
+		re, names, err := compilePathTemplate(path)
+		if err != nil {
+			return err
+		}
+
+		common, err := s.parseParameters(pathItem["parameters"])
+		if err != nil {
+			return err
+		}
+
+		for _, method := range openAPIMethods {
+			item, ok := pathItem[method]
+			if !ok {
+				continue
+			}
+
+			operation, _ := item.(map[string]interface{})
+
+			own, err := s.parseParameters(operation["parameters"])
+			if err != nil {
+				return err
+			}
+
+			op := &openAPIOperation{
+				spec:       s,
+				method:     strings.ToUpper(method),
+				path:       path,
+				regexp:     re,
+				names:      names,
+				parameters: mergeParameters(common, own),
+			}
+
+			op.id, _ = operation["operationId"].(string)
+
+			if body, ok := operation["requestBody"]; ok {
+				if op.body, err = s.resolve(body); err != nil {
+					return err
+				}
+			}
+
+			op.responses, _ = operation["responses"].(map[string]interface{})
+
+			s.operations = append(s.operations, op)
+		}
+	}
+
+	// concrete paths should be matched before templated ones
+	sort.SliceStable(s.operations, func(i, j int) bool {
+		a, b := s.operations[i], s.operations[j]
+		if len(a.names) != len(b.names) {
+			return len(a.names) < len(b.names)
+		}
+		return a.path < b.path
+	})
+
+	return nil
+}
+
+func (s *OpenAPI) parseParameters(value interface{}) ([]openAPIParameter, error) {
+	items, _ := value.([]interface{})
+
+	var params []openAPIParameter
+
+	for _, item := range items {
+		param, err := s.resolve(item)
+		if err != nil {
+			return nil, err
+		}
+
+		p := openAPIParameter{}
+		p.name, _ = param["name"].(string)
+		p.in, _ = param["in"].(string)
+		p.required, _ = param["required"].(bool)
+		p.schema, _ = param["schema"].(map[string]interface{})
+
+		if p.in == "path" {
+			p.required = true
+		}
+
+		params = append(params, p)
+	}
+
+	return params, nil
+}
+
+func mergeParameters(common, own []openAPIParameter) []openAPIParameter {
+	params := append([]openAPIParameter(nil), own...)
+
+	for _, p := range common {
+		overridden := false
+		for _, o := range own {
+			if o.name == p.name && o.in == p.in {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			params = append(params, p)
+		}
+	}
+
+	return params
+}
+
+var pathTemplateRegexp = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+func compilePathTemplate(path string) (*regexp.Regexp, []string, error) {
+	var (
+		pattern strings.Builder
+		names   []string
+	)
+
+	pattern.WriteString("^")
+
+	last := 0
+	for _, m := range pathTemplateRegexp.FindAllStringSubmatchIndex(path, -1) {
+		pattern.WriteString(regexp.QuoteMeta(path[last:m[0]]))
+		pattern.WriteString("([^/]+)")
+		names = append(names, path[m[2]:m[3]])
+		last = m[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(path[last:]))
+
+	pattern.WriteString("$")
+
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid path %q: %s", path, err.Error())
+	}
+
+	return re, names, nil
+}
+
+// resolve follows local reference, if value is a reference object,
+// and returns referenced object.
+func (s *OpenAPI) resolve(value interface{}) (map[string]interface{}, error) {
+	for n := 0; n < 32; n++ {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected object, but got %v", value)
+		}
+
+		ref, ok := obj["$ref"].(string)
+		if !ok {
+			return obj, nil
+		}
+
+		if !strings.HasPrefix(ref, "#/") {
+			return nil, fmt.Errorf("unsupported reference %q", ref)
+		}
+
+		value = s.doc
+		for _, token := range strings.Split(ref[2:], "/") {
+			if unescaped, err := url.PathUnescape(token); err == nil {
+				token = unescaped
+			}
+			token = strings.Replace(token, "~1", "/", -1)
+			token = strings.Replace(token, "~0", "~", -1)
+
+			container, _ := value.(map[string]interface{})
+			if value, ok = container[token]; !ok {
+				return nil, fmt.Errorf("unresolved reference %q", ref)
+			}
+		}
+	}
+
+	return nil, errors.New("too many nested references")
+}
+
+// findOperation returns operation matching request method and path,
+// or nil if there is no such operation.
+func (s *OpenAPI) findOperation(method, path string) (*openAPIOperation, []string) {
+	paths := []string{path}
+	for _, basePath := range s.basePaths {
+		if strings.HasPrefix(path, basePath+"/") {
+			paths = append(paths, strings.TrimPrefix(path, basePath))
+		}
+	}
+
+	for _, op := range s.operations {
+		if op.method != method {
+			continue
+		}
+		for _, p := range paths {
+			if m := op.regexp.FindStringSubmatch(p); m != nil {
+				return op, m[1:]
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func (op *openAPIOperation) String() string {
+	if op.id != "" {
+		return fmt.Sprintf("'%s' (%s %s)", op.id, op.method, op.path)
+	}
+	return fmt.Sprintf("%s %s", op.method, op.path)
+}
+
+// validateRequest validates request parameters and body and returns
+// a list of found mismatches. If body is nil, it's not validated.
+func (op *openAPIOperation) validateRequest(
+	req *http.Request, pathValues []string, body []byte,
+) []string {
+	var errs []string
+
+	query := req.URL.Query()
+
+	for _, param := range op.parameters {
+		var values []string
+
+		switch param.in {
+		case "path":
+			for n, name := range op.names {
+				if name == param.name && n < len(pathValues) {
+					if v, err := url.PathUnescape(pathValues[n]); err == nil {
+						values = []string{v}
+					} else {
+						values = []string{pathValues[n]}
+					}
+				}
+			}
+		case "query":
+			values = query[param.name]
+		case "header":
+			values = req.Header[http.CanonicalHeaderKey(param.name)]
+		case "cookie":
+			if c, err := req.Cookie(param.name); err == nil {
+				values = []string{c.Value}
+			}
+		}
+
+		if len(values) == 0 {
+			if param.required {
+				errs = append(errs, fmt.Sprintf(
+					"missing required %s parameter '%s'", param.in, param.name))
+			}
+			continue
+		}
+
+		if param.schema == nil {
+			continue
+		}
+
+		value := op.spec.convertParameter(param.schema, values)
+
+		for _, err := range op.spec.validateSchema(param.schema, value) {
+			errs = append(errs, fmt.Sprintf(
+				"%s parameter '%s': %s", param.in, param.name, err))
+		}
+	}
+
+	if op.body != nil && body != nil {
+		errs = append(errs, op.validateBody("request",
+			op.body, req.Header.Get("Content-Type"), body)...)
+	}
+
+	return errs
+}
+
+// validateResponse validates response status, headers, and body and
+// returns a list of found mismatches.
+func (op *openAPIOperation) validateResponse(
+	resp *http.Response, body []byte,
+) []string {
+	code := strconv.Itoa(resp.StatusCode)
+
+	item, ok := op.responses[code]
+	if !ok {
+		item, ok = op.responses[code[:1]+"XX"]
+	}
+	if !ok {
+		item, ok = op.responses["default"]
+	}
+	if !ok {
+		return []string{fmt.Sprintf("unexpected response status %d", resp.StatusCode)}
+	}
+
+	response, err := op.spec.resolve(item)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var errs []string
+
+	headers, _ := response["headers"].(map[string]interface{})
+	for name, item := range headers {
+		header, err := op.spec.resolve(item)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		values := resp.Header[http.CanonicalHeaderKey(name)]
+
+		if len(values) == 0 {
+			if required, _ := header["required"].(bool); required {
+				errs = append(errs, fmt.Sprintf(
+					"missing required response header '%s'", name))
+			}
+			continue
+		}
+
+		schema, _ := header["schema"].(map[string]interface{})
+		if schema == nil {
+			continue
+		}
+
+		value := op.spec.convertParameter(schema, values)
+
+		for _, err := range op.spec.validateSchema(schema, value) {
+			errs = append(errs, fmt.Sprintf("response header '%s': %s", name, err))
+		}
+	}
+
+	errs = append(errs, op.validateBody("response",
+		response, resp.Header.Get("Content-Type"), body)...)
+
+	return errs
+}
+
+// validateBody validates body against content defined in request body
+// or response object.
+func (op *openAPIOperation) validateBody(
+	what string, object map[string]interface{}, contentType string, body []byte,
+) []string {
+	content, _ := object["content"].(map[string]interface{})
+
+	if len(body) == 0 {
+		if required, _ := object["required"].(bool); required {
+			return []string{fmt.Sprintf("missing required %s body", what)}
+		}
+		return nil
+	}
+
+	if len(content) == 0 {
+		return nil
+	}
+
+	mediaType, media := matchMediaType(content, contentType)
+	if media == nil {
+		types := make([]string, 0, len(content))
+		for t := range content {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		return []string{fmt.Sprintf(
+			"unexpected %s content type %q, expected one of: %s",
+			what, contentType, strings.Join(types, ", "))}
+	}
+
+	schema, _ := media["schema"].(map[string]interface{})
+	if schema == nil || !isJSONMediaType(mediaType) {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []string{fmt.Sprintf("invalid %s body: %s", what, err.Error())}
+	}
+
+	var errs []string
+	for _, err := range op.spec.validateSchema(schema, value) {
+		errs = append(errs, fmt.Sprintf("%s body: %s", what, err))
+	}
+
+	return errs
+}
+
+func matchMediaType(
+	content map[string]interface{}, contentType string,
+) (string, map[string]interface{}) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = ""
+	}
+
+	candidates := []string{mediaType}
+	if i := strings.Index(mediaType, "/"); i > 0 {
+		candidates = append(candidates, mediaType[:i]+"/*")
+	}
+	candidates = append(candidates, "*/*")
+
+	for _, candidate := range candidates {
+		for key, item := range content {
+			if strings.EqualFold(key, candidate) {
+				media, _ := item.(map[string]interface{})
+				if media == nil {
+					media = map[string]interface{}{}
+				}
+				return mediaType, media
+			}
+		}
+	}
+
+	return mediaType, nil
+}
+
+// convertParameter converts parameter values from strings to types defined
+// by parameter schema. Values that can't be converted are left as strings,
+// so that schema validation reports them.
+func (s *OpenAPI) convertParameter(
+	schema map[string]interface{}, values []string,
+) interface{} {
+	resolved, err := s.resolve(schema)
+	if err != nil {
+		return values[0]
+	}
+
+	typ, _ := resolved["type"].(string)
+	if types, ok := resolved["type"].([]interface{}); ok && len(types) != 0 {
+		typ, _ = types[0].(string)
+	}
+
+	switch typ {
+	case "array":
+		if len(values) == 1 {
+			values = strings.Split(values[0], ",")
+		}
+		items, _ := resolved["items"].(map[string]interface{})
+		ret := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			if items != nil {
+				ret = append(ret, s.convertParameter(items, []string{v}))
+			} else {
+				ret = append(ret, v)
+			}
+		}
+		return ret
+
+	case "integer", "number":
+		if f, err := strconv.ParseFloat(values[0], 64); err == nil {
+			return f
+		}
+
+	case "boolean":
+		if b, err := strconv.ParseBool(values[0]); err == nil {
+			return b
+		}
+	}
+
+	return values[0]
+}
+
+// validateSchema validates value against schema from the document and returns
+// a list of validation errors.
+func (s *OpenAPI) validateSchema(
+	schema map[string]interface{}, value interface{},
+) []string {
+	compiled, err := s.compileSchema(schema)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	result, err := compiled.Validate(gojsonschema.NewGoLoader(value))
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var errs []string
+	for _, err := range result.Errors() {
+		errs = append(errs, err.String())
+	}
+
+	return errs
+}
+
+func (s *OpenAPI) compileSchema(
+	schema map[string]interface{},
+) (*gojsonschema.Schema, error) {
+	key := reflect.ValueOf(schema).Pointer()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if compiled, ok := s.schemas[key]; ok {
+		return compiled, nil
+	}
+
+	// references are resolved relative to the schema root, so the schema
+	// is wrapped into a document containing components from the spec
+	root := make(map[string]interface{}, len(schema)+1)
+	for k, v := range schema {
+		root[k] = v
+	}
+	if components, ok := s.doc["components"]; ok {
+		root["components"] = components
+	}
+
+	compiled, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(root))
+	if err != nil {
+		return nil, err
+	}
+
+	s.schemas[key] = compiled
+
+	return compiled, nil
+}
@@ -0,0 +1,433 @@
+package httpexpect
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOpenAPI = `
+openapi: 3.0.0
+info:
+  title: Users
+  version: "1.0"
+servers:
+  - url: http://example.com/{version}
+    variables:
+      version:
+        default: v1
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            maximum: 100
+        - name: tags
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+      responses:
+        200:
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/User'
+    post:
+      operationId: createUser
+      parameters:
+        - $ref: '#/components/parameters/RequestID'
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/User'
+      responses:
+        201:
+          description: Created
+          headers:
+            Location:
+              required: true
+              schema:
+                type: string
+        4XX:
+          description: Error
+  /users/me:
+    get:
+      operationId: getMe
+      responses:
+        200:
+          description: OK
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        schema:
+          type: integer
+    get:
+      operationId: getUser
+      responses:
+        200:
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+        default:
+          description: Error
+          content:
+            text/plain: {}
+components:
+  parameters:
+    RequestID:
+      name: X-Request-ID
+      in: header
+      required: true
+      schema:
+        type: string
+  schemas:
+    User:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+        email:
+          type: string
+          nullable: true
+`
+
+type openAPIHandler struct {
+	status  int
+	headers map[string]string
+	body    interface{}
+}
+
+func (h *openAPIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for k, v := range h.headers {
+		w.Header().Set(k, v)
+	}
+	if h.body != nil {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(h.status)
+	if h.body != nil {
+		b, _ := json.Marshal(h.body)
+		_, _ = w.Write(b)
+	}
+}
+
+func TestOpenAPIParse(t *testing.T) {
+	spec, err := ParseOpenAPI([]byte(testOpenAPI))
+	require.Nil(t, err)
+
+	assert.Equal(t, []string{"/v1"}, spec.basePaths)
+	assert.Equal(t, 4, len(spec.operations))
+
+	op, values := spec.findOperation("GET", "/v1/users/me")
+	require.NotNil(t, op)
+	assert.Equal(t, "getMe", op.id)
+	assert.Equal(t, 0, len(values))
+
+	op, values = spec.findOperation("GET", "/v1/users/123")
+	require.NotNil(t, op)
+	assert.Equal(t, "getUser", op.id)
+	assert.Equal(t, []string{"123"}, values)
+
+	op, _ = spec.findOperation("GET", "/users/123")
+	require.NotNil(t, op)
+	assert.Equal(t, "getUser", op.id)
+
+	op, _ = spec.findOperation("DELETE", "/v1/users/123")
+	assert.Nil(t, op)
+
+	op, _ = spec.findOperation("GET", "/v1/users/123/posts")
+	assert.Nil(t, op)
+
+	spec, err = ParseOpenAPI([]byte(`{"openapi": "3.0.1", "paths": {}}`))
+	assert.Nil(t, err)
+	assert.NotNil(t, spec)
+
+	_, err = ParseOpenAPI([]byte(`{"swagger": "2.0"}`))
+	assert.NotNil(t, err)
+
+	_, err = ParseOpenAPI([]byte(`[1, 2, 3]`))
+	assert.NotNil(t, err)
+
+	_, err = ParseOpenAPI([]byte(`
+openapi: 3.0.0
+paths:
+  /users:
+    get:
+      parameters:
+        - $ref: '#/components/parameters/Missing'
+`))
+	assert.NotNil(t, err)
+}
+
+func TestOpenAPILoad(t *testing.T) {
+	f, err := ioutil.TempFile("", "httpexpect")
+	require.Nil(t, err)
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(testOpenAPI)
+	require.Nil(t, err)
+	require.Nil(t, f.Close())
+
+	spec, err := LoadOpenAPI(f.Name())
+	assert.Nil(t, err)
+	assert.NotNil(t, spec)
+
+	_, err = LoadOpenAPI(f.Name() + ".missing")
+	assert.NotNil(t, err)
+}
+
+func TestOpenAPIRequest(t *testing.T) {
+	handler := &openAPIHandler{
+		status: http.StatusOK,
+		body:   []interface{}{},
+	}
+
+	spec, err := ParseOpenAPI([]byte(testOpenAPI))
+	require.Nil(t, err)
+
+	config := newBinderConfig(t, handler)
+	config.BaseURL = "http://example.com/v1"
+	config.OpenAPI = spec
+
+	resp1 := NewRequest(config, "GET", "/users").
+		WithQuery("limit", 10).
+		WithQuery("tags", "a,b").
+		Expect()
+	resp1.chain.assertOK(t)
+
+	resp2 := NewRequest(config, "GET", "/users").
+		WithQuery("limit", 1000).
+		Expect()
+	resp2.chain.assertFailed(t)
+
+	resp3 := NewRequest(config, "GET", "/users").
+		WithQuery("limit", "ten").
+		Expect()
+	resp3.chain.assertFailed(t)
+
+	resp4 := NewRequest(config, "GET", "/users/{id}", "abc").Expect()
+	resp4.chain.assertFailed(t)
+
+	resp5 := NewRequest(config, "GET", "/unknown").Expect()
+	resp5.chain.assertFailed(t)
+
+	resp6 := NewRequest(config, "GET", "/unknown").WithOpenAPI(nil).Expect()
+	resp6.chain.assertOK(t)
+}
+
+func TestOpenAPIRequestBody(t *testing.T) {
+	handler := &openAPIHandler{
+		status:  http.StatusCreated,
+		headers: map[string]string{"Location": "/users/1"},
+	}
+
+	spec, err := ParseOpenAPI([]byte(testOpenAPI))
+	require.Nil(t, err)
+
+	config := newBinderConfig(t, handler)
+	config.BaseURL = "http://example.com/v1"
+	config.OpenAPI = spec
+
+	resp1 := NewRequest(config, "POST", "/users").
+		WithHeader("X-Request-ID", "1").
+		WithJSON(map[string]interface{}{"id": 1, "name": "john", "email": nil}).
+		Expect()
+	resp1.chain.assertOK(t)
+
+	resp2 := NewRequest(config, "POST", "/users").
+		WithJSON(map[string]interface{}{"id": 1, "name": "john"}).
+		Expect()
+	resp2.chain.assertFailed(t)
+
+	resp3 := NewRequest(config, "POST", "/users").
+		WithHeader("X-Request-ID", "1").
+		WithJSON(map[string]interface{}{"id": "1"}).
+		Expect()
+	resp3.chain.assertFailed(t)
+
+	resp4 := NewRequest(config, "POST", "/users").
+		WithHeader("X-Request-ID", "1").
+		Expect()
+	resp4.chain.assertFailed(t)
+
+	resp5 := NewRequest(config, "POST", "/users").
+		WithHeader("X-Request-ID", "1").
+		WithText("hello").
+		Expect()
+	resp5.chain.assertFailed(t)
+}
+
+func TestOpenAPIResponse(t *testing.T) {
+	handler := &openAPIHandler{
+		status: http.StatusOK,
+		body:   map[string]interface{}{"id": 1, "name": "john"},
+	}
+
+	spec, err := ParseOpenAPI([]byte(testOpenAPI))
+	require.Nil(t, err)
+
+	config := newBinderConfig(t, handler)
+	config.BaseURL = "http://example.com/v1"
+	config.OpenAPI = spec
+
+	resp1 := NewRequest(config, "GET", "/users/1").Expect()
+	resp1.chain.assertOK(t)
+
+	handler.body = map[string]interface{}{"id": 1}
+
+	resp2 := NewRequest(config, "GET", "/users/1").Expect()
+	resp2.chain.assertFailed(t)
+
+	handler.status = http.StatusNotFound
+	handler.body = nil
+
+	resp3 := NewRequest(config, "GET", "/users/1").Expect()
+	resp3.chain.assertOK(t)
+
+	handler.status = http.StatusTeapot
+
+	resp4 := NewRequest(config, "POST", "/users").
+		WithHeader("X-Request-ID", "1").
+		WithJSON(map[string]interface{}{"id": 1, "name": "john"}).
+		Expect()
+	resp4.chain.assertOK(t)
+
+	handler.status = http.StatusCreated
+
+	resp5 := NewRequest(config, "POST", "/users").
+		WithHeader("X-Request-ID", "1").
+		WithJSON(map[string]interface{}{"id": 1, "name": "john"}).
+		Expect()
+	resp5.chain.assertFailed(t)
+
+	handler.status = http.StatusOK
+
+	resp6 := NewRequest(config, "POST", "/users").
+		WithHeader("X-Request-ID", "1").
+		WithJSON(map[string]interface{}{"id": 1, "name": "john"}).
+		Expect()
+	resp6.chain.assertFailed(t)
+}
+
+func TestOpenAPIFailure(t *testing.T) {
+	handler := &openAPIHandler{
+		status: http.StatusOK,
+		body:   map[string]interface{}{"id": "1", "name": "john"},
+	}
+
+	spec, err := ParseOpenAPI([]byte(testOpenAPI))
+	require.Nil(t, err)
+
+	config := newBinderConfig(t, handler)
+	config.BaseURL = "http://example.com/v1"
+	config.OpenAPI = spec
+
+	reporter := newMockFailureReporter(t)
+	config.Reporter = reporter
+
+	NewRequest(config, "GET", "/users/1").Expect()
+
+	require.Equal(t, 1, len(reporter.failures))
+
+	assert.Contains(t, reporter.failures[0].Message, "getUser")
+	assert.Contains(t, reporter.failures[0].Message, "GET /users/{id}")
+	assert.Contains(t, reporter.failures[0].Message, "response body")
+}
+
+func TestOpenAPIAuthenticator(t *testing.T) {
+	const testAuthOpenAPI = `
+openapi: 3.0.0
+info:
+  title: Keys
+  version: "1.0"
+paths:
+  /header:
+    get:
+      parameters:
+        - name: X-API-Key
+          in: header
+          required: true
+          schema:
+            type: string
+      responses:
+        200:
+          description: OK
+  /query:
+    get:
+      parameters:
+        - name: api_key
+          in: query
+          required: true
+          schema:
+            type: string
+      responses:
+        200:
+          description: OK
+`
+
+	handler := &openAPIHandler{
+		status: http.StatusOK,
+	}
+
+	spec, err := ParseOpenAPI([]byte(testAuthOpenAPI))
+	require.Nil(t, err)
+
+	config := newBinderConfig(t, handler)
+	config.OpenAPI = spec
+
+	resp1 := NewRequest(config, "GET", "/header").
+		WithAuthenticator(NewAPIKeyAuthenticator(APIKeyInHeader, "X-API-Key", "secret")).
+		Expect()
+	resp1.chain.assertOK(t)
+
+	resp2 := NewRequest(config, "GET", "/query").
+		WithAuthenticator(NewAPIKeyAuthenticator(APIKeyInQuery, "api_key", "secret")).
+		Expect()
+	resp2.chain.assertOK(t)
+
+	resp3 := NewRequest(config, "GET", "/header").Expect()
+	resp3.chain.assertFailed(t)
+}
+
+func TestOpenAPICompressedRequest(t *testing.T) {
+	handler := &openAPIHandler{
+		status:  http.StatusCreated,
+		headers: map[string]string{"Location": "/users/1"},
+	}
+
+	spec, err := ParseOpenAPI([]byte(testOpenAPI))
+	require.Nil(t, err)
+
+	config := newBinderConfig(t, handler)
+	config.BaseURL = "http://example.com/v1"
+	config.OpenAPI = spec
+
+	resp := NewRequest(config, "POST", "/users").
+		WithHeader("X-Request-ID", "1").
+		WithCompression("gzip").
+		WithJSON(map[string]interface{}{"id": 1, "name": "john"}).
+		Expect()
+	resp.chain.assertOK(t)
+}
@@ -0,0 +1,265 @@
+package httpexpect
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Paginator defines how Request.Paginate extracts items from every page
+// and finds the next page.
+//
+// LinkPaginator, CursorPaginator, PagePaginator, and OffsetPaginator
+// implement this interface.
+type Paginator interface {
+	// Page is invoked for every received page. It returns items of the
+	// page and URL of the next page, or nil URL if the page is the last one.
+	Page(page *Page) (items []interface{}, next *url.URL, err error)
+}
+
+// Page describes a page received by Request.Paginate.
+type Page struct {
+	// Index is zero-based number of the page.
+	Index int
+
+	// URL is the URL from which the page was received.
+	URL *url.URL
+
+	// Header is the header of the page response.
+	Header http.Header
+
+	// Body is the decoded JSON body of the page response.
+	Body interface{}
+}
+
+// LinkPaginator follows links with "next" relation type from Link header,
+// as defined in RFC 8288. Pagination stops when there is no such link.
+type LinkPaginator struct {
+	items string
+}
+
+// NewLinkPaginator returns a new LinkPaginator.
+//
+// items is a JSONPath expression that selects array of items in every
+// page body. If it's empty, page body itself should be an array.
+//
+// Example:
+//  req.Paginate(NewLinkPaginator("$.data"))
+func NewLinkPaginator(items string) *LinkPaginator {
+	return &LinkPaginator{items: items}
+}
+
+// Page implements Paginator.Page.
+func (p *LinkPaginator) Page(page *Page) ([]interface{}, *url.URL, error) {
+	items, err := pageItems(page, p.items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	links, err := parseLinkHeader(page.Header, page.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, link := range links {
+		for _, rel := range link.rels() {
+			if rel == "next" {
+				next, err := url.Parse(link.target)
+				return items, next, err
+			}
+		}
+	}
+
+	return items, nil, nil
+}
+
+// CursorPaginator passes cursor token from every page body to the next
+// page request as a query parameter. Pagination stops when page body
+// has no cursor, or when it's null or empty.
+type CursorPaginator struct {
+	items  string
+	cursor string
+	param  string
+	query  *jsonPath
+	err    error
+}
+
+// NewCursorPaginator returns a new CursorPaginator.
+//
+// items is a JSONPath expression that selects array of items in every
+// page body. If it's empty, page body itself should be an array. cursor
+// is a JSONPath expression that selects next cursor in page body, and
+// param is the name of query parameter to which cursor is assigned.
+// If cursor is not a valid JSONPath expression, pagination fails on the
+// first page.
+//
+// Example:
+//  req.Paginate(NewCursorPaginator("$.data", "$.meta.next_cursor", "cursor"))
+func NewCursorPaginator(items, cursor, param string) *CursorPaginator {
+	query, err := parseJSONPath(cursor)
+	return &CursorPaginator{
+		items:  items,
+		cursor: cursor,
+		param:  param,
+		query:  query,
+		err:    err,
+	}
+}
+
+// Page implements Paginator.Page.
+func (p *CursorPaginator) Page(page *Page) ([]interface{}, *url.URL, error) {
+	if p.err != nil {
+		return nil, nil, fmt.Errorf("invalid cursor path %q: %s", p.cursor, p.err.Error())
+	}
+
+	items, err := pageItems(page, p.items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodes := p.query.eval(page.Body, page.Body)
+	switch len(nodes) {
+	case 0:
+		// cursor is usually omitted from the last page
+		return items, nil, nil
+	case 1:
+	default:
+		return nil, nil, fmt.Errorf(
+			"expected single cursor at %q, but got %d values", p.cursor, len(nodes))
+	}
+
+	value := nodes[0]
+
+	var cursor string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		cursor = v
+	case float64:
+		cursor = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil, nil, fmt.Errorf(
+			"expected string or number cursor at %q, but got %T", p.cursor, value)
+	}
+
+	if cursor == "" {
+		return items, nil, nil
+	}
+
+	return items, withQueryParam(page.URL, p.param, cursor), nil
+}
+
+// PagePaginator increments page number query parameter. Pagination starts
+// from page number from request URL, or from 1 if it's missing, and stops
+// when a page has no items.
+type PagePaginator struct {
+	items string
+	param string
+}
+
+// NewPagePaginator returns a new PagePaginator.
+//
+// items is a JSONPath expression that selects array of items in every
+// page body. If it's empty, page body itself should be an array. param
+// is the name of query parameter with page number.
+//
+// Example:
+//  req.WithQuery("per_page", 50).Paginate(NewPagePaginator("", "page"))
+func NewPagePaginator(items, param string) *PagePaginator {
+	return &PagePaginator{items: items, param: param}
+}
+
+// Page implements Paginator.Page.
+func (p *PagePaginator) Page(page *Page) ([]interface{}, *url.URL, error) {
+	items, err := pageItems(page, p.items)
+	if err != nil || len(items) == 0 {
+		return items, nil, err
+	}
+
+	number, err := queryParamInt(page.URL, p.param, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return items, withQueryParam(page.URL, p.param, strconv.Itoa(number+1)), nil
+}
+
+// OffsetPaginator increases offset query parameter by the number of items
+// in every page. Pagination starts from offset from request URL, or from
+// 0 if it's missing, and stops when a page has no items.
+type OffsetPaginator struct {
+	items string
+	param string
+}
+
+// NewOffsetPaginator returns a new OffsetPaginator.
+//
+// items is a JSONPath expression that selects array of items in every
+// page body. If it's empty, page body itself should be an array. param
+// is the name of query parameter with offset.
+//
+// Example:
+//  req.WithQuery("limit", 100).Paginate(NewOffsetPaginator("$.items", "offset"))
+func NewOffsetPaginator(items, param string) *OffsetPaginator {
+	return &OffsetPaginator{items: items, param: param}
+}
+
+// Page implements Paginator.Page.
+func (p *OffsetPaginator) Page(page *Page) ([]interface{}, *url.URL, error) {
+	items, err := pageItems(page, p.items)
+	if err != nil || len(items) == 0 {
+		return items, nil, err
+	}
+
+	offset, err := queryParamInt(page.URL, p.param, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next := strconv.Itoa(offset + len(items))
+	return items, withQueryParam(page.URL, p.param, next), nil
+}
+
+func pageItems(page *Page, path string) ([]interface{}, error) {
+	value := page.Body
+	if path != "" {
+		var err error
+		if value, err = evalJSONPath(page.Body, path); err != nil {
+			return nil, err
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		return v, nil
+	default:
+		if path == "" {
+			path = "$"
+		}
+		return nil, fmt.Errorf("expected array of items at %q, but got %T", path, value)
+	}
+}
+
+func queryParamInt(u *url.URL, param string, def int) (int, error) {
+	value := u.Query().Get(param)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("expected integer %q query parameter, but got %q",
+			param, value)
+	}
+	return n, nil
+}
+
+func withQueryParam(u *url.URL, param, value string) *url.URL {
+	ret := *u
+	query := ret.Query()
+	query.Set(param, value)
+	ret.RawQuery = query.Encode()
+	return &ret
+}
@@ -0,0 +1,285 @@
+package httpexpect
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newPaginatedHandler(t *testing.T, style string, total, size int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		start := 0
+		switch style {
+		case "link", "page":
+			if page := query.Get("page"); page != "" {
+				n, _ := strconv.Atoi(page)
+				start = (n - 1) * size
+			}
+		case "cursor":
+			if cursor := query.Get("cursor"); cursor != "" {
+				start, _ = strconv.Atoi(strings.TrimPrefix(cursor, "c"))
+			}
+		case "offset":
+			start, _ = strconv.Atoi(query.Get("offset"))
+		}
+
+		items := []interface{}{}
+		for n := start; n < start+size && n < total; n++ {
+			items = append(items, n)
+		}
+
+		body := map[string]interface{}{
+			"data": items,
+		}
+
+		switch style {
+		case "link":
+			if start+size < total {
+				w.Header().Set("Link",
+					`<?page=`+strconv.Itoa(start/size+2)+`>; rel="next"`)
+			}
+		case "cursor":
+			if start+size < total {
+				body["meta"] = map[string]interface{}{
+					"next": "c" + strconv.Itoa(start+size),
+				}
+			} else {
+				body["meta"] = map[string]interface{}{
+					"next": nil,
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(body))
+	})
+}
+
+func TestPaginate(t *testing.T) {
+	expected := []interface{}{}
+	for n := 0; n < 10; n++ {
+		expected = append(expected, n)
+	}
+
+	cases := []struct {
+		style     string
+		paginator Paginator
+		pages     int
+	}{
+		{"link", NewLinkPaginator("$.data"), 4},
+		{"cursor", NewCursorPaginator("$.data", "$.meta.next", "cursor"), 4},
+		{"page", NewPagePaginator("$.data", "page"), 5},
+		{"offset", NewOffsetPaginator("$.data", "offset"), 5},
+	}
+
+	for _, tc := range cases {
+		pages := 0
+		pageMatches := 0
+
+		e := WithConfig(Config{
+			BaseURL:  "http://example.com",
+			Reporter: NewAssertReporter(t),
+			Client: &http.Client{
+				Transport: NewBinder(newPaginatedHandler(t, tc.style, 10, 3)),
+			},
+		}).Matcher(func(resp *Response) {
+			pages++
+			resp.Status(http.StatusOK)
+		})
+
+		items := e.GET("/items").
+			WithHeader("Accept", "application/json").
+			Paginate(tc.paginator, func(page *Response) {
+				pageMatches++
+				page.JSON().Path("$.data").Array().Length().Le(3)
+			})
+
+		items.chain.assertOK(t)
+		items.Equal(expected)
+
+		assert.Equal(t, tc.pages, pages, tc.style)
+		assert.Equal(t, tc.pages, pageMatches, tc.style)
+	}
+}
+
+func TestPaginateFailures(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/loop":
+			w.Header().Set("Link", `</loop>; rel="next"`)
+			_, _ = w.Write([]byte(`[1]`))
+		case "/object":
+			_, _ = w.Write([]byte(`{"data": {}}`))
+		case "/text":
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte(`hello`))
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`[]`))
+		}
+	})
+
+	config := Config{
+		BaseURL:        "http://example.com",
+		RequestFactory: DefaultRequestFactory{},
+		Reporter:       newMockReporter(t),
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+	}
+
+	NewRequest(config, "GET", "/loop").
+		Paginate(NewLinkPaginator("")).
+		chain.assertFailed(t)
+
+	NewRequest(config, "GET", "/object").
+		Paginate(NewLinkPaginator("$.data")).
+		chain.assertFailed(t)
+
+	NewRequest(config, "GET", "/text").
+		Paginate(NewLinkPaginator("")).
+		chain.assertFailed(t)
+
+	NewRequest(config, "GET", "/missing").
+		WithMatcher(func(resp *Response) {
+			resp.Status(http.StatusOK)
+		}).
+		Paginate(NewLinkPaginator("")).
+		chain.assertFailed(t)
+
+	NewRequest(config, "GET", "/missing").
+		Paginate(nil).
+		chain.assertFailed(t)
+
+	NewRequest(config, "GET", "/loop").
+		Paginate(NewLinkPaginator(""), func(page *Response) {
+			page.JSON().Array().Empty()
+		}).
+		chain.assertFailed(t)
+
+	NewRequest(config, "GET", "/object").
+		Paginate(NewCursorPaginator("$.data", `$.meta[`, "cursor")).
+		chain.assertFailed(t)
+
+	NewRequest(config, "GET", "/loop").
+		WithQuery("page", "x").
+		Paginate(NewPagePaginator("", "page")).
+		chain.assertFailed(t)
+}
+
+func TestPaginateOpenAPI(t *testing.T) {
+	spec, err := ParseOpenAPI([]byte(`
+openapi: 3.0.0
+paths:
+  /numbers:
+    get:
+      responses:
+        200:
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: integer
+  /strings:
+    get:
+      responses:
+        200:
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: string
+`))
+	assert.NoError(t, err)
+
+	next := "/strings"
+	body := `["a"]`
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/numbers":
+			w.Header().Set("Link", "<"+next+`>; rel="next"`)
+			_, _ = w.Write([]byte(`[1]`))
+		case "/strings":
+			_, _ = w.Write([]byte(body))
+		}
+	})
+
+	config := newBinderConfig(t, handler)
+	config.OpenAPI = spec
+
+	items := NewRequest(config, "GET", "/numbers").
+		Paginate(NewLinkPaginator(""))
+	items.chain.assertOK(t)
+	items.Equal([]interface{}{1, "a"})
+
+	body = `[2]`
+
+	NewRequest(config, "GET", "/numbers").
+		Paginate(NewLinkPaginator("")).
+		chain.assertFailed(t)
+
+	next = "/unknown"
+
+	NewRequest(config, "GET", "/numbers").
+		Paginate(NewLinkPaginator("")).
+		chain.assertFailed(t)
+}
+
+func TestPaginateCursor(t *testing.T) {
+	paginator := NewCursorPaginator("$.data", "$.next", "cursor")
+
+	page := &Page{
+		URL:  mustParseURL("http://example.com/items?limit=2"),
+		Body: map[string]interface{}{"data": []interface{}{1.0}, "next": 123.0},
+	}
+
+	items, next, err := paginator.Page(page)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{1.0}, items)
+	assert.Equal(t, "http://example.com/items?cursor=123&limit=2", next.String())
+
+	page.Body = map[string]interface{}{"data": []interface{}{}, "next": ""}
+
+	_, next, err = paginator.Page(page)
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+
+	page.Body = map[string]interface{}{"data": []interface{}{}}
+
+	_, next, err = paginator.Page(page)
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+
+	page.Body = map[string]interface{}{"data": []interface{}{}, "next": true}
+
+	_, _, err = paginator.Page(page)
+	assert.Error(t, err)
+
+	page.Body = map[string]interface{}{"data": []interface{}{}, "next": "c1"}
+
+	_, _, err = NewCursorPaginator("$.data", `$.meta[`, "cursor").Page(page)
+	assert.Error(t, err)
+
+	_, _, err = NewCursorPaginator("$.data", `$..next`, "cursor").Page(&Page{
+		URL: page.URL,
+		Body: map[string]interface{}{
+			"data": []interface{}{},
+			"next": "c1",
+			"meta": map[string]interface{}{"next": "c2"},
+		},
+	})
+	assert.Error(t, err)
+}
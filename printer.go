@@ -91,7 +91,8 @@ func (p DebugPrinter) Response(resp *http.Response, duration time.Duration) {
 		return
 	}
 
-	dump, err := httputil.DumpResponse(resp, p.body)
+	// event stream body is read later by SSE
+	dump, err := httputil.DumpResponse(resp, p.body && !isEventStream(resp.Header))
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,238 @@
+package httpexpect
+
+import "sort"
+
+// Problem provides methods to inspect problem details object, as defined
+// in RFC 9457 (which obsoletes RFC 7807).
+//
+// Standard members are available using Type, Title, Status, Detail, and
+// Instance. All other members are extension members.
+type Problem struct {
+	chain chain
+	value map[string]interface{}
+}
+
+var problemMembers = map[string]bool{
+	"type":     true,
+	"title":    true,
+	"status":   true,
+	"detail":   true,
+	"instance": true,
+}
+
+// NewProblem returns a new Problem object given a reporter used to report
+// failures and decoded problem details object to be inspected.
+//
+// reporter should not be nil.
+//
+// Example:
+//  problem := NewProblem(reporter, map[string]interface{}{
+//      "type":   "https://example.com/probs/out-of-credit",
+//      "title":  "You do not have enough credit.",
+//      "status": 403,
+//  })
+//  problem.Title().Equal("You do not have enough credit.")
+func NewProblem(reporter Reporter, value map[string]interface{}) *Problem {
+	chain := makeChain(reporter).root("Problem").rootJSON()
+	if value == nil {
+		chain.fail("\nexpected non-nil problem details object")
+	} else {
+		value, _ = canonMap(&chain, value)
+	}
+	return &Problem{chain, value}
+}
+
+// Problem returns a new Problem object that may be used to inspect problem
+// details object in response body, as defined in RFC 9457.
+//
+// Problem succeeds if response contains "application/problem+json"
+// Content-Type header with empty or "utf-8" charset, if body is a JSON
+// object, and if its "status" member, when present, is equal to response
+// status code.
+//
+// Example:
+//  resp := NewResponse(t, response)
+//  problem := resp.Problem()
+//  problem.Type().Equal("https://example.com/probs/out-of-credit")
+//  problem.Status().Equal(http.StatusForbidden)
+//  problem.Extension("balance").Number().Equal(30)
+//  resp.Problem(ContentOpts{
+//    MediaType: "application/json",
+//  }).Title().NotEmpty()
+func (r *Response) Problem(opts ...ContentOpts) *Problem {
+	if r.chain.failed() {
+		return &Problem{r.chain.enter("Problem()").rootJSON(), nil}
+	}
+
+	if !r.checkContentOpts(opts, "application/problem+json") {
+		return &Problem{r.chain.enter("Problem()").rootJSON(), nil}
+	}
+
+	value := r.getJSON(opts...)
+	if r.chain.failed() {
+		return &Problem{r.chain.enter("Problem()").rootJSON(), nil}
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		r.chain.fail("\nexpected problem details JSON object, but got:\n%s",
+			dumpValue(value))
+		return &Problem{r.chain.enter("Problem()").rootJSON(), nil}
+	}
+
+	if status, ok := object["status"]; ok {
+		if n, ok := status.(float64); !ok || n != float64(r.resp.StatusCode) {
+			r.chain.fail(
+				"\nexpected problem details \"status\" member equal to"+
+					" response status:\n %s\n\nbut got:\n %v",
+				statusCodeText(r.resp.StatusCode), status)
+			return &Problem{r.chain.enter("Problem()").rootJSON(), nil}
+		}
+	}
+
+	return &Problem{r.chain.enter("Problem()").rootJSON(), object}
+}
+
+// Raw returns underlying problem details object.
+func (p *Problem) Raw() map[string]interface{} {
+	return p.value
+}
+
+// Type returns a new String object that may be used to inspect "type"
+// member. If member is missing, it's "about:blank", as defined by RFC.
+//
+// Example:
+//  problem := NewProblem(t, value)
+//  problem.Type().Equal("https://example.com/probs/out-of-credit")
+func (p *Problem) Type() *String {
+	value, present := p.member("type")
+	if !present && !p.chain.failed() {
+		value = "about:blank"
+	}
+	return &String{p.chain.enter("Type()").enterKey("type"), value}
+}
+
+// Title returns a new String object that may be used to inspect "title"
+// member. If member is missing, returned String is empty.
+//
+// Example:
+//  problem := NewProblem(t, value)
+//  problem.Title().Equal("You do not have enough credit.")
+func (p *Problem) Title() *String {
+	value, _ := p.member("title")
+	return &String{p.chain.enter("Title()").enterKey("title"), value}
+}
+
+// Status returns a new Number object that may be used to inspect "status"
+// member. If member is missing, returned Number is zero.
+//
+// If Problem was obtained using Response.Problem, "status" member is
+// already checked to be equal to response status code, when present.
+//
+// Example:
+//  problem := NewProblem(t, value)
+//  problem.Status().Equal(http.StatusForbidden)
+func (p *Problem) Status() *Number {
+	if p.chain.failed() {
+		return &Number{p.chain.enter("Status()").enterKey("status"), 0}
+	}
+	var value float64
+	if status, ok := p.value["status"]; ok {
+		if value, ok = status.(float64); !ok {
+			p.chain.fail("\nexpected problem details \"status\" member of number type,"+
+				" but got:\n%s", dumpValue(status))
+		}
+	}
+	return &Number{p.chain.enter("Status()").enterKey("status"), value}
+}
+
+// Detail returns a new String object that may be used to inspect "detail"
+// member. If member is missing, returned String is empty.
+//
+// Example:
+//  problem := NewProblem(t, value)
+//  problem.Detail().Contains("balance is 30")
+func (p *Problem) Detail() *String {
+	value, _ := p.member("detail")
+	return &String{p.chain.enter("Detail()").enterKey("detail"), value}
+}
+
+// Instance returns a new String object that may be used to inspect
+// "instance" member. If member is missing, returned String is empty.
+//
+// Example:
+//  problem := NewProblem(t, value)
+//  problem.Instance().Equal("/account/12345/msgs/abc")
+func (p *Problem) Instance() *String {
+	value, _ := p.member("instance")
+	return &String{p.chain.enter("Instance()").enterKey("instance"), value}
+}
+
+// Extension returns a new Value object that may be used to inspect given
+// extension member.
+//
+// If member is missing, failure is reported.
+//
+// Example:
+//  problem := NewProblem(t, value)
+//  problem.Extension("balance").Number().Equal(30)
+func (p *Problem) Extension(name string) *Value {
+	chain := p.chain.enter("Extension(%q)", name).enterKey(name)
+	if p.chain.failed() {
+		return &Value{chain, nil}
+	}
+	value, ok := p.value[name]
+	if !ok {
+		p.chain.fail("\nexpected problem details with %q extension member,"+
+			" but got only members:\n%s", name, dumpValue(sortedMembers(p.value)))
+		return &Value{p.chain.enter("Extension(%q)", name).enterKey(name), nil}
+	}
+	return &Value{chain, value}
+}
+
+// Extensions returns a new Object that may be used to inspect all extension
+// members, i.e. all members except standard ones.
+//
+// Example:
+//  problem := NewProblem(t, value)
+//  problem.Extensions().Keys().ContainsOnly("balance", "accounts")
+func (p *Problem) Extensions() *Object {
+	if p.chain.failed() {
+		return &Object{p.chain.enter("Extensions()"), nil}
+	}
+	ext := map[string]interface{}{}
+	for name, value := range p.value {
+		if !problemMembers[name] {
+			ext[name] = value
+		}
+	}
+	return &Object{p.chain.enter("Extensions()"), ext}
+}
+
+// member returns value of given standard member and whether it's present.
+// If member is present, but is not a string, failure is reported.
+func (p *Problem) member(name string) (string, bool) {
+	if p.chain.failed() {
+		return "", false
+	}
+	value, ok := p.value[name]
+	if !ok {
+		return "", false
+	}
+	s, ok := value.(string)
+	if !ok {
+		p.chain.fail("\nexpected problem details %q member of string type, but got:\n%s",
+			name, dumpValue(value))
+		return "", false
+	}
+	return s, true
+}
+
+func sortedMembers(value map[string]interface{}) []string {
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,132 @@
+package httpexpect
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblemFailed(t *testing.T) {
+	chain := makeChain(newMockReporter(t))
+
+	chain.fail("fail")
+
+	value := &Problem{chain, nil}
+
+	assert.True(t, value.Raw() == nil)
+	assert.True(t, value.Type() != nil)
+	assert.True(t, value.Title() != nil)
+	assert.True(t, value.Status() != nil)
+	assert.True(t, value.Detail() != nil)
+	assert.True(t, value.Instance() != nil)
+	assert.True(t, value.Extension("foo") != nil)
+	assert.True(t, value.Extensions() != nil)
+}
+
+func TestProblemGetters(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	NewProblem(reporter, nil).chain.assertFailed(t)
+
+	value := NewProblem(reporter, map[string]interface{}{
+		"type":     "https://example.com/probs/out-of-credit",
+		"title":    "You do not have enough credit.",
+		"status":   403,
+		"detail":   "Your current balance is 30, but that costs 50.",
+		"instance": "/account/12345/msgs/abc",
+		"balance":  30,
+		"accounts": []interface{}{"/account/12345", "/account/67890"},
+	})
+
+	value.Type().Equal("https://example.com/probs/out-of-credit").chain.assertOK(t)
+	value.Title().Equal("You do not have enough credit.").chain.assertOK(t)
+	value.Status().Equal(http.StatusForbidden).chain.assertOK(t)
+	value.Detail().Contains("balance is 30").chain.assertOK(t)
+	value.Instance().Equal("/account/12345/msgs/abc").chain.assertOK(t)
+
+	value.Extension("balance").Number().Equal(30).chain.assertOK(t)
+	value.Extension("accounts").Array().Length().Equal(2).chain.assertOK(t)
+	value.Extensions().Keys().ContainsOnly("balance", "accounts").chain.assertOK(t)
+
+	assert.Equal(t, "$.type", value.Type().chain.jsonPath)
+	assert.Equal(t, "$.balance", value.Extension("balance").chain.jsonPath)
+
+	value.chain.assertOK(t)
+
+	value.Extension("missing").chain.assertFailed(t)
+	value.chain.reset()
+
+	value = NewProblem(reporter, map[string]interface{}{})
+
+	value.Type().Equal("about:blank").chain.assertOK(t)
+	value.Title().Empty().chain.assertOK(t)
+	value.Status().Equal(0).chain.assertOK(t)
+	value.Extensions().Empty().chain.assertOK(t)
+
+	value = NewProblem(reporter, map[string]interface{}{
+		"title":  123,
+		"status": "403",
+	})
+
+	value.Title().chain.assertFailed(t)
+	value.chain.reset()
+
+	value.Status().chain.assertFailed(t)
+	value.chain.reset()
+}
+
+func TestProblemResponse(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	newResp := func(status int, contentType, body string) *Response {
+		return NewResponse(reporter, &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": {contentType}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+		})
+	}
+
+	resp := newResp(http.StatusNotFound, "application/problem+json",
+		`{"type": "https://example.com/probs/not-found", "status": 404, "id": 1}`)
+
+	problem := resp.Problem()
+	problem.Type().Equal("https://example.com/probs/not-found")
+	problem.Status().Equal(http.StatusNotFound)
+	problem.Extension("id").Number().Equal(1)
+	problem.chain.assertOK(t)
+	resp.chain.assertOK(t)
+
+	resp = newResp(http.StatusNotFound, "application/problem+json; charset=utf-8",
+		`{"title": "Not Found"}`)
+
+	resp.Problem().Status().Equal(0)
+	resp.chain.assertOK(t)
+
+	resp = newResp(http.StatusNotFound, "application/json", `{"status": 404}`)
+
+	resp.Problem()
+	resp.chain.assertFailed(t)
+
+	resp = newResp(http.StatusNotFound, "application/json", `{"status": 404}`)
+
+	resp.Problem(ContentOpts{MediaType: "application/json"})
+	resp.chain.assertOK(t)
+
+	resp = newResp(http.StatusBadRequest, "application/problem+json", `{"status": 404}`)
+
+	resp.Problem()
+	resp.chain.assertFailed(t)
+
+	resp = newResp(http.StatusBadRequest, "application/problem+json", `[]`)
+
+	resp.Problem()
+	resp.chain.assertFailed(t)
+
+	resp = newResp(http.StatusBadRequest, "application/problem+json", `{`)
+
+	resp.Problem()
+	resp.chain.assertFailed(t)
+}
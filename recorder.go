@@ -0,0 +1,403 @@
+package httpexpect
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// RecorderMode defines how Recorder handles requests.
+type RecorderMode int
+
+const (
+	// RecorderPassthrough mode sends requests using underlying client and
+	// neither reads nor writes the cassette.
+	RecorderPassthrough RecorderMode = iota
+
+	// RecorderRecord mode sends requests using underlying client and saves
+	// every interaction to the cassette. Existing cassette is overwritten.
+	RecorderRecord
+
+	// RecorderReplay mode serves responses from the cassette without sending
+	// requests. Requests that don't match any recorded interaction fail.
+	RecorderReplay
+)
+
+// RecorderMatch defines which parts of requests are compared when looking
+// for a recorded interaction in replay mode.
+type RecorderMatch int
+
+const (
+	// RecorderMatchMethod compares request methods.
+	RecorderMatchMethod RecorderMatch = 1 << iota
+
+	// RecorderMatchURL compares request URLs, including query.
+	RecorderMatchURL
+
+	// RecorderMatchBody compares request bodies. If both bodies are valid
+	// JSON, they're compared as JSON values, so formatting and key order
+	// don't matter.
+	RecorderMatchBody
+)
+
+// RecorderScrubbed is the placeholder that replaces values of scrubbed
+// headers in cassette.
+const RecorderScrubbed = "[SCRUBBED]"
+
+// Recorder implements Client. It records interactions with a real server
+// to a cassette file and replays them later, which allows to run tests
+// written against a real service quickly and without network.
+//
+// In record mode, requests are sent using underlying Client, e.g. a real
+// http.Client, or &http.Client{Transport: NewBinder(handler)} to record
+// interactions with a handler, and every request and response are saved
+// to the cassette. The cassette file is rewritten after every interaction.
+//
+// In replay mode, the cassette is loaded on first request, and every request
+// is answered with the response of the first unused matching interaction,
+// or, if all matching interactions were already used, the last of them.
+// If there is no matching interaction, an error is returned, and so the
+// failure is reported by Request.Expect.
+//
+// Example:
+//  recorder := &httpexpect.Recorder{
+//      Mode:         httpexpect.RecorderReplay,
+//      Cassette:     "testdata/users.json",
+//      Client:       http.DefaultClient,
+//      MatchHeaders: []string{"Accept"},
+//      ScrubHeaders: []string{"Authorization", "Set-Cookie"},
+//  }
+//
+//  e := httpexpect.WithConfig(httpexpect.Config{
+//      BaseURL:  "https://staging.example.com",
+//      Reporter: httpexpect.NewAssertReporter(t),
+//      Client:   recorder,
+//  })
+type Recorder struct {
+	// Mode defines how requests are handled.
+	Mode RecorderMode
+
+	// Cassette is the path to the cassette file.
+	Cassette string
+
+	// Client is used to send requests in record and passthrough modes.
+	// If nil, http.DefaultClient is used.
+	Client Client
+
+	// Match defines which parts of requests should match recorded ones in
+	// replay mode. If zero, method, URL, and body are compared.
+	Match RecorderMatch
+
+	// MatchHeaders lists request headers that should match recorded ones
+	// in replay mode.
+	MatchHeaders []string
+
+	// ScrubHeaders lists request and response headers which values are
+	// replaced with RecorderScrubbed before saving to cassette, e.g.
+	// "Authorization" or "Set-Cookie". Scrubbed headers are ignored when
+	// matching requests.
+	ScrubHeaders []string
+
+	mu       sync.Mutex
+	cassette *recorderCassette
+	used     []bool
+}
+
+type recorderCassette struct {
+	Interactions []*recorderInteraction `json:"interactions"`
+}
+
+type recorderInteraction struct {
+	Request  recorderRequest  `json:"request"`
+	Response recorderResponse `json:"response"`
+}
+
+type recorderRequest struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Headers      http.Header `json:"headers,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+type recorderResponse struct {
+	Status       int         `json:"status"`
+	Headers      http.Header `json:"headers,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+// Do implements Client.Do.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	switch r.Mode {
+	case RecorderRecord:
+		return r.record(req)
+	case RecorderReplay:
+		return r.replay(req)
+	default:
+		return r.client().Do(req)
+	}
+}
+
+func (r *Recorder) client() Client {
+	if r.Client == nil {
+		return http.DefaultClient
+	}
+	return r.Client
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBodyErr(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	interaction := &recorderInteraction{
+		Request: recorderRequest{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: r.scrub(req.Header),
+		},
+		Response: recorderResponse{
+			Status:  resp.StatusCode,
+			Headers: r.scrub(resp.Header),
+		},
+	}
+
+	interaction.Request.Body, interaction.Request.BodyEncoding =
+		encodeRecorderBody(reqBody)
+
+	interaction.Response.Body, interaction.Response.BodyEncoding =
+		encodeRecorderBody(respBody)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cassette == nil {
+		r.cassette = &recorderCassette{}
+	}
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+
+	if err := r.save(); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBodyErr(req)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cassette == nil {
+		if err := r.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	found := -1
+	for n, interaction := range r.cassette.Interactions {
+		if !r.matches(interaction, req, body) {
+			continue
+		}
+		found = n
+		if !r.used[n] {
+			break
+		}
+	}
+
+	if found < 0 {
+		return nil, fmt.Errorf(
+			"no interaction in cassette %q matches request %s %s",
+			r.Cassette, req.Method, req.URL)
+	}
+
+	r.used[found] = true
+
+	return r.cassette.Interactions[found].Response.build(req)
+}
+
+func (r *Recorder) matches(
+	interaction *recorderInteraction, req *http.Request, body []byte,
+) bool {
+	match := r.Match
+	if match == 0 {
+		match = RecorderMatchMethod | RecorderMatchURL | RecorderMatchBody
+	}
+
+	recorded := interaction.Request
+
+	if match&RecorderMatchMethod != 0 && recorded.Method != req.Method {
+		return false
+	}
+
+	if match&RecorderMatchURL != 0 && recorded.URL != req.URL.String() {
+		return false
+	}
+
+	for _, name := range r.MatchHeaders {
+		if r.scrubbed(name) {
+			continue
+		}
+		if !reflect.DeepEqual(
+			recorded.Headers[http.CanonicalHeaderKey(name)],
+			req.Header[http.CanonicalHeaderKey(name)]) {
+			return false
+		}
+	}
+
+	if match&RecorderMatchBody != 0 {
+		recordedBody, err := decodeRecorderBody(recorded.Body, recorded.BodyEncoding)
+		if err != nil || !equalRecorderBodies(recordedBody, body) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *Recorder) scrubbed(name string) bool {
+	for _, s := range r.ScrubHeaders {
+		if strings.EqualFold(s, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Recorder) scrub(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	ret := make(http.Header, len(header))
+	for name, values := range header {
+		if r.scrubbed(name) {
+			ret[name] = []string{RecorderScrubbed}
+		} else {
+			ret[name] = append([]string(nil), values...)
+		}
+	}
+	return ret
+}
+
+func (r *Recorder) load() error {
+	data, err := ioutil.ReadFile(r.Cassette)
+	if err != nil {
+		return err
+	}
+
+	cassette := &recorderCassette{}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return fmt.Errorf("invalid cassette %q: %s", r.Cassette, err.Error())
+	}
+
+	r.cassette = cassette
+	r.used = make([]bool, len(cassette.Interactions))
+
+	return nil
+}
+
+func (r *Recorder) save() error {
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.Cassette), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.Cassette, append(data, '\n'), 0644)
+}
+
+func (resp *recorderResponse) build(req *http.Request) (*http.Response, error) {
+	body, err := decodeRecorderBody(resp.Body, resp.BodyEncoding)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	for name, values := range resp.Headers {
+		header[name] = append([]string(nil), values...)
+	}
+
+	return &http.Response{
+		Status:        strconv.Itoa(resp.Status) + " " + http.StatusText(resp.Status),
+		StatusCode:    resp.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// readRequestBodyErr is like readRequestBody, but returns error.
+func readRequestBodyErr(req *http.Request) ([]byte, error) {
+	body, ok := readRequestBody(req)
+	if !ok {
+		return nil, fmt.Errorf("failed to read body of request %s %s",
+			req.Method, req.URL)
+	}
+	return body, nil
+}
+
+func encodeRecorderBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func decodeRecorderBody(body, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(body), nil
+	case "base64":
+		return base64.StdEncoding.DecodeString(body)
+	default:
+		return nil, fmt.Errorf("unsupported body encoding %q", encoding)
+	}
+}
+
+func equalRecorderBodies(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+
+	var ja, jb interface{}
+	if json.Unmarshal(a, &ja) != nil || json.Unmarshal(b, &jb) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(ja, jb)
+}
@@ -0,0 +1,270 @@
+package httpexpect
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorderHandler struct {
+	calls int
+}
+
+func (h *recorderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.calls++
+
+	body, _ := ioutil.ReadAll(r.Body)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Set-Cookie", "session=secret")
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"path":  r.URL.Path,
+		"calls": h.calls,
+		"body":  string(body),
+	})
+}
+
+func TestRecorderRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpexpect")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cassette := filepath.Join(dir, "cassettes", "test.json")
+
+	handler := &recorderHandler{}
+
+	recorder := &Recorder{
+		Mode:     RecorderRecord,
+		Cassette: cassette,
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+		ScrubHeaders: []string{"Authorization", "Set-Cookie"},
+	}
+
+	config := Config{
+		BaseURL:        "http://example.com",
+		RequestFactory: DefaultRequestFactory{},
+		Client:         recorder,
+		Reporter:       newMockReporter(t),
+	}
+
+	NewRequest(config, "GET", "/foo").
+		WithHeader("Authorization", "Bearer secret").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().ValueEqual("calls", 1).
+		chain.assertOK(t)
+
+	NewRequest(config, "GET", "/foo").
+		Expect().
+		JSON().Object().ValueEqual("calls", 2).
+		chain.assertOK(t)
+
+	NewRequest(config, "POST", "/bar").
+		WithJSON(map[string]interface{}{"a": 1, "b": 2}).
+		Expect().
+		JSON().Object().ValueEqual("calls", 3).
+		chain.assertOK(t)
+
+	assert.Equal(t, 3, handler.calls)
+
+	data, err := ioutil.ReadFile(cassette)
+	require.Nil(t, err)
+	assert.NotContains(t, string(data), "secret")
+	assert.Contains(t, string(data), RecorderScrubbed)
+
+	recorder = &Recorder{
+		Mode:     RecorderReplay,
+		Cassette: cassette,
+	}
+
+	config = Config{
+		BaseURL:        "http://example.com",
+		RequestFactory: DefaultRequestFactory{},
+		Client:         recorder,
+		Reporter:       newMockReporter(t),
+	}
+
+	NewRequest(config, "GET", "/foo").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().ValueEqual("calls", 1).
+		chain.assertOK(t)
+
+	NewRequest(config, "GET", "/foo").
+		Expect().
+		JSON().Object().ValueEqual("calls", 2).
+		chain.assertOK(t)
+
+	NewRequest(config, "GET", "/foo").
+		Expect().
+		JSON().Object().ValueEqual("calls", 2).
+		chain.assertOK(t)
+
+	NewRequest(config, "POST", "/bar").
+		WithBytes([]byte(`{ "b": 2, "a": 1 }`)).
+		Expect().
+		JSON().Object().ValueEqual("calls", 3).
+		chain.assertOK(t)
+
+	NewRequest(config, "POST", "/bar").
+		WithJSON(map[string]interface{}{"a": 1}).
+		Expect().
+		chain.assertFailed(t)
+
+	NewRequest(config, "PUT", "/foo").
+		Expect().
+		chain.assertFailed(t)
+
+	NewRequest(config, "GET", "/baz").
+		Expect().
+		chain.assertFailed(t)
+
+	assert.Equal(t, 3, handler.calls)
+}
+
+func TestRecorderMatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpexpect")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cassette := filepath.Join(dir, "test.json")
+
+	recorder := &Recorder{
+		Mode:     RecorderRecord,
+		Cassette: cassette,
+		Client: &http.Client{
+			Transport: NewBinder(&recorderHandler{}),
+		},
+	}
+
+	NewRequest(Config{
+		BaseURL:        "http://example.com",
+		RequestFactory: DefaultRequestFactory{},
+		Client:         recorder,
+		Reporter:       newMockReporter(t),
+	}, "POST", "/foo").
+		WithHeader("Accept", "application/json").
+		WithText("hello").
+		Expect().
+		chain.assertOK(t)
+
+	recorder = &Recorder{
+		Mode:         RecorderReplay,
+		Cassette:     cassette,
+		Match:        RecorderMatchURL,
+		MatchHeaders: []string{"accept"},
+	}
+
+	config := Config{
+		BaseURL:        "http://example.com",
+		RequestFactory: DefaultRequestFactory{},
+		Client:         recorder,
+		Reporter:       newMockReporter(t),
+	}
+
+	NewRequest(config, "PUT", "/foo").
+		WithHeader("Accept", "application/json").
+		WithText("world").
+		Expect().
+		Status(http.StatusOK).
+		chain.assertOK(t)
+
+	NewRequest(config, "PUT", "/foo").
+		WithHeader("Accept", "text/plain").
+		Expect().
+		chain.assertFailed(t)
+
+	NewRequest(config, "PUT", "/foo").
+		WithQuery("q", 1).
+		WithHeader("Accept", "application/json").
+		Expect().
+		chain.assertFailed(t)
+}
+
+func TestRecorderPassthrough(t *testing.T) {
+	handler := &recorderHandler{}
+
+	recorder := &Recorder{
+		Cassette: "/nonexistent/cassette.json",
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+	}
+
+	config := Config{
+		BaseURL:        "http://example.com",
+		RequestFactory: DefaultRequestFactory{},
+		Client:         recorder,
+		Reporter:       newMockReporter(t),
+	}
+
+	NewRequest(config, "GET", "/foo").
+		Expect().
+		Status(http.StatusOK).
+		chain.assertOK(t)
+
+	assert.Equal(t, 1, handler.calls)
+}
+
+func TestRecorderErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpexpect")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	recorder1 := &Recorder{
+		Mode:     RecorderReplay,
+		Cassette: filepath.Join(dir, "missing.json"),
+	}
+
+	NewRequest(Config{
+		BaseURL:        "http://example.com",
+		RequestFactory: DefaultRequestFactory{},
+		Client:         recorder1,
+		Reporter:       newMockReporter(t),
+	}, "GET", "/foo").
+		Expect().
+		chain.assertFailed(t)
+
+	invalid := filepath.Join(dir, "invalid.json")
+	require.Nil(t, ioutil.WriteFile(invalid, []byte("{"), 0644))
+
+	recorder2 := &Recorder{
+		Mode:     RecorderReplay,
+		Cassette: invalid,
+	}
+
+	NewRequest(Config{
+		BaseURL:        "http://example.com",
+		RequestFactory: DefaultRequestFactory{},
+		Client:         recorder2,
+		Reporter:       newMockReporter(t),
+	}, "GET", "/foo").
+		Expect().
+		chain.assertFailed(t)
+}
+
+func TestRecorderBinaryBody(t *testing.T) {
+	body, encoding := encodeRecorderBody([]byte{0xff, 0x00})
+	assert.Equal(t, "base64", encoding)
+
+	decoded, err := decodeRecorderBody(body, encoding)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0xff, 0x00}, decoded)
+
+	body, encoding = encodeRecorderBody([]byte("hello"))
+	assert.Equal(t, "hello", body)
+	assert.Equal(t, "", encoding)
+
+	_, err = decodeRecorderBody("hello", "gzip")
+	assert.NotNil(t, err)
+}
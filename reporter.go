@@ -2,6 +2,7 @@ package httpexpect
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -38,3 +39,89 @@ func NewRequireReporter(t require.TestingT) *RequireReporter {
 func (r *RequireReporter) Errorf(message string, args ...interface{}) {
 	r.backend.FailNow(fmt.Sprintf(message, args...))
 }
+
+// recordingReporter implements Reporter and FailureReporter interfaces and
+// records all reported failures instead of reporting them, so that they can
+// be later replayed to another reporter or discarded.
+type recordingReporter struct {
+	mu       sync.Mutex
+	failures []*AssertionFailure
+}
+
+func newRecordingReporter() *recordingReporter {
+	return &recordingReporter{}
+}
+
+// Errorf implements Reporter.Errorf.
+func (r *recordingReporter) Errorf(message string, args ...interface{}) {
+	r.ReportFailure(&AssertionFailure{
+		Message: fmt.Sprintf(message, args...),
+	})
+}
+
+// ReportFailure implements FailureReporter.ReportFailure.
+func (r *recordingReporter) ReportFailure(failure *AssertionFailure) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failures = append(r.failures, failure)
+}
+
+func (r *recordingReporter) failed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.failures) != 0
+}
+
+func (r *recordingReporter) recorded() []*AssertionFailure {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*AssertionFailure(nil), r.failures...)
+}
+
+func (r *recordingReporter) replay(reporter Reporter) {
+	for _, failure := range r.recorded() {
+		reportFailure(reporter, failure)
+	}
+}
+
+// replayCombined reports all recorded failures to another reporter as
+// a single failure. Does nothing if there are no recorded failures.
+func (r *recordingReporter) replayCombined(reporter Reporter, assertion string) {
+	failures := r.recorded()
+	if len(failures) == 0 {
+		return
+	}
+
+	reportFailure(reporter, &AssertionFailure{
+		Assertion: assertion,
+		Message:   formatFailures(failures),
+		Failures:  failures,
+	})
+}
+
+// sessionReporter implements Reporter and FailureReporter interfaces and
+// tags every failure with session name before passing it to another
+// reporter. Used by Expect.Session.
+type sessionReporter struct {
+	name     string
+	reporter Reporter
+}
+
+// Errorf implements Reporter.Errorf.
+func (r *sessionReporter) Errorf(message string, args ...interface{}) {
+	r.ReportFailure(&AssertionFailure{
+		Message: fmt.Sprintf(message, args...),
+	})
+}
+
+// ReportFailure implements FailureReporter.ReportFailure.
+func (r *sessionReporter) ReportFailure(failure *AssertionFailure) {
+	tagged := *failure
+	tagged.Session = r.name
+	tagged.Message = "\n[session " + r.name + "]" + failure.Message
+
+	reportFailure(r.reporter, &tagged)
+}
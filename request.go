@@ -2,7 +2,9 @@ package httpexpect
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -36,8 +38,10 @@ type Request struct {
 	multipart  *multipart.Writer
 	bodySetter string
 	typeSetter string
+	encoding   string
 	forceType  bool
 	wsUpgrade  bool
+	operation  *openAPIOperation
 	matchers   []func(*Response)
 }
 
@@ -73,7 +77,7 @@ func NewRequest(config Config, method, path string, pathargs ...interface{}) *Re
 		panic("config.Client == nil")
 	}
 
-	chain := makeChain(config.Reporter)
+	chain := makeChain(config.Reporter).root("Request")
 
 	n := 0
 	path, err := interpol.WithFunc(path, func(k string, w io.Writer) error {
@@ -102,6 +106,8 @@ func NewRequest(config Config, method, path string, pathargs ...interface{}) *Re
 		chain.fail(err.Error())
 	}
 
+	chain.request = hr
+
 	return &Request{
 		config: config,
 		chain:  chain,
@@ -227,6 +233,134 @@ func (r *Request) WithWebsocketDialer(dialer WebsocketDialer) *Request {
 	return r
 }
 
+// WithContext sets the context of the request.
+//
+// The new context overwrites Config.Context. If the context is cancelled
+// or its deadline is exceeded before the response is received, failure is
+// reported. It is also used for WebSocket handshake.
+//
+// Example:
+//  ctx, cancel := context.WithCancel(context.Background())
+//  defer cancel()
+//
+//  req := NewRequest(config, "GET", "/path")
+//  req.WithContext(ctx)
+func (r *Request) WithContext(ctx context.Context) *Request {
+	if r.chain.failed() {
+		return r
+	}
+	if ctx == nil {
+		r.chain.fail("\nunexpected nil context in WithContext")
+		return r
+	}
+	r.config.Context = ctx
+	return r
+}
+
+// WithTimeout sets the timeout of the request.
+//
+// The new timeout overwrites Config.RequestTimeout. If the response is
+// not received in time, failure is reported. Zero timeout means that no
+// timeout is used.
+//
+// Example:
+//  req := NewRequest(config, "GET", "/path")
+//  req.WithTimeout(2 * time.Second)
+//  req.Expect().Status(http.StatusOK)
+func (r *Request) WithTimeout(timeout time.Duration) *Request {
+	if r.chain.failed() {
+		return r
+	}
+	if timeout < 0 {
+		r.chain.fail("\nunexpected negative timeout in WithTimeout: %s", timeout)
+		return r
+	}
+	r.config.RequestTimeout = timeout
+	return r
+}
+
+// WithRetryPolicy sets the retry policy of the request.
+//
+// The new policy overwrites Config.RetryPolicy. Nil policy means that
+// the request is never retried. See RetryPolicy for details.
+//
+// Example:
+//  req := NewRequest(config, "GET", "/path")
+//  req.WithRetryPolicy(&RetryPolicy{
+//      MaxAttempts: 3,
+//      MinBackoff:  100 * time.Millisecond,
+//      RetryOn:     []RetryCondition{RetryServerErrors},
+//  })
+//  req.Expect().Status(http.StatusOK)
+func (r *Request) WithRetryPolicy(policy *RetryPolicy) *Request {
+	if r.chain.failed() {
+		return r
+	}
+	r.config.RetryPolicy = policy
+	return r
+}
+
+// WithAuthenticator sets the authenticator of the request.
+//
+// The new authenticator overwrites Config.Authenticator. Nil authenticator
+// means that no credentials are added automatically, e.g. when checking
+// that unauthenticated request is rejected. See Authenticator for details.
+//
+// Example:
+//  req := NewRequest(config, "GET", "/path")
+//  req.WithAuthenticator(NewAPIKeyAuthenticator(APIKeyInQuery, "key", "secret"))
+//  req.Expect().Status(http.StatusOK)
+func (r *Request) WithAuthenticator(auth Authenticator) *Request {
+	if r.chain.failed() {
+		return r
+	}
+	r.config.Authenticator = auth
+	return r
+}
+
+// WithSigner sets the signer of the request.
+//
+// The new signer overwrites Config.Signer. Nil signer means that request
+// is not signed, e.g. when checking that unsigned request is rejected.
+// See Signer for details.
+//
+// Example:
+//  req := NewRequest(config, "PUT", "/path")
+//  req.WithSigner(NewAWSSigV4Signer(AWSSigV4Opts{
+//      AccessKeyID:     "AKID",
+//      SecretAccessKey: "secret",
+//      Region:          "us-east-1",
+//      Service:         "execute-api",
+//  }))
+//  req.Expect().Status(http.StatusOK)
+func (r *Request) WithSigner(signer Signer) *Request {
+	if r.chain.failed() {
+		return r
+	}
+	r.config.Signer = signer
+	return r
+}
+
+// WithOpenAPI sets the OpenAPI document that request and response are
+// validated against.
+//
+// The new document overwrites Config.OpenAPI. Nil document means that the
+// request and response are not validated, e.g. when sending a request that
+// intentionally violates the contract. See OpenAPI for details.
+//
+// Example:
+//  req := NewRequest(config, "POST", "/users")
+//  req.WithOpenAPI(nil)
+//  req.WithJSON(map[string]interface{}{"name": 123})
+//  req.Expect().Status(http.StatusBadRequest)
+func (r *Request) WithOpenAPI(spec *OpenAPI) *Request {
+	if r.chain.failed() {
+		return r
+	}
+	r.config.OpenAPI = spec
+	return r
+}
+
 // WithPath substitutes named parameters in url path.
 //
 // value is converted to string using fmt.Sprint(). If there is no named
@@ -490,6 +624,65 @@ func (r *Request) WithHeader(k, v string) *Request {
 	return r
 }
 
+// WithConditionalFrom makes request conditional, using validators from
+// given response: ETag and Last-Modified headers.
+//
+// For GET and HEAD requests, ETag is copied to If-None-Match header, and
+// Last-Modified is copied to If-Modified-Since header, so that server may
+// reply with 304 Not Modified. For other methods, they are copied to
+// If-Match and If-Unmodified-Since headers, so that server may reply with
+// 412 Precondition Failed if resource was modified meanwhile.
+//
+// If response has neither ETag nor Last-Modified header, failure is
+// reported.
+//
+// Example:
+//  resp := e.GET("/users/1").Expect().Status(http.StatusOK)
+//  e.GET("/users/1").WithConditionalFrom(resp).
+//      Expect().
+//      Status(http.StatusNotModified).
+//      ETag().Equal(resp.ETag().Raw())
+func (r *Request) WithConditionalFrom(resp *Response) *Request {
+	if r.chain.failed() {
+		return r
+	}
+
+	if resp == nil || resp.resp == nil {
+		r.chain.fail("\nunexpected nil response in WithConditionalFrom")
+		return r
+	}
+
+	etag := resp.resp.Header.Get("ETag")
+	lastModified := resp.resp.Header.Get("Last-Modified")
+
+	if etag == "" && lastModified == "" {
+		r.chain.fail(
+			"\nexpected response with \"ETag\" or \"Last-Modified\" header" +
+				" in WithConditionalFrom")
+		return r
+	}
+
+	safe := r.http.Method == http.MethodGet || r.http.Method == http.MethodHead
+
+	if etag != "" {
+		if safe {
+			r.http.Header.Set("If-None-Match", etag)
+		} else {
+			r.http.Header.Set("If-Match", etag)
+		}
+	}
+
+	if lastModified != "" {
+		if safe {
+			r.http.Header.Set("If-Modified-Since", lastModified)
+		} else {
+			r.http.Header.Set("If-Unmodified-Since", lastModified)
+		}
+	}
+
+	return r
+}
+
 // WithCookies adds given cookies to request.
 //
 // Example:
@@ -591,6 +784,36 @@ func (r *Request) WithChunked(reader io.Reader) *Request {
 	return r
 }
 
+// WithCompression enables compression of request body using given content
+// coding, e.g. "gzip", "deflate", or "br".
+//
+// Body set by any method, e.g. WithJSON, WithBytes, or WithForm, is
+// compressed when the request is sent, and Content-Encoding header is set.
+// Request without body is sent as is. Empty encoding or "identity" disables
+// compression. Codings other than built-in ones may be added using
+// Config.ContentCodings.
+//
+// Example:
+//  req := NewRequest(config, "PUT", "http://example.com/path")
+//  req.WithCompression("gzip")
+//  req.WithJSON(map[string]interface{}{"foo": 123})
+func (r *Request) WithCompression(encoding string) *Request {
+	if r.chain.failed() {
+		return r
+	}
+	if encoding == "" || strings.EqualFold(encoding, "identity") {
+		r.encoding = ""
+		return r
+	}
+	coding, ok := lookupContentCoding(r.config.ContentCodings, encoding)
+	if !ok || coding.Encoder == nil {
+		r.chain.fail("\nunsupported content encoding %q", encoding)
+		return r
+	}
+	r.encoding = encoding
+	return r
+}
+
 func (r *Request) WithJSONPretty(object interface{}) *Request {
 	if r.chain.failed() {
 		return r
@@ -669,6 +892,34 @@ func (r *Request) WithJSON(object interface{}) *Request {
 	return r
 }
 
+// WithXML sets Content-Type header to "application/xml; charset=utf-8"
+// and sets body to object, marshaled using xml.Marshal().
+//
+// Example:
+//  type MyXML struct {
+//      XMLName xml.Name `xml:"user"`
+//      ID      int      `xml:"id,attr"`
+//      Name    string   `xml:"name"`
+//  }
+//
+//  req := NewRequest(config, "PUT", "http://example.com/path")
+//  req.WithXML(MyXML{ID: 1, Name: "john"})
+func (r *Request) WithXML(object interface{}) *Request {
+	if r.chain.failed() {
+		return r
+	}
+	b, err := xml.Marshal(object)
+	if err != nil {
+		r.chain.fail(err.Error())
+		return r
+	}
+
+	r.setType("WithXML", "application/xml; charset=utf-8", false)
+	r.setBody("WithXML", bytes.NewReader(b), len(b), false)
+
+	return r
+}
+
 // WithForm sets Content-Type header to "application/x-www-form-urlencoded"
 // or (if WithMultipart() was called) "multipart/form-data", converts given
 // object to url.Values using github.com/ajg/form, and adds it to request body.
@@ -880,8 +1131,11 @@ func (r *Request) WithMultipart() *Request {
 //  resp := req.Expect()
 //  resp.Status(http.StatusOK)
 func (r *Request) Expect() *Response {
-	resp := r.roundTrip()
+	return r.matchResponse(r.roundTrip())
+}
 
+// matchResponse invokes matchers for response returned by roundTrip.
+func (r *Request) matchResponse(resp *Response) *Response {
 	if resp == nil {
 		return makeResponse(responseOpts{
 			config: r.config,
@@ -903,17 +1157,497 @@ func (r *Request) ExpectWithCurl() *Response {
 	return r.Expect()
 }
 
+// ExpectEventually is like Expect, but re-sends the request until all
+// matchers succeed, or until the timeout expires.
+//
+// On every attempt, the request is sent, and then all matchers attached to
+// the request and the given matcher are invoked for the new response. If
+// any failure is reported during the attempt (including failure to send the
+// request), the attempt is considered failed, its failures are discarded,
+// and the request is re-sent after the given interval.
+//
+// If the timeout expires, failures of the last attempt are reported to
+// Config.Reporter. The returned Response is the response of the last
+// attempt, and further checks on it are reported as usual.
+//
+// Note that the timeout is checked between attempts. Use Config.RequestTimeout
+// or WithTimeout to limit duration of every single attempt.
+//
+// Request body set by WithChunked can't be re-sent, unless the reader is
+// *bytes.Reader, *bytes.Buffer, or *strings.Reader, so such requests are
+// not allowed.
+//
+// Example:
+//  req := NewRequest(config, "GET", "/jobs/{id}", id)
+//  req.ExpectEventually(10*time.Second, 100*time.Millisecond,
+//      func(resp *Response) {
+//          resp.Status(http.StatusOK).
+//              JSON().Object().ValueEqual("status", "done")
+//      })
+func (r *Request) ExpectEventually(
+	timeout, interval time.Duration, matcher func(*Response),
+) *Response {
+	if !r.chain.failed() && !r.canReplayBody() {
+		r.chain.fail(
+			"\nrequest body set by %s can't be re-sent by ExpectEventually",
+			r.bodySetter)
+	}
+
+	if !r.encode() {
+		return makeResponse(responseOpts{
+			config: r.config,
+			chain:  r.chain,
+		})
+	}
+
+	ctx := r.config.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	reporter := r.chain.reporter
+	deadline := time.Now().Add(timeout)
+
+	for {
+		recorder := newRecordingReporter()
+
+		r.chain.reset()
+		r.chain.reporter = recorder
+
+		resp := r.send()
+
+		if resp == nil {
+			resp = makeResponse(responseOpts{
+				config: r.config,
+				chain:  r.chain,
+			})
+		} else {
+			for _, m := range r.matchers {
+				m(resp)
+			}
+			if matcher != nil {
+				matcher(resp)
+			}
+		}
+
+		r.chain.reporter = reporter
+		resp.chain.reporter = reporter
+
+		if !recorder.failed() {
+			return resp
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			recorder.replay(reporter)
+			return resp
+		}
+
+		// response of failed attempt is discarded, e.g. close unread stream
+		resp.release()
+
+		if !sleepContext(ctx, interval) || !r.replayBody() {
+			recorder.replay(reporter)
+			return resp
+		}
+	}
+}
+
+// Paginate sends the request, and then requests all subsequent pages, as
+// defined by given paginator, until the last page. It returns a new Array
+// object with items collected from all pages.
+//
+// Every page is requested with the same method, headers, and body, but with
+// URL returned by paginator. All matchers attached to the request are
+// invoked for every page, and then given page matchers are invoked, so
+// they may be used to assert every page. Page body should contain JSON.
+// Pagination stops at the first failure, or if a page URL is repeated.
+//
+// If OpenAPI is set, every page request and response is validated against
+// the operation matching URL of that page, which may differ from the
+// operation of the first page.
+//
+// Request body set by WithChunked can't be re-sent, unless the reader is
+// *bytes.Reader, *bytes.Buffer, or *strings.Reader, so such requests are
+// not allowed.
+//
+// Example:
+//  req := NewRequest(config, "GET", "/users")
+//  req.Paginate(NewLinkPaginator("$.data"), func(page *Response) {
+//      page.Status(http.StatusOK)
+//      page.JSON().Path("$.data").Array().Length().Le(50)
+//  }).Length().Equal(42)
+func (r *Request) Paginate(
+	paginator Paginator, pageMatchers ...func(*Response),
+) *Array {
+	if !r.chain.failed() && paginator == nil {
+		r.chain.fail("\nunexpected nil paginator in Paginate")
+	}
+
+	if !r.chain.failed() && !r.canReplayBody() {
+		r.chain.fail(
+			"\nrequest body set by %s can't be re-sent by Paginate",
+			r.bodySetter)
+	}
+
+	if !r.encode() {
+		return &Array{r.chain.enter("Paginate()").rootJSON(), nil}
+	}
+
+	items := []interface{}{}
+	visited := map[string]bool{}
+
+	for index := 0; ; index++ {
+		visited[r.http.URL.String()] = true
+
+		resp := r.send()
+		if resp == nil {
+			return &Array{r.chain.enter("Paginate()").rootJSON(), nil}
+		}
+
+		for _, matcher := range r.matchers {
+			matcher(resp)
+		}
+
+		for _, matcher := range pageMatchers {
+			matcher(resp)
+		}
+
+		body := resp.getJSON()
+		if resp.chain.failed() {
+			return &Array{resp.chain.enter("Paginate()").rootJSON(), nil}
+		}
+
+		pageItems, next, err := paginator.Page(&Page{
+			Index:  index,
+			URL:    r.http.URL,
+			Header: resp.resp.Header,
+			Body:   body,
+		})
+		if err != nil {
+			resp.chain.fail("\npagination failed on page %d:\n %s", index, err.Error())
+			return &Array{resp.chain.enter("Paginate()").rootJSON(), nil}
+		}
+
+		items = append(items, pageItems...)
+
+		if next == nil {
+			return &Array{resp.chain.enter("Paginate()").rootJSON(), items}
+		}
+
+		if visited[next.String()] {
+			resp.chain.fail(
+				"\nexpected next page URL not requested before, but got:\n %q",
+				next.String())
+			return &Array{resp.chain.enter("Paginate()").rootJSON(), nil}
+		}
+
+		if !r.replayBody() {
+			return &Array{r.chain.enter("Paginate()").rootJSON(), nil}
+		}
+
+		r.http.URL = next
+		r.http.Host = next.Host
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 func (r *Request) roundTrip() *Response {
-	if !r.encodeRequest() {
+	if !r.encode() {
 		return nil
 	}
 
+	return r.send()
+}
+
+func (r *Request) encode() bool {
+	if !r.encodeRequest() {
+		return false
+	}
+
 	if r.wsUpgrade {
 		if !r.encodeWebsocketRequest() {
+			return false
+		}
+	}
+
+	if r.encoding != "" {
+		if !r.compressBody() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *Request) compressBody() bool {
+	if r.bodySetter == "" {
+		return true
+	}
+
+	body, err := r.peekBody()
+	if err != nil {
+		r.chain.fail(err.Error())
+		return false
+	}
+
+	compressed, err := encodeContent(r.config.ContentCodings, r.encoding, body)
+	if err != nil {
+		r.chain.fail("\nfailed to compress request body: %s", err.Error())
+		return false
+	}
+
+	length := len(compressed)
+	if r.http.ContentLength < 0 {
+		// keep chunked encoding
+		length = -1
+	}
+
+	r.setBody(r.bodySetter, bytes.NewReader(compressed), length, true)
+	r.http.Header.Set("Content-Encoding", r.encoding)
+
+	return true
+}
+
+func (r *Request) validateRequest() bool {
+	op, pathValues := r.config.OpenAPI.findOperation(
+		r.http.Method, r.http.URL.EscapedPath())
+	if op == nil {
+		r.chain.fail("\nno OpenAPI operation matches request:\n %s %s",
+			r.http.Method, r.http.URL.Path)
+		return false
+	}
+
+	var body []byte
+	if r.bodySetter == "" {
+		body = []byte{}
+	} else if r.http.GetBody != nil {
+		if reader, err := r.http.GetBody(); err == nil {
+			body, _ = ioutil.ReadAll(reader)
+			_ = reader.Close()
+		}
+		if decoded, err := decodeContent(
+			r.config.ContentCodings, r.http.Header, body); err == nil {
+			body = decoded
+		}
+	}
+
+	if errs := op.validateRequest(r.http, pathValues, body); len(errs) != 0 {
+		r.chain.fail("\nrequest doesn't match OpenAPI operation %s:\n %s",
+			op, strings.Join(errs, "\n "))
+		return false
+	}
+
+	r.operation = op
+
+	return true
+}
+
+func (r *Request) send() *Response {
+	parent := r.config.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	policy := r.config.RetryPolicy
+	refreshed := false
+
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := parent, context.CancelFunc(func() {})
+		if r.config.RequestTimeout > 0 {
+			ctx, cancel = context.WithTimeout(parent, r.config.RequestTimeout)
+		}
+		r.http = r.http.WithContext(ctx)
+		r.chain.request = r.http
+
+		authHeaders, ok := r.authenticate()
+		if !ok || !r.sign(authHeaders) {
+			cancel()
+			return nil
+		}
+
+		if r.config.OpenAPI != nil && !r.validateRequest() {
+			cancel()
+			return nil
+		}
+
+		httpResp, websock, elapsed, err := r.sendAttempt()
+
+		if !refreshed && r.needRefresh(httpResp, err) && r.canReplayBody() {
+			refreshed = true
+
+			ok, refreshErr := r.config.Authenticator.Refresh(
+				r.config.Client, r.http, httpResp)
+			if refreshErr != nil {
+				discardAttempt(httpResp, websock)
+				cancel()
+				r.chain.fail("\nauthentication refresh failed: %s", refreshErr.Error())
+				return nil
+			}
+
+			if ok {
+				discardAttempt(httpResp, websock)
+				cancel()
+
+				if !r.replayBody() {
+					return nil
+				}
+
+				continue
+			}
+		}
+
+		if policy.needRetry(attempt, httpResp, err) && r.canReplayBody() {
+			discardAttempt(httpResp, websock)
+			cancel()
+
+			if !policy.wait(parent, attempt) {
+				r.chain.fail(parent.Err().Error())
+				return nil
+			}
+
+			if !r.replayBody() {
+				return nil
+			}
+
+			continue
+		}
+
+		if err != nil {
+			cancel()
+			r.failSend(parent, err)
 			return nil
 		}
+
+		// response body is read by makeResponse, so context should
+		// not be cancelled before it; event stream body is read later,
+		// so its context is cancelled when the stream is closed
+		resp := makeResponse(responseOpts{
+			config:    r.config,
+			chain:     r.chain,
+			response:  httpResp,
+			websocket: websock,
+			rtt:       &elapsed,
+			attempts:  attempt,
+			cancel:    cancel,
+		})
+
+		if resp.cancel == nil {
+			cancel()
+		}
+
+		if r.operation != nil && !r.wsUpgrade {
+			resp.validateOperation(r.operation)
+		}
+
+		return resp
+	}
+}
+
+// authenticate adds credentials to request and returns names of headers
+// that were set or changed by authenticator.
+func (r *Request) authenticate() ([]string, bool) {
+	if r.config.Authenticator == nil {
+		return nil, true
+	}
+
+	before := cloneHeader(r.http.Header)
+
+	if err := r.config.Authenticator.Authenticate(r.config.Client, r.http); err != nil {
+		r.chain.fail("\nauthentication failed: %s", err.Error())
+		return nil, false
+	}
+
+	var names []string
+	for name, values := range r.http.Header {
+		if !reflect.DeepEqual(before[name], values) {
+			names = append(names, name)
+		}
+	}
+
+	return names, true
+}
+
+// sign adds signature to request. Signer is not allowed to overwrite
+// headers set by authenticator, e.g. when both use "Authorization".
+func (r *Request) sign(authHeaders []string) bool {
+	if r.config.Signer == nil {
+		return true
+	}
+
+	body, err := r.peekBody()
+	if err != nil {
+		r.chain.fail(err.Error())
+		return false
+	}
+
+	authValues := make(map[string][]string, len(authHeaders))
+	for _, name := range authHeaders {
+		authValues[name] = r.http.Header[name]
+	}
+
+	if err := r.config.Signer.Sign(r.http, body); err != nil {
+		r.chain.fail("\nrequest signing failed: %s", err.Error())
+		return false
+	}
+
+	for _, name := range authHeaders {
+		if !reflect.DeepEqual(authValues[name], r.http.Header[name]) {
+			r.chain.fail(
+				"\nrequest signer overwrote %q header set by authenticator,"+
+					" use different header for signature", name)
+			return false
+		}
+	}
+
+	return true
+}
+
+// peekBody returns request body without consuming it.
+func (r *Request) peekBody() ([]byte, error) {
+	if r.http.GetBody != nil {
+		body, err := r.http.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return ioutil.ReadAll(body)
+	}
+
+	if r.http.Body == nil || r.http.Body == http.NoBody {
+		return []byte{}, nil
 	}
 
+	data, err := ioutil.ReadAll(r.http.Body)
+	_ = r.http.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	r.http.GetBody = makeGetBody(bytes.NewReader(data))
+	r.http.Body, _ = r.http.GetBody()
+
+	return data, nil
+}
+
+func (r *Request) needRefresh(httpResp *http.Response, err error) bool {
+	return r.config.Authenticator != nil && err == nil &&
+		httpResp != nil && httpResp.StatusCode == http.StatusUnauthorized
+}
+
+func (r *Request) sendAttempt() (
+	*http.Response, *websocket.Conn, time.Duration, error,
+) {
 	for _, printer := range r.config.Printers {
 		printer.Request(r.http)
 	}
@@ -923,30 +1657,83 @@ func (r *Request) roundTrip() *Response {
 	var (
 		httpResp *http.Response
 		websock  *websocket.Conn
+		err      error
 	)
 	if r.wsUpgrade {
-		httpResp, websock = r.sendWebsocketRequest()
+		httpResp, websock, err = r.sendWebsocketRequest()
 	} else {
-		httpResp = r.sendRequest()
+		httpResp, err = r.sendRequest()
 	}
 
 	elapsed := time.Since(start)
 
-	if httpResp == nil {
-		return nil
+	if err != nil {
+		return nil, nil, elapsed, err
+	}
+
+	// websocket dialer builds its own request, and custom clients may
+	// not set it at all; point response to the request we've printed
+	if httpResp != nil && (r.wsUpgrade || httpResp.Request == nil) {
+		httpResp.Request = r.http
 	}
 
 	for _, printer := range r.config.Printers {
 		printer.Response(httpResp, elapsed)
 	}
 
-	return makeResponse(responseOpts{
-		config:    r.config,
-		chain:     r.chain,
-		response:  httpResp,
-		websocket: websock,
-		rtt:       &elapsed,
-	})
+	return httpResp, websock, elapsed, nil
+}
+
+func discardAttempt(httpResp *http.Response, websock *websocket.Conn) {
+	if websock != nil {
+		_ = websock.Close()
+	}
+	if httpResp != nil && httpResp.Body != nil {
+		_, _ = io.Copy(ioutil.Discard, httpResp.Body)
+		_ = httpResp.Body.Close()
+	}
+}
+
+func (r *Request) canReplayBody() bool {
+	return r.bodySetter == "" || r.http.GetBody != nil
+}
+
+// replayBody prepares request for the next attempt. Every attempt gets
+// its own copy of the request, since previous attempt may still use it,
+// e.g. when handler invoked by Binder didn't return before timeout.
+func (r *Request) replayBody() bool {
+	req := *r.http
+	req.Header = cloneHeader(r.http.Header)
+
+	// Binder sets RequestURI, which is not allowed in client requests
+	req.RequestURI = ""
+
+	if r.bodySetter == "" {
+		req.Body = nil
+	} else {
+		body, err := r.http.GetBody()
+		if err != nil {
+			r.chain.fail(err.Error())
+			return false
+		}
+		req.Body = body
+	}
+
+	r.http = &req
+	r.chain.request = r.http
+
+	return true
+}
+
+func cloneHeader(header http.Header) http.Header {
+	if header == nil {
+		return nil
+	}
+	ret := make(http.Header, len(header))
+	for k, v := range header {
+		ret[k] = append([]string(nil), v...)
+	}
+	return ret
 }
 
 func (r *Request) encodeRequest() bool {
@@ -999,35 +1786,76 @@ func (r *Request) encodeWebsocketRequest() bool {
 	return true
 }
 
-func (r *Request) sendRequest() *http.Response {
-	if r.chain.failed() {
-		return nil
-	}
+func (r *Request) sendRequest() (*http.Response, error) {
+	return r.config.Client.Do(r.http)
+}
 
-	resp, err := r.config.Client.Do(r.http)
+func (r *Request) sendWebsocketRequest() (
+	*http.Response, *websocket.Conn, error,
+) {
+	conn, resp, err := dialWebsocket(r.http.Context(), r.config.WebsocketDialer,
+		r.http.URL.String(), r.http.Header)
 
-	if err != nil {
-		r.chain.fail(err.Error())
-		return nil
+	if err != nil && err != websocket.ErrBadHandshake {
+		return nil, nil, err
 	}
 
-	return resp
+	return resp, conn, nil
 }
 
-func (r *Request) sendWebsocketRequest() (*http.Response, *websocket.Conn) {
-	if r.chain.failed() {
-		return nil, nil
+func dialWebsocket(
+	ctx context.Context, dialer WebsocketDialer, url string, reqH http.Header,
+) (*websocket.Conn, *http.Response, error) {
+	if d, ok := dialer.(WebsocketDialerContext); ok {
+		return d.DialContext(ctx, url, reqH)
 	}
 
-	conn, resp, err := r.config.WebsocketDialer.Dial(
-		r.http.URL.String(), r.http.Header)
+	if ctx.Done() == nil {
+		return dialer.Dial(url, reqH)
+	}
 
-	if err != nil && err != websocket.ErrBadHandshake {
-		r.chain.fail(err.Error())
-		return nil, nil
+	type result struct {
+		conn *websocket.Conn
+		resp *http.Response
+		err  error
 	}
 
-	return resp, conn
+	ch := make(chan result, 1)
+
+	go func() {
+		conn, resp, err := dialer.Dial(url, reqH)
+		ch <- result{conn, resp, err}
+	}()
+
+	select {
+	case res := <-ch:
+		return res.conn, res.resp, res.err
+
+	case <-ctx.Done():
+		go func() {
+			if res := <-ch; res.conn != nil {
+				_ = res.conn.Close()
+			}
+		}()
+		return nil, nil, ctx.Err()
+	}
+}
+
+// failSend reports error returned by sendAttempt. If the request context
+// expired, the message tells whether it was the parent context, i.e.
+// Config.Context or WithContext, or per-attempt RequestTimeout.
+func (r *Request) failSend(parent context.Context, err error) {
+	switch {
+	case parent.Err() == context.DeadlineExceeded:
+		r.chain.fail("\nrequest context deadline exceeded:\n %s", err.Error())
+	case parent.Err() == context.Canceled:
+		r.chain.fail("\nrequest context canceled:\n %s", err.Error())
+	case r.config.RequestTimeout > 0 &&
+		r.http.Context().Err() == context.DeadlineExceeded:
+		r.chain.fail("\nrequest timed out after %s", r.config.RequestTimeout)
+	default:
+		r.chain.fail("%s", err.Error())
+	}
 }
 
 func (r *Request) setType(newSetter, newType string, overwrite bool) {
@@ -1072,9 +1900,46 @@ func (r *Request) setBody(setter string, reader io.Reader, len int, overwrite bo
 		r.http.ContentLength = int64(len)
 	}
 
+	r.http.GetBody = makeGetBody(reader)
+
 	r.bodySetter = setter
 }
 
+// makeGetBody returns a function that returns a new copy of the body, or
+// nil if the body can't be replayed, e.g. if it was set by WithChunked
+// using an arbitrary reader. Used to re-send request.
+func makeGetBody(reader io.Reader) func() (io.ReadCloser, error) {
+	switch v := reader.(type) {
+	case nil:
+		return func() (io.ReadCloser, error) {
+			return http.NoBody, nil
+		}
+
+	case *bytes.Buffer:
+		buf := v.Bytes()
+		return func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(buf)), nil
+		}
+
+	case *bytes.Reader:
+		snapshot := *v
+		return func() (io.ReadCloser, error) {
+			r := snapshot
+			return ioutil.NopCloser(&r), nil
+		}
+
+	case *strings.Reader:
+		snapshot := *v
+		return func() (io.ReadCloser, error) {
+			r := snapshot
+			return ioutil.NopCloser(&r), nil
+		}
+
+	default:
+		return nil
+	}
+}
+
 func concatPaths(a, b string) string {
 	if a == "" {
 		return b
@@ -2,6 +2,7 @@ package httpexpect
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
@@ -11,7 +12,9 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -1320,6 +1323,290 @@ func TestRequestErrorSend(t *testing.T) {
 	assert.True(t, resp.Raw() == nil)
 }
 
+type blockingDialer struct {
+	done chan struct{}
+}
+
+func (d *blockingDialer) Dial(
+	url string, reqH http.Header,
+) (*websocket.Conn, *http.Response, error) {
+	<-d.done
+	return nil, nil, errors.New("closed")
+}
+
+func TestRequestTimeout(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-done
+		}
+	})
+
+	reporter := newMockReporter(t)
+
+	config := Config{
+		RequestFactory: DefaultRequestFactory{},
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+		WebsocketDialer: &blockingDialer{done},
+		Reporter:        reporter,
+	}
+
+	req1 := NewRequest(config, "GET", "/slow")
+	req1.WithTimeout(10 * time.Millisecond)
+	req1.Expect().chain.assertFailed(t)
+
+	req2 := NewRequest(config, "GET", "/fast")
+	req2.WithTimeout(time.Minute)
+	req2.Expect().chain.assertOK(t)
+
+	req3 := NewRequest(config, "GET", "/slow")
+	req3.WithTimeout(-time.Second)
+	req3.chain.assertFailed(t)
+
+	req4 := NewRequest(config, "GET", "/slow")
+	req4.WithWebsocketUpgrade()
+	req4.WithTimeout(10 * time.Millisecond)
+	req4.Expect().chain.assertFailed(t)
+
+	config.RequestTimeout = 10 * time.Millisecond
+
+	req5 := NewRequest(config, "GET", "/slow")
+	req5.Expect().chain.assertFailed(t)
+
+	req6 := NewRequest(config, "GET", "/slow")
+	req6.WithTimeout(0)
+	req6.WithContext(cancelledContext())
+	req6.Expect().chain.assertFailed(t)
+}
+
+func TestRequestTimeoutMessage(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	})
+
+	reporter := newMockFailureReporter(t)
+
+	config := Config{
+		RequestFactory: DefaultRequestFactory{},
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+		Reporter:       reporter,
+		RequestTimeout: 10 * time.Millisecond,
+	}
+
+	NewRequest(config, "GET", "/").Expect().chain.assertFailed(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	NewRequest(config, "GET", "/").
+		WithTimeout(time.Minute).
+		WithContext(ctx).
+		Expect().chain.assertFailed(t)
+
+	NewRequest(config, "GET", "/").
+		WithContext(cancelledContext()).
+		Expect().chain.assertFailed(t)
+
+	if assert.Equal(t, 3, len(reporter.failures)) {
+		assert.Contains(t, reporter.failures[0].Message, "request timed out after 10ms")
+		assert.Contains(t, reporter.failures[1].Message,
+			"request context deadline exceeded")
+		assert.Contains(t, reporter.failures[2].Message, "request context canceled")
+	}
+}
+
+func TestRequestContext(t *testing.T) {
+	client := &mockClient{}
+
+	reporter := newMockReporter(t)
+
+	config := Config{
+		RequestFactory: DefaultRequestFactory{},
+		Client:         client,
+		Reporter:       reporter,
+	}
+
+	type ctxKey struct{}
+
+	ctx1 := context.WithValue(context.Background(), ctxKey{}, "config")
+	ctx2 := context.WithValue(context.Background(), ctxKey{}, "request")
+
+	config.Context = ctx1
+
+	req1 := NewRequest(config, "GET", "/")
+	req1.Expect().chain.assertOK(t)
+	assert.Equal(t, "config", client.req.Context().Value(ctxKey{}))
+
+	req2 := NewRequest(config, "GET", "/")
+	req2.WithContext(ctx2)
+	req2.Expect().chain.assertOK(t)
+	assert.Equal(t, "request", client.req.Context().Value(ctxKey{}))
+
+	req3 := NewRequest(config, "GET", "/")
+	req3.WithContext(nil) //nolint:staticcheck
+	req3.chain.assertFailed(t)
+}
+
+func cancelledContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+func TestRequestExpectEventually(t *testing.T) {
+	count := 0
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		b, _ := ioutil.ReadAll(r.Body)
+		if count < 3 || string(b) != "body" {
+			_, _ = w.Write([]byte("pending"))
+		} else {
+			_, _ = w.Write([]byte("done"))
+		}
+	})
+
+	reporter := newMockReporter(t)
+
+	config := Config{
+		RequestFactory: DefaultRequestFactory{},
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+		Reporter: reporter,
+	}
+
+	req := NewRequest(config, "POST", "/")
+	req.WithText("body")
+
+	matched := 0
+	req.WithMatcher(func(resp *Response) {
+		matched++
+	})
+
+	resp := req.ExpectEventually(time.Minute, time.Millisecond,
+		func(resp *Response) {
+			resp.Body().Equal("done")
+		})
+
+	assert.Equal(t, 3, count)
+	assert.Equal(t, 3, matched)
+	assert.False(t, reporter.reported)
+
+	req.chain.assertOK(t)
+	resp.chain.assertOK(t)
+
+	resp.Body().Equal("pending")
+	assert.True(t, reporter.reported)
+}
+
+func TestRequestExpectEventuallyTimeout(t *testing.T) {
+	count := 0
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	reporter := newMockReporter(t)
+
+	config := Config{
+		RequestFactory: DefaultRequestFactory{},
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+		Reporter: reporter,
+	}
+
+	req := NewRequest(config, "GET", "/")
+
+	resp := req.ExpectEventually(20*time.Millisecond, time.Millisecond,
+		func(resp *Response) {
+			resp.Status(http.StatusOK)
+		})
+
+	assert.True(t, count > 1)
+	assert.True(t, reporter.reported)
+
+	resp.chain.assertFailed(t)
+}
+
+func TestRequestExpectEventuallyErrors(t *testing.T) {
+	client := &mockClient{
+		err: errors.New("error"),
+	}
+
+	reporter1 := newMockReporter(t)
+
+	config := Config{
+		RequestFactory: DefaultRequestFactory{},
+		Client:         client,
+		Reporter:       reporter1,
+	}
+
+	req1 := NewRequest(config, "GET", "/")
+	resp1 := req1.ExpectEventually(time.Millisecond, time.Millisecond, nil)
+
+	assert.True(t, reporter1.reported)
+	req1.chain.assertFailed(t)
+	resp1.chain.assertFailed(t)
+
+	reporter2 := newMockReporter(t)
+
+	config.Client = &mockClient{}
+	config.Reporter = reporter2
+
+	req2 := NewRequest(config, "POST", "/")
+	req2.WithChunked(ioutil.NopCloser(strings.NewReader("body")))
+	resp2 := req2.ExpectEventually(time.Minute, time.Millisecond, nil)
+
+	assert.True(t, reporter2.reported)
+	req2.chain.assertFailed(t)
+	resp2.chain.assertFailed(t)
+}
+
+func TestRequestReplayBody(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	config := Config{
+		RequestFactory: DefaultRequestFactory{},
+		Client:         &mockClient{},
+		Reporter:       reporter,
+	}
+
+	req := NewRequest(config, "POST", "/")
+	req.WithHeader("Foo", "bar")
+	req.WithText("body")
+
+	assert.True(t, req.encode())
+
+	prev := req.http
+	prev.RequestURI = "/"
+
+	assert.True(t, req.replayBody())
+	req.chain.assertOK(t)
+
+	assert.True(t, prev != req.http)
+	assert.Equal(t, "/", prev.RequestURI)
+	assert.Equal(t, "", req.http.RequestURI)
+
+	req.http.Header.Set("Foo", "baz")
+	assert.Equal(t, "bar", prev.Header.Get("Foo"))
+
+	body, err := ioutil.ReadAll(req.http.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "body", string(body))
+}
+
 func TestRequestErrorConflictBody(t *testing.T) {
 	factory := DefaultRequestFactory{}
 
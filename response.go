@@ -2,12 +2,15 @@ package httpexpect
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"mime"
 	"net/http"
+	"net/url"
 	"reflect"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -38,13 +41,19 @@ const (
 
 // Response provides methods to inspect attached http.Response object.
 type Response struct {
-	config    Config
-	chain     chain
-	resp      *http.Response
-	content   []byte
-	cookies   []*http.Cookie
-	websocket *websocket.Conn
-	rtt       *time.Duration
+	config     Config
+	chain      chain
+	resp       *http.Response
+	content    []byte
+	raw        []byte
+	decodeErr  error
+	unread     bool
+	takenBySSE bool
+	cookies    []*http.Cookie
+	websocket  *websocket.Conn
+	rtt        *time.Duration
+	attempts   int
+	cancel     context.CancelFunc
 }
 
 // NewResponse returns a new Response given a reporter used to report
@@ -75,40 +84,110 @@ type responseOpts struct {
 	response  *http.Response
 	websocket *websocket.Conn
 	rtt       *time.Duration
+	attempts  int
+	cancel    context.CancelFunc
 }
 
 func makeResponse(opts responseOpts) *Response {
-	var content []byte
+	var content, raw []byte
+	var decodeErr error
+	var unread bool
 	var cookies []*http.Cookie
+	if opts.attempts == 0 {
+		opts.attempts = 1
+	}
+	opts.chain = opts.chain.root("Response")
+	opts.chain.response = opts.response
+	if opts.chain.request == nil && opts.response != nil {
+		opts.chain.request = opts.response.Request
+	}
 	if opts.response != nil {
-		content = getContent(&opts.chain, opts.response)
+		if isEventStream(opts.response.Header) {
+			// event stream is read later, either by SSE, or when
+			// content is inspected; see readContent
+			unread = true
+		} else {
+			content, raw, decodeErr = getContent(&opts.chain, opts.config, opts.response)
+			opts.cancel = nil
+		}
 		cookies = opts.response.Cookies()
 	} else {
 		opts.chain.fail("expected non-nil response")
+		opts.cancel = nil
 	}
-	return &Response{
+	r := &Response{
 		config:    opts.config,
 		chain:     opts.chain,
 		resp:      opts.response,
 		content:   content,
+		raw:       raw,
+		decodeErr: decodeErr,
+		unread:    unread,
 		cookies:   cookies,
 		websocket: opts.websocket,
 		rtt:       opts.rtt,
+		attempts:  opts.attempts,
+		cancel:    opts.cancel,
+	}
+	if unread {
+		// close the stream if neither SSE nor content is used
+		runtime.SetFinalizer(r, (*Response).release)
 	}
+	return r
 }
 
-func getContent(chain *chain, resp *http.Response) []byte {
+// readContent reads body of event stream response, if it's not read yet.
+// It blocks until the stream is closed by server.
+func (r *Response) readContent() {
+	if !r.unread {
+		return
+	}
+	content, raw, err := getContent(&r.chain, r.config, r.resp)
+	r.release()
+	r.content, r.raw, r.decodeErr = content, raw, err
+}
+
+// release closes body of event stream response and cancels its context,
+// if the body is not read yet and not taken by SSE.
+func (r *Response) release() {
+	if !r.unread {
+		return
+	}
+	r.unread = false
+	runtime.SetFinalizer(r, nil)
+	if r.resp.Body != nil {
+		_ = r.resp.Body.Close()
+	}
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+}
+
+// getContent reads response body and decompresses it according to
+// Content-Encoding header. It returns decompressed and raw body.
+//
+// Decompression error is returned instead of being reported, since it
+// matters only when decompressed body is inspected; see checkContent.
+func getContent(
+	chain *chain, config Config, resp *http.Response,
+) ([]byte, []byte, error) {
 	if resp.Body == nil {
-		return []byte{}
+		return []byte{}, []byte{}, nil
 	}
 
-	content, err := ioutil.ReadAll(resp.Body)
+	raw, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		chain.fail(err.Error())
-		return nil
+		return nil, nil, nil
+	}
+
+	content, err := decodeContent(config.ContentCodings, resp.Header, raw)
+	if err != nil {
+		return nil, raw, err
 	}
 
-	return content
+	return content, raw, nil
 }
 
 // Raw returns underlying http.Response object.
@@ -128,15 +207,31 @@ func (r *Response) Raw() *http.Response {
 //  resp := NewResponse(t, response, time.Duration(10000000))
 //  resp.RoundTripTime().Lt(10 * time.Millisecond)
 func (r *Response) RoundTripTime() *Duration {
-	return &Duration{r.chain, r.rtt}
+	return &Duration{r.chain.enter("RoundTripTime()"), r.rtt}
+}
+
+// Attempts returns a new Number object that may be used to inspect the
+// number of attempts made to receive the response.
+//
+// The number is greater than one only if the request was retried
+// according to RetryPolicy, or sent again after Authenticator refreshed
+// credentials. Round-trip time is measured only for the last attempt.
+//
+// Example:
+//  req := NewRequest(config, "GET", "/path")
+//  req.WithRetryPolicy(&RetryPolicy{MaxAttempts: 5})
+//  resp := req.Expect()
+//  resp.Attempts().Le(5)
+func (r *Response) Attempts() *Number {
+	return &Number{r.chain.enter("Attempts()"), float64(r.attempts)}
 }
 
 // Deprecated: use RoundTripTime instead.
 func (r *Response) Duration() *Number {
 	if r.rtt == nil {
-		return &Number{r.chain, 0}
+		return &Number{r.chain.enter("Duration()"), 0}
 	}
-	return &Number{r.chain, float64(*r.rtt)}
+	return &Number{r.chain.enter("Duration()"), float64(*r.rtt)}
 }
 
 // Status succeeds if response contains given status code.
@@ -224,7 +319,7 @@ func (r *Response) Headers() *Object {
 	if !r.chain.failed() {
 		value, _ = canonMap(&r.chain, r.resp.Header)
 	}
-	return &Object{r.chain, value}
+	return &Object{r.chain.enter("Headers()"), value}
 }
 
 // Header returns a new String object that may be used to inspect given header.
@@ -238,7 +333,119 @@ func (r *Response) Header(header string) *String {
 	if !r.chain.failed() {
 		value = r.resp.Header.Get(header)
 	}
-	return &String{r.chain, value}
+	return &String{r.chain.enter("Header(%q)", header), value}
+}
+
+// CacheControl returns a new CacheControl object that may be used to inspect
+// directives of Cache-Control header.
+//
+// If header is missing, returned object has no directives.
+//
+// Example:
+//  resp := NewResponse(t, response)
+//  resp.CacheControl().Private().MaxAge().Le(time.Hour)
+func (r *Response) CacheControl() *CacheControl {
+	var header http.Header
+	if !r.chain.failed() {
+		header = r.resp.Header
+	}
+	return makeCacheControl(r.chain.enter("CacheControl()"), header)
+}
+
+// ETag returns a new String object that may be used to inspect ETag header.
+// The value is the entity tag as is, including quotes and "W/" prefix for
+// weak tags.
+//
+// If header is missing, failure is reported.
+//
+// Example:
+//  resp := NewResponse(t, response)
+//  resp.ETag().Equal(`"v1"`)
+func (r *Response) ETag() *String {
+	if r.chain.failed() {
+		return &String{r.chain.enter("ETag()"), ""}
+	}
+
+	value := r.resp.Header.Get("ETag")
+	if value == "" {
+		r.chain.fail("\nexpected response with \"ETag\" header")
+	}
+
+	return &String{r.chain.enter("ETag()"), value}
+}
+
+// LastModified returns a new DateTime object that may be used to inspect
+// Last-Modified header.
+//
+// If header is missing or is not a valid HTTP date, failure is reported.
+//
+// Example:
+//  resp := NewResponse(t, response)
+//  resp.LastModified().Lt(time.Now())
+func (r *Response) LastModified() *DateTime {
+	if r.chain.failed() {
+		return &DateTime{r.chain.enter("LastModified()"), time.Time{}}
+	}
+
+	value := r.resp.Header.Get("Last-Modified")
+	if value == "" {
+		r.chain.fail("\nexpected response with \"Last-Modified\" header")
+		return &DateTime{r.chain.enter("LastModified()"), time.Time{}}
+	}
+
+	t, err := http.ParseTime(value)
+	if err != nil {
+		r.chain.fail("\nexpected \"Last-Modified\" header with HTTP date, but got:\n %q",
+			value)
+		return &DateTime{r.chain.enter("LastModified()"), time.Time{}}
+	}
+
+	return &DateTime{r.chain.enter("LastModified()"), t}
+}
+
+// CORS returns a new CORS object that may be used to inspect CORS policy
+// of the response, defined by Access-Control-* headers.
+//
+// Failure is reported if the policy is invalid: if credentials are allowed
+// together with a wildcard, or if origin of the request is echoed in
+// Access-Control-Allow-Origin header, but Vary header doesn't contain
+// Origin.
+//
+// Example:
+//  resp := e.Preflight("/path", "https://app.example.com", "PUT").Expect()
+//  resp.CORS().AllowsOrigin("https://app.example.com").AllowsMethod("PUT")
+func (r *Response) CORS() *CORS {
+	cors := &CORS{}
+	if !r.chain.failed() {
+		cors.parse(&r.chain, r.resp)
+	}
+	cors.chain = r.chain.enter("CORS()")
+	return cors
+}
+
+// Links returns a new Links object that may be used to inspect web links
+// from Link header, as defined in RFC 8288. Relative link targets are
+// resolved against request URL.
+//
+// If Link header is missing, returned Links is empty. If it's malformed,
+// failure is reported.
+//
+// Example:
+//  resp := NewResponse(t, response)
+//  resp.Links().URL("next").Equal("http://example.com/users?page=2")
+func (r *Response) Links() *Links {
+	if r.chain.failed() {
+		return &Links{chain: r.chain.enter("Links()")}
+	}
+
+	var base *url.URL
+	if r.resp.Request != nil {
+		base = r.resp.Request.URL
+	}
+
+	links := parseLinks(&r.chain, r.resp.Header, base)
+
+	return &Links{r.chain.enter("Links()"), links}
 }
 
 // Cookies returns a new Array object with all cookie names set by this response.
@@ -253,13 +460,13 @@ func (r *Response) Header(header string) *String {
 //  resp.Cookies().Contains("session")
 func (r *Response) Cookies() *Array {
 	if r.chain.failed() {
-		return &Array{r.chain, nil}
+		return &Array{r.chain.enter("Cookies()"), nil}
 	}
 	names := []interface{}{}
 	for _, c := range r.cookies {
 		names = append(names, c.Name)
 	}
-	return &Array{r.chain, names}
+	return &Array{r.chain.enter("Cookies()"), names}
 }
 
 // Cookie returns a new Cookie object that may be used to inspect given cookie
@@ -274,18 +481,18 @@ func (r *Response) Cookies() *Array {
 //  resp.Cookie("session").Domain().Equal("example.com")
 func (r *Response) Cookie(name string) *Cookie {
 	if r.chain.failed() {
-		return &Cookie{r.chain, nil}
+		return &Cookie{r.chain.enter("Cookie(%q)", name), nil}
 	}
 	names := []string{}
 	for _, c := range r.cookies {
 		if c.Name == name {
-			return &Cookie{r.chain, c}
+			return &Cookie{r.chain.enter("Cookie(%q)", name), c}
 		}
 		names = append(names, c.Name)
 	}
 	r.chain.fail("\nexpected response with cookie:\n %q\n\nbut got only cookies:\n%s",
 		name, dumpValue(names))
-	return &Cookie{r.chain, nil}
+	return &Cookie{r.chain.enter("Cookie(%q)", name), nil}
 }
 
 // Websocket returns Websocket object that can be used to interact with
@@ -303,7 +510,7 @@ func (r *Response) Websocket() *Websocket {
 	if !r.chain.failed() && r.websocket == nil {
 		r.chain.fail("\nunexpected Websocket call for non-WebSocket response")
 	}
-	return makeWebsocket(r.config, r.chain, r.websocket)
+	return makeWebsocket(r.config, r.chain.enter("Websocket()"), r.websocket)
 }
 
 // Body returns a new String object that may be used to inspect response body.
@@ -313,7 +520,86 @@ func (r *Response) Websocket() *Websocket {
 //  resp.Body().NotEmpty()
 //  resp.Body().Length().Equal(100)
 func (r *Response) Body() *String {
-	return &String{r.chain, string(r.content)}
+	if !r.checkContent() {
+		return &String{r.chain.enter("Body()"), ""}
+	}
+	return &String{r.chain.enter("Body()"), string(r.content)}
+}
+
+// CompressedBody returns a new String object that may be used to inspect
+// response body as it was received, before decompression.
+//
+// Unlike Body, which returns body decompressed according to Content-Encoding
+// header, CompressedBody returns raw bytes. If response is not compressed,
+// CompressedBody is the same as Body.
+//
+// Note that http.Transport transparently decompresses gzip responses if it
+// added Accept-Encoding header itself; in this case, compressed body is not
+// available. Set DisableCompression field of http.Transport to avoid this.
+//
+// Example:
+//  resp := NewResponse(t, response)
+//  resp.CompressedBody().NotEqual("")
+func (r *Response) CompressedBody() *String {
+	if !r.checkCompressed("CompressedBody()") {
+		return &String{r.chain.enter("CompressedBody()"), ""}
+	}
+	return &String{r.chain.enter("CompressedBody()"), string(r.raw)}
+}
+
+// CompressedSize returns a new Number object that may be used to inspect
+// size of response body as it was received, before decompression.
+//
+// It may be used to check that response was actually compressed.
+// See CompressedBody for details.
+//
+// Example:
+//  resp := NewResponse(t, response)
+//  resp.ContentEncoding("gzip")
+//  resp.CompressedSize().Lt(float64(len(resp.Body().Raw())))
+func (r *Response) CompressedSize() *Number {
+	if !r.checkCompressed("CompressedSize()") {
+		return &Number{r.chain.enter("CompressedSize()"), 0}
+	}
+	return &Number{r.chain.enter("CompressedSize()"), float64(len(r.raw))}
+}
+
+func (r *Response) checkCompressed(method string) bool {
+	if r.chain.failed() || !r.checkBody() {
+		return false
+	}
+
+	if r.resp.Uncompressed {
+		r.chain.fail("\n%s: response body was decompressed by http.Transport,"+
+			" compressed body is not available", method)
+		return false
+	}
+
+	return true
+}
+
+// checkContent reports failure if response body can't be decompressed
+// according to Content-Encoding header.
+func (r *Response) checkContent() bool {
+	if !r.checkBody() {
+		return false
+	}
+	if r.decodeErr != nil {
+		r.chain.fail("\nfailed to decompress response body: %s", r.decodeErr.Error())
+		return false
+	}
+	return true
+}
+
+// checkBody reads body of event stream response, if it's not read yet,
+// and reports failure if the body was taken by SSE.
+func (r *Response) checkBody() bool {
+	if r.takenBySSE {
+		r.chain.fail("\nresponse body can't be inspected after SSE() call")
+		return false
+	}
+	r.readContent()
+	return true
 }
 
 // NoContent succeeds if response contains empty Content-Type header and
@@ -326,7 +612,9 @@ func (r *Response) NoContent() *Response {
 	contentType := r.resp.Header.Get("Content-Type")
 
 	r.checkEqual("\"Content-Type\" header", "", contentType)
-	r.checkEqual("body", "", string(r.content))
+	if r.checkContent() {
+		r.checkEqual("body", "", string(r.content))
+	}
 
 	return r
 }
@@ -364,6 +652,31 @@ func (r *Response) TransferEncoding(encoding ...string) *Response {
 	return r
 }
 
+// VerifySignature succeeds if response has valid signature, according to
+// given verifier. Signature is verified over raw response body, before
+// decoding Content-Encoding, as it was sent by server.
+//
+// HMACSigner and HTTPSignatureSigner implement SignatureVerifier.
+//
+// Example:
+//  resp := NewResponse(t, response)
+//  resp.VerifySignature(NewHTTPSignatureSigner(HTTPSignatureOpts{
+//      KeyID: "server-key",
+//      Key:   publicKey,
+//  }))
+func (r *Response) VerifySignature(verifier SignatureVerifier) *Response {
+	if r.chain.failed() {
+		return r
+	}
+
+	if err := verifier.Verify(r.resp, r.raw); err != nil {
+		r.chain.fail(
+			"\nexpected response with valid signature, but got: %s", err.Error())
+	}
+
+	return r
+}
+
 // ContentOpts define parameters for matching the response content parameters.
 type ContentOpts struct {
 	// The media type Content-Type part, e.g. "application/json"
@@ -386,11 +699,12 @@ type ContentOpts struct {
 func (r *Response) Text(opts ...ContentOpts) *String {
 	var content string
 
-	if !r.chain.failed() && r.checkContentOpts(opts, "text/plain") {
+	if !r.chain.failed() && r.checkContentOpts(opts, "text/plain") &&
+		r.checkContent() {
 		content = string(r.content)
 	}
 
-	return &String{r.chain, content}
+	return &String{r.chain.enter("Text()"), content}
 }
 
 // Form returns a new Object that may be used to inspect form contents
@@ -408,7 +722,7 @@ func (r *Response) Text(opts ...ContentOpts) *String {
 //  }).Value("foo").Equal("bar")
 func (r *Response) Form(opts ...ContentOpts) *Object {
 	object := r.getForm(opts...)
-	return &Object{r.chain, object}
+	return &Object{r.chain.enter("Form()"), object}
 }
 
 func (r *Response) getForm(opts ...ContentOpts) map[string]interface{} {
@@ -416,7 +730,8 @@ func (r *Response) getForm(opts ...ContentOpts) map[string]interface{} {
 		return nil
 	}
 
-	if !r.checkContentOpts(opts, "application/x-www-form-urlencoded", "") {
+	if !r.checkContentOpts(opts, "application/x-www-form-urlencoded", "") ||
+		!r.checkContent() {
 		return nil
 	}
 
@@ -434,8 +749,9 @@ func (r *Response) getForm(opts ...ContentOpts) map[string]interface{} {
 // JSON returns a new Value object that may be used to inspect JSON contents
 // of response.
 //
-// JSON succeeds if response contains "application/json" Content-Type header
-// with empty or "utf-8" charset and if JSON may be decoded from response body.
+// JSON succeeds if response contains "application/json" or "+json" suffixed
+// Content-Type header, like "application/problem+json", with empty or "utf-8"
+// charset and if JSON may be decoded from response body.
 //
 // Example:
 //  resp := NewResponse(t, response)
@@ -445,7 +761,7 @@ func (r *Response) getForm(opts ...ContentOpts) map[string]interface{} {
 //  }).Array.Elements("foo", "bar")
 func (r *Response) JSON(opts ...ContentOpts) *Value {
 	value := r.getJSON(opts...)
-	return &Value{r.chain, value}
+	return &Value{r.chain.enter("JSON()").rootJSON(), value}
 }
 
 func (r *Response) getJSON(opts ...ContentOpts) interface{} {
@@ -453,7 +769,8 @@ func (r *Response) getJSON(opts ...ContentOpts) interface{} {
 		return nil
 	}
 
-	if !r.checkContentOpts(opts, "application/json") {
+	if !r.checkContentOpts(opts, jsonMediaType(r.resp.Header.Get("Content-Type"))) ||
+		!r.checkContent() {
 		return nil
 	}
 
@@ -466,6 +783,80 @@ func (r *Response) getJSON(opts ...ContentOpts) interface{} {
 	return value
 }
 
+// XML returns a new XML object that may be used to inspect XML contents
+// of response. The returned object is attached to the root element.
+//
+// XML succeeds if response contains "application/xml", "text/xml", or
+// "+xml" suffixed Content-Type header with empty or "utf-8" charset and
+// if XML may be decoded from response body.
+//
+// Example:
+//  resp := NewResponse(t, response)
+//  resp.XML().Element("user").Attribute("id").Equal("1")
+//  resp.XML(ContentOpts{
+//    MediaType: "application/soap+xml",
+//  }).Element("Body")
+func (r *Response) XML(opts ...ContentOpts) *XML {
+	if !r.chain.failed() && r.checkContentOpts(opts, r.xmlMediaType()) {
+		r.checkContent()
+	}
+	return newXML(r.chain.enter("XML()"), r.content)
+}
+
+// xmlMediaType returns media type expected by XML if none is specified
+// explicitly: the actual media type if it denotes XML, or "application/xml".
+func (r *Response) xmlMediaType() string {
+	mediaType, _, err := mime.ParseMediaType(r.resp.Header.Get("Content-Type"))
+	if err == nil &&
+		(mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")) {
+		return mediaType
+	}
+	return "application/xml"
+}
+
+// jsonMediaType returns media type expected by JSON if none is specified
+// explicitly: the actual media type if it denotes JSON, or "application/json".
+func jsonMediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && isJSONMediaType(mediaType) {
+		return mediaType
+	}
+	return "application/json"
+}
+
+// isJSONMediaType reports whether media type is "application/json" or
+// has "+json" suffix, like "application/problem+json".
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// Multipart returns a new Multipart object that may be used to inspect
+// parts of multipart response, e.g. "multipart/mixed" or
+// "multipart/form-data".
+//
+// Multipart succeeds if response contains "multipart/*" Content-Type
+// header with boundary parameter and if multipart content may be decoded
+// from response body. If media type is given in opts, Content-Type should
+// match it exactly.
+//
+// Example:
+//  resp := NewResponse(t, response)
+//  resp.Multipart().Length().Equal(2)
+//  resp.Multipart().FormPart("meta").JSON().Object().ValueEqual("id", 1)
+//  resp.Multipart(ContentOpts{
+//    MediaType: "multipart/mixed",
+//  }).Part(0).Body().Equal("hello")
+func (r *Response) Multipart(opts ...ContentOpts) *Multipart {
+	var boundary string
+	if !r.chain.failed() {
+		boundary = getBoundary(&r.chain, r.resp.Header.Get("Content-Type"), opts)
+		if boundary != "" {
+			r.checkContent()
+		}
+	}
+	return newMultipart(r.chain.enter("Multipart()"), boundary, r.content)
+}
+
 // JSONP returns a new Value object that may be used to inspect JSONP contents
 // of response.
 //
@@ -485,7 +876,7 @@ func (r *Response) getJSON(opts ...ContentOpts) interface{} {
 //  }).Array.Elements("foo", "bar")
 func (r *Response) JSONP(callback string, opts ...ContentOpts) *Value {
 	value := r.getJSONP(callback, opts...)
-	return &Value{r.chain, value}
+	return &Value{r.chain.enter("JSONP(%q)", callback).rootJSON(), value}
 }
 
 var (
@@ -497,7 +888,7 @@ func (r *Response) getJSONP(callback string, opts ...ContentOpts) interface{} {
 		return nil
 	}
 
-	if !r.checkContentOpts(opts, "application/javascript") {
+	if !r.checkContentOpts(opts, "application/javascript") || !r.checkContent() {
 		return nil
 	}
 
@@ -521,6 +912,25 @@ func (r *Response) getJSONP(callback string, opts ...ContentOpts) interface{} {
 
 func (r *Response) checkContentOpts(
 	opts []ContentOpts, expectedType string, expectedCharset ...string,
+) bool {
+	if r.chain.failed() {
+		return false
+	}
+	return checkContentOpts(&r.chain, r.resp.Header.Get("Content-Type"),
+		opts, expectedType, expectedCharset...)
+}
+
+func (r *Response) checkContentType(expectedType string, expectedCharset ...string) bool {
+	if r.chain.failed() {
+		return false
+	}
+	return checkContentType(&r.chain, r.resp.Header.Get("Content-Type"),
+		expectedType, expectedCharset...)
+}
+
+func checkContentOpts(
+	chain *chain, contentType string,
+	opts []ContentOpts, expectedType string, expectedCharset ...string,
 ) bool {
 	if len(opts) != 0 {
 		if opts[0].MediaType != "" {
@@ -530,16 +940,16 @@ func (r *Response) checkContentOpts(
 			expectedCharset = []string{opts[0].Charset}
 		}
 	}
-	return r.checkContentType(expectedType, expectedCharset...)
+	return checkContentType(chain, contentType, expectedType, expectedCharset...)
 }
 
-func (r *Response) checkContentType(expectedType string, expectedCharset ...string) bool {
-	if r.chain.failed() {
+func checkContentType(
+	chain *chain, contentType string, expectedType string, expectedCharset ...string,
+) bool {
+	if chain.failed() {
 		return false
 	}
 
-	contentType := r.resp.Header.Get("Content-Type")
-
 	if expectedType == "" && len(expectedCharset) == 0 {
 		if contentType == "" {
 			return true
@@ -548,12 +958,12 @@ func (r *Response) checkContentType(expectedType string, expectedCharset ...stri
 
 	mediaType, params, err := mime.ParseMediaType(contentType)
 	if err != nil {
-		r.chain.fail("\ngot invalid \"Content-Type\" header %q", contentType)
+		chain.fail("\ngot invalid \"Content-Type\" header %q", contentType)
 		return false
 	}
 
 	if mediaType != expectedType {
-		r.chain.fail(
+		chain.fail(
 			"\nexpected \"Content-Type\" header with %q media type,"+
 				"\nbut got %q", expectedType, mediaType)
 		return false
@@ -563,14 +973,14 @@ func (r *Response) checkContentType(expectedType string, expectedCharset ...stri
 
 	if len(expectedCharset) == 0 {
 		if charset != "" && !strings.EqualFold(charset, "utf-8") {
-			r.chain.fail(
+			chain.fail(
 				"\nexpected \"Content-Type\" header with \"utf-8\" or empty charset,"+
 					"\nbut got %q", charset)
 			return false
 		}
 	} else {
 		if !strings.EqualFold(charset, expectedCharset[0]) {
-			r.chain.fail(
+			chain.fail(
 				"\nexpected \"Content-Type\" header with %q charset,"+
 					"\nbut got %q", expectedCharset[0], charset)
 			return false
@@ -580,9 +990,20 @@ func (r *Response) checkContentType(expectedType string, expectedCharset ...stri
 	return true
 }
 
+func (r *Response) validateOperation(op *openAPIOperation) {
+	if r.chain.failed() || !r.checkContent() {
+		return
+	}
+	if errs := op.validateResponse(r.resp, r.content); len(errs) != 0 {
+		r.chain.fail("\nresponse doesn't match OpenAPI operation %s:\n %s",
+			op, strings.Join(errs, "\n "))
+	}
+}
+
 func (r *Response) checkEqual(what string, expected, actual interface{}) {
 	if !reflect.DeepEqual(expected, actual) {
-		r.chain.fail("\nexpected %s equal to:\n%s\n\nbut got:\n%s", what,
+		r.chain.failExpected(expected, actual,
+			"\nexpected %s equal to:\n%s\n\nbut got:\n%s", what,
 			dumpValue(expected), dumpValue(actual))
 	}
 }
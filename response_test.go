@@ -716,6 +716,49 @@ func TestResponseJSON(t *testing.T) {
 		map[string]interface{}{"key": "value"}, resp.JSON().Object().Raw())
 }
 
+func TestResponseJSONSuffix(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	for _, contentType := range []string{
+		"application/problem+json",
+		"application/vnd.api+json; charset=utf-8",
+		"application/hal+json",
+	} {
+		resp := NewResponse(reporter, &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {contentType}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"key": "value"}`)),
+		})
+
+		resp.JSON().Object().ValueEqual("key", "value")
+		resp.chain.assertOK(t)
+	}
+
+	for _, contentType := range []string{
+		"application/problem+xml",
+		"text/json+plain",
+		"application/vnd.api+json; charset=latin1",
+	} {
+		resp := NewResponse(reporter, &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {contentType}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"key": "value"}`)),
+		})
+
+		resp.JSON()
+		resp.chain.assertFailed(t)
+	}
+
+	resp := NewResponse(reporter, &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/hal+json"}},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{"key": "value"}`)),
+	})
+
+	resp.JSON(ContentOpts{MediaType: "application/json"})
+	resp.chain.assertFailed(t)
+}
+
 func TestResponseJSONBadBody(t *testing.T) {
 	reporter := newMockReporter(t)
 
@@ -0,0 +1,187 @@
+package httpexpect
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RetryPolicy defines whether and how requests are retried.
+//
+// A request is retried if it failed with an error (e.g. a network error)
+// or received a response, and at least one of RetryOn conditions matches
+// the error or the response. Only the last attempt is reported: if some
+// attempt succeeds, all previous failed attempts are ignored.
+//
+// Every attempt goes through Config.Printers. Request body set by WithBytes,
+// WithText, WithJSON, WithForm, WithMultipart, etc. is buffered and sent
+// again on every attempt. Requests with body set by WithChunked can't be
+// replayed and are never retried, unless the reader is *bytes.Reader,
+// *bytes.Buffer, or *strings.Reader.
+//
+// Example:
+//  e := httpexpect.WithConfig(httpexpect.Config{
+//      BaseURL:  "http://example.com",
+//      Reporter: httpexpect.NewAssertReporter(t),
+//      RetryPolicy: &httpexpect.RetryPolicy{
+//          MaxAttempts: 5,
+//          MinBackoff:  100 * time.Millisecond,
+//          MaxBackoff:  time.Second,
+//          Jitter:      0.2,
+//          RetryOn: []httpexpect.RetryCondition{
+//              httpexpect.RetryTemporaryNetworkErrors,
+//              httpexpect.RetryStatusCodes(http.StatusBadGateway,
+//                  http.StatusServiceUnavailable),
+//          },
+//      },
+//  })
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the
+	// first one. Zero or one means that request is never retried.
+	MaxAttempts int
+
+	// MinBackoff is the delay before the first retry. The delay is
+	// doubled before every next retry.
+	MinBackoff time.Duration
+
+	// MaxBackoff is the maximum delay between retries.
+	// Zero means no limit.
+	MaxBackoff time.Duration
+
+	// Jitter defines randomization of delays, in range [0; 1].
+	// Every delay is decreased by a random fraction of itself,
+	// not greater than Jitter. Zero means no randomization.
+	Jitter float64
+
+	// RetryOn defines when request should be retried. Request is
+	// retried if any of the conditions returns true.
+	//
+	// If empty, RetryTimeoutErrors, RetryTemporaryNetworkErrors,
+	// RetryServerErrors, and RetryTooManyRequests are used.
+	RetryOn []RetryCondition
+}
+
+// RetryCondition decides whether the request should be retried given
+// result of an attempt. Either response or error is nil.
+type RetryCondition func(resp *http.Response, err error) bool
+
+// RetryTimeoutErrors is a RetryCondition that retries requests failed
+// with a timeout, including Config.RequestTimeout and Request.WithTimeout.
+func RetryTimeoutErrors(resp *http.Response, err error) bool {
+	if err == nil {
+		return false
+	}
+	if err == context.DeadlineExceeded {
+		return true
+	}
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return true
+	}
+	return false
+}
+
+// RetryTemporaryNetworkErrors is a RetryCondition that retries requests
+// failed with a temporary network error, e.g. if connection was refused
+// or reset.
+func RetryTemporaryNetworkErrors(resp *http.Response, err error) bool {
+	if err == nil {
+		return false
+	}
+	if ue, ok := err.(*url.Error); ok {
+		err = ue.Err
+	}
+	if oe, ok := err.(*net.OpError); ok && oe.Op == "dial" {
+		return true
+	}
+	if te, ok := err.(interface{ Temporary() bool }); ok && te.Temporary() {
+		return true
+	}
+	return false
+}
+
+// RetryServerErrors is a RetryCondition that retries requests that
+// received a response with 5xx status code.
+func RetryServerErrors(resp *http.Response, err error) bool {
+	return resp != nil && resp.StatusCode >= 500 && resp.StatusCode < 600
+}
+
+// RetryTooManyRequests is a RetryCondition that retries requests that
+// received a response with "429 Too Many Requests" status code.
+func RetryTooManyRequests(resp *http.Response, err error) bool {
+	return resp != nil && resp.StatusCode == http.StatusTooManyRequests
+}
+
+// RetryStatusCodes returns a RetryCondition that retries requests that
+// received a response with any of given status codes.
+func RetryStatusCodes(codes ...int) RetryCondition {
+	return func(resp *http.Response, err error) bool {
+		if resp == nil {
+			return false
+		}
+		for _, code := range codes {
+			if resp.StatusCode == code {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+var defaultRetryConditions = []RetryCondition{
+	RetryTimeoutErrors,
+	RetryTemporaryNetworkErrors,
+	RetryServerErrors,
+	RetryTooManyRequests,
+}
+
+func (p *RetryPolicy) needRetry(attempt int, resp *http.Response, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	conditions := p.RetryOn
+	if len(conditions) == 0 {
+		conditions = defaultRetryConditions
+	}
+
+	for _, cond := range conditions {
+		if cond(resp, err) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.MinBackoff
+	for n := 1; n < attempt; n++ {
+		if (p.MaxBackoff > 0 && delay >= p.MaxBackoff) || delay*2 < delay {
+			break
+		}
+		delay *= 2
+	}
+
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay))
+	}
+
+	return delay
+}
+
+// wait sleeps before next attempt and returns false if ctx is done
+// before the delay is expired.
+func (p *RetryPolicy) wait(ctx context.Context, attempt int) bool {
+	return sleepContext(ctx, p.backoff(attempt))
+}
@@ -0,0 +1,271 @@
+package httpexpect
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type retryHandler struct {
+	failures int
+	status   int
+	bodies   []string
+}
+
+func (h *retryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, _ := ioutil.ReadAll(r.Body)
+	h.bodies = append(h.bodies, string(b))
+
+	if len(h.bodies) <= h.failures {
+		w.WriteHeader(h.status)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+type countingPrinter struct {
+	requests  int
+	responses int
+}
+
+func (p *countingPrinter) Request(*http.Request) {
+	p.requests++
+}
+
+func (p *countingPrinter) Response(*http.Response, time.Duration) {
+	p.responses++
+}
+
+func TestRetrySuccess(t *testing.T) {
+	handler := &retryHandler{failures: 2, status: http.StatusServiceUnavailable}
+	printer := &countingPrinter{}
+
+	config := newBinderConfig(t, handler)
+	config.Printers = []Printer{printer}
+	config.RetryPolicy = &RetryPolicy{
+		MaxAttempts: 3,
+	}
+
+	req := NewRequest(config, "POST", "/")
+	req.WithJSON(map[string]interface{}{"foo": 123})
+
+	resp := req.Expect()
+	resp.chain.assertOK(t)
+
+	resp.Status(http.StatusOK)
+	resp.Attempts().Equal(3)
+	resp.chain.assertOK(t)
+
+	assert.Equal(t, 3, printer.requests)
+	assert.Equal(t, 3, printer.responses)
+
+	assert.Equal(t, []string{
+		`{"foo":123}`,
+		`{"foo":123}`,
+		`{"foo":123}`,
+	}, handler.bodies)
+}
+
+func TestRetryExhausted(t *testing.T) {
+	handler := &retryHandler{failures: 5, status: http.StatusBadGateway}
+
+	config := newBinderConfig(t, handler)
+	config.RetryPolicy = &RetryPolicy{
+		MaxAttempts: 3,
+	}
+
+	resp := NewRequest(config, "GET", "/").Expect()
+	resp.chain.assertOK(t)
+
+	resp.Status(http.StatusBadGateway)
+	resp.Attempts().Equal(3)
+	resp.chain.assertOK(t)
+
+	assert.Equal(t, 3, len(handler.bodies))
+}
+
+func TestRetryConditions(t *testing.T) {
+	handler := &retryHandler{failures: 1, status: http.StatusNotFound}
+
+	config := newBinderConfig(t, handler)
+	config.RetryPolicy = &RetryPolicy{
+		MaxAttempts: 3,
+	}
+
+	resp1 := NewRequest(config, "GET", "/").Expect()
+	resp1.Status(http.StatusNotFound)
+	resp1.Attempts().Equal(1)
+	resp1.chain.assertOK(t)
+
+	handler.bodies = nil
+
+	req2 := NewRequest(config, "GET", "/")
+	req2.WithRetryPolicy(&RetryPolicy{
+		MaxAttempts: 3,
+		RetryOn:     []RetryCondition{RetryStatusCodes(http.StatusNotFound)},
+	})
+
+	resp2 := req2.Expect()
+	resp2.Status(http.StatusOK)
+	resp2.Attempts().Equal(2)
+	resp2.chain.assertOK(t)
+
+	handler.bodies = nil
+
+	req3 := NewRequest(config, "GET", "/")
+	req3.WithRetryPolicy(nil)
+
+	resp3 := req3.Expect()
+	resp3.Status(http.StatusNotFound)
+	resp3.Attempts().Equal(1)
+	resp3.chain.assertOK(t)
+}
+
+func TestRetryBodyReplay(t *testing.T) {
+	handler := &retryHandler{failures: 1, status: http.StatusServiceUnavailable}
+
+	config := newBinderConfig(t, handler)
+	config.RetryPolicy = &RetryPolicy{
+		MaxAttempts: 2,
+	}
+
+	req1 := NewRequest(config, "POST", "/")
+	req1.WithText("hello")
+	resp1 := req1.Expect()
+	resp1.Status(http.StatusOK).Attempts().Equal(2)
+	resp1.chain.assertOK(t)
+	assert.Equal(t, []string{"hello", "hello"}, handler.bodies)
+
+	handler.bodies = nil
+
+	req2 := NewRequest(config, "POST", "/")
+	req2.WithMultipart().WithFormField("foo", "bar")
+	resp2 := req2.Expect()
+	resp2.Status(http.StatusOK).Attempts().Equal(2)
+	resp2.chain.assertOK(t)
+	assert.Equal(t, 2, len(handler.bodies))
+	assert.Equal(t, handler.bodies[0], handler.bodies[1])
+	assert.Contains(t, handler.bodies[1], "bar")
+
+	handler.bodies = nil
+
+	req3 := NewRequest(config, "POST", "/")
+	req3.WithChunked(ioutil.NopCloser(strings.NewReader("hello")))
+	resp3 := req3.Expect()
+	resp3.Status(http.StatusServiceUnavailable).Attempts().Equal(1)
+	resp3.chain.assertOK(t)
+}
+
+func TestRetryTimeout(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	var attempts int32
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			<-done
+		}
+	})
+
+	config := newBinderConfig(t, handler)
+	config.RequestTimeout = 10 * time.Millisecond
+	config.RetryPolicy = &RetryPolicy{
+		MaxAttempts: 2,
+	}
+
+	resp := NewRequest(config, "GET", "/").Expect()
+	resp.Attempts().Equal(2)
+	resp.chain.assertOK(t)
+}
+
+func TestRetryContextCancelled(t *testing.T) {
+	handler := &retryHandler{failures: 5, status: http.StatusServiceUnavailable}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	config := newBinderConfig(t, handler)
+	config.Context = ctx
+	config.RetryPolicy = &RetryPolicy{
+		MaxAttempts: 5,
+		MinBackoff:  time.Hour,
+	}
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	resp := NewRequest(config, "GET", "/").Expect()
+	resp.chain.assertFailed(t)
+
+	assert.Equal(t, 1, len(handler.bodies))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := &RetryPolicy{
+		MinBackoff: 10 * time.Millisecond,
+		MaxBackoff: 50 * time.Millisecond,
+	}
+
+	assert.Equal(t, 10*time.Millisecond, policy.backoff(1))
+	assert.Equal(t, 20*time.Millisecond, policy.backoff(2))
+	assert.Equal(t, 40*time.Millisecond, policy.backoff(3))
+	assert.Equal(t, 50*time.Millisecond, policy.backoff(4))
+	assert.Equal(t, 50*time.Millisecond, policy.backoff(100))
+
+	policy.Jitter = 0.5
+
+	for n := 0; n < 100; n++ {
+		d := policy.backoff(1)
+		assert.True(t, d >= 5*time.Millisecond)
+		assert.True(t, d <= 10*time.Millisecond)
+	}
+
+	unlimited := &RetryPolicy{
+		MinBackoff: time.Second,
+	}
+
+	assert.True(t, unlimited.backoff(1000) > 0)
+}
+
+func TestRetryPredicates(t *testing.T) {
+	resp := func(code int) *http.Response {
+		return &http.Response{StatusCode: code}
+	}
+
+	timeoutErr := &url.Error{Op: "Get", URL: "/", Err: context.DeadlineExceeded}
+	dialErr := &url.Error{Op: "Get", URL: "/", Err: &net.OpError{
+		Op: "dial", Err: errors.New("connection refused"),
+	}}
+	otherErr := errors.New("error")
+
+	assert.True(t, RetryTimeoutErrors(nil, timeoutErr))
+	assert.True(t, RetryTimeoutErrors(nil, context.DeadlineExceeded))
+	assert.False(t, RetryTimeoutErrors(nil, otherErr))
+	assert.False(t, RetryTimeoutErrors(resp(504), nil))
+
+	assert.True(t, RetryTemporaryNetworkErrors(nil, dialErr))
+	assert.False(t, RetryTemporaryNetworkErrors(nil, otherErr))
+	assert.False(t, RetryTemporaryNetworkErrors(resp(503), nil))
+
+	assert.True(t, RetryServerErrors(resp(500), nil))
+	assert.True(t, RetryServerErrors(resp(503), nil))
+	assert.False(t, RetryServerErrors(resp(429), nil))
+	assert.False(t, RetryServerErrors(nil, otherErr))
+
+	assert.True(t, RetryTooManyRequests(resp(429), nil))
+	assert.False(t, RetryTooManyRequests(resp(503), nil))
+
+	cond := RetryStatusCodes(404, 409)
+	assert.True(t, cond(resp(404), nil))
+	assert.True(t, cond(resp(409), nil))
+	assert.False(t, cond(resp(500), nil))
+	assert.False(t, cond(nil, otherErr))
+}
@@ -0,0 +1,706 @@
+package httpexpect
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SecurityHeaders provides methods to inspect security-related headers of
+// response, like Strict-Transport-Security and Content-Security-Policy.
+type SecurityHeaders struct {
+	chain  chain
+	resp   *http.Response
+	header http.Header
+}
+
+// SecurityHeaders returns a new SecurityHeaders object that may be used to
+// inspect security-related headers of response.
+//
+// Example:
+//  resp := NewResponse(t, response)
+//  resp.SecurityHeaders().
+//      HSTS(365*24*time.Hour, true).
+//      ContentTypeOptions().
+//      FrameOptions("DENY")
+func (r *Response) SecurityHeaders() *SecurityHeaders {
+	if r.chain.failed() {
+		return &SecurityHeaders{r.chain.enter("SecurityHeaders()"), nil, http.Header{}}
+	}
+	return &SecurityHeaders{r.chain.enter("SecurityHeaders()"), r.resp, r.resp.Header}
+}
+
+// HSTS succeeds if response has Strict-Transport-Security header with
+// max-age not less than minAge, and, if includeSubdomains is true, with
+// includeSubDomains directive.
+//
+// Example:
+//  resp.SecurityHeaders().HSTS(180*24*time.Hour, false)
+func (s *SecurityHeaders) HSTS(
+	minAge time.Duration, includeSubdomains bool,
+) *SecurityHeaders {
+	if s.chain.failed() {
+		return s
+	}
+	if err := checkHSTS(s.header, minAge, includeSubdomains); err != "" {
+		s.chain.fail("\n%s", err)
+	}
+	return s
+}
+
+// ContentTypeOptions succeeds if response has X-Content-Type-Options
+// header equal to "nosniff".
+func (s *SecurityHeaders) ContentTypeOptions() *SecurityHeaders {
+	if s.chain.failed() {
+		return s
+	}
+	if err := checkContentTypeOptions(s.header); err != "" {
+		s.chain.fail("\n%s", err)
+	}
+	return s
+}
+
+// FrameOptions succeeds if response has X-Frame-Options header equal to
+// one of given values, compared case-insensitively. If no values are
+// given, "DENY" and "SAMEORIGIN" are allowed.
+//
+// Example:
+//  resp.SecurityHeaders().FrameOptions("DENY")
+func (s *SecurityHeaders) FrameOptions(allowed ...string) *SecurityHeaders {
+	if s.chain.failed() {
+		return s
+	}
+	if len(allowed) == 0 {
+		allowed = defaultFrameOptions
+	}
+	if err := checkHeaderOneOf(s.header, "X-Frame-Options", allowed); err != "" {
+		s.chain.fail("\n%s", err)
+	}
+	return s
+}
+
+// ReferrerPolicy succeeds if effective policy from Referrer-Policy header
+// is one of given policies. Effective policy is the last recognized token
+// of the header. If no policies are given, policies that don't leak full
+// URL to other origins are allowed: "no-referrer", "same-origin",
+// "strict-origin", and "strict-origin-when-cross-origin".
+//
+// Example:
+//  resp.SecurityHeaders().ReferrerPolicy("no-referrer")
+func (s *SecurityHeaders) ReferrerPolicy(allowed ...string) *SecurityHeaders {
+	if s.chain.failed() {
+		return s
+	}
+	if len(allowed) == 0 {
+		allowed = defaultReferrerPolicies
+	}
+	if err := checkReferrerPolicy(s.header, allowed); err != "" {
+		s.chain.fail("\n%s", err)
+	}
+	return s
+}
+
+// COOP succeeds if response has Cross-Origin-Opener-Policy header equal
+// to one of given values. If no values are given, "same-origin" is
+// required. Parameters, like report-to, are ignored.
+//
+// Example:
+//  resp.SecurityHeaders().COOP("same-origin", "same-origin-allow-popups")
+func (s *SecurityHeaders) COOP(allowed ...string) *SecurityHeaders {
+	if s.chain.failed() {
+		return s
+	}
+	if len(allowed) == 0 {
+		allowed = []string{"same-origin"}
+	}
+	err := checkHeaderOneOf(s.header, "Cross-Origin-Opener-Policy", allowed)
+	if err != "" {
+		s.chain.fail("\n%s", err)
+	}
+	return s
+}
+
+// COEP succeeds if response has Cross-Origin-Embedder-Policy header equal
+// to one of given values. If no values are given, "require-corp" is
+// required. Parameters, like report-to, are ignored.
+//
+// Example:
+//  resp.SecurityHeaders().COEP("require-corp", "credentialless")
+func (s *SecurityHeaders) COEP(allowed ...string) *SecurityHeaders {
+	if s.chain.failed() {
+		return s
+	}
+	if len(allowed) == 0 {
+		allowed = []string{"require-corp"}
+	}
+	if err := checkHeaderOneOf(
+		s.header, "Cross-Origin-Embedder-Policy", allowed); err != "" {
+		s.chain.fail("\n%s", err)
+	}
+	return s
+}
+
+// PermissionsPolicy returns a new Object that may be used to inspect
+// Permissions-Policy header. Object maps every feature to an array of
+// allowlist entries: "*", "self", "src", or origin. Empty array means
+// that feature is disabled.
+//
+// If header is missing or malformed, failure is reported.
+//
+// Example:
+//  pp := resp.SecurityHeaders().PermissionsPolicy()
+//  pp.Value("camera").Array().Empty()
+//  pp.Value("geolocation").Array().Elements("self")
+func (s *SecurityHeaders) PermissionsPolicy() *Object {
+	if s.chain.failed() {
+		return &Object{s.chain.enter("PermissionsPolicy()"), nil}
+	}
+
+	value := strings.Join(s.header["Permissions-Policy"], ", ")
+	if value == "" {
+		s.chain.fail("\nexpected response with \"Permissions-Policy\" header")
+		return &Object{s.chain.enter("PermissionsPolicy()"), nil}
+	}
+
+	policy, err := parsePermissionsPolicy(value)
+	if err != nil {
+		s.chain.fail("\ninvalid \"Permissions-Policy\" header:\n %q\n\n%s",
+			value, err.Error())
+		return &Object{s.chain.enter("PermissionsPolicy()"), nil}
+	}
+
+	return &Object{s.chain.enter("PermissionsPolicy()"), policy}
+}
+
+// CSP returns a new CSP object that may be used to inspect
+// Content-Security-Policy header.
+//
+// If header is missing, failure is reported.
+//
+// Example:
+//  resp.SecurityHeaders().CSP().
+//      AllowsSource("script-src", "'self'").
+//      NotAllowsSource("script-src", "'unsafe-inline'")
+func (s *SecurityHeaders) CSP() *CSP {
+	csp := &CSP{}
+	if !s.chain.failed() {
+		if len(s.header["Content-Security-Policy"]) == 0 {
+			s.chain.fail("\nexpected response with \"Content-Security-Policy\" header")
+		} else {
+			csp.policies = parseCSP(s.header["Content-Security-Policy"])
+		}
+	}
+	csp.chain = s.chain.enter("CSP()")
+	return csp
+}
+
+// SecurityBaseline defines a policy enforced by SecurityHeaders.Baseline.
+//
+// Zero value defines a reasonable default policy.
+type SecurityBaseline struct {
+	// HSTSMinAge is the minimum max-age of Strict-Transport-Security.
+	// Default is 180 days. HSTS is checked only for HTTPS requests.
+	HSTSMinAge time.Duration
+
+	// HSTSIncludeSubdomains requires includeSubDomains directive in
+	// Strict-Transport-Security header.
+	HSTSIncludeSubdomains bool
+
+	// FrameOptions defines allowed values of X-Frame-Options header.
+	// Default is "DENY" and "SAMEORIGIN". The header is not required if
+	// Content-Security-Policy has frame-ancestors directive.
+	FrameOptions []string
+
+	// ReferrerPolicies defines allowed values of Referrer-Policy header.
+	// Default is the same as in SecurityHeaders.ReferrerPolicy.
+	ReferrerPolicies []string
+
+	// COOP and COEP define allowed values of Cross-Origin-Opener-Policy and
+	// Cross-Origin-Embedder-Policy headers. If empty, the headers are not
+	// checked.
+	COOP []string
+	COEP []string
+
+	// CSPForbiddenSources defines sources that should not be allowed by
+	// Content-Security-Policy for script-src directive. Default is
+	// "'unsafe-inline'" and "'unsafe-eval'".
+	CSPForbiddenSources []string
+
+	// Skip defines names of headers that are not checked, e.g.
+	// "Content-Security-Policy".
+	Skip []string
+}
+
+var (
+	defaultHSTSMinAge       = 180 * 24 * time.Hour
+	defaultFrameOptions     = []string{"DENY", "SAMEORIGIN"}
+	defaultReferrerPolicies = []string{
+		"no-referrer",
+		"same-origin",
+		"strict-origin",
+		"strict-origin-when-cross-origin",
+	}
+	defaultCSPForbiddenSources = []string{"'unsafe-inline'", "'unsafe-eval'"}
+)
+
+// Baseline succeeds if response headers conform to given security
+// baseline. If baseline is omitted, default SecurityBaseline is used.
+//
+// By default, the following is required:
+//  - Strict-Transport-Security with max-age of at least 180 days,
+//    for HTTPS requests
+//  - X-Content-Type-Options equal to "nosniff"
+//  - X-Frame-Options equal to "DENY" or "SAMEORIGIN", or
+//    Content-Security-Policy with frame-ancestors directive
+//  - Referrer-Policy not leaking full URL to other origins
+//  - Content-Security-Policy not allowing inline scripts and eval
+//
+// All violations are reported as a single failure.
+//
+// Baseline may be enforced for every response using Expect.Matcher and
+// SecurityBaselineMatcher.
+//
+// Example:
+//  resp.SecurityHeaders().Baseline(httpexpect.SecurityBaseline{
+//      Skip: []string{"Content-Security-Policy"},
+//  })
+func (s *SecurityHeaders) Baseline(baseline ...SecurityBaseline) *SecurityHeaders {
+	if s.chain.failed() {
+		return s
+	}
+
+	var b SecurityBaseline
+	if len(baseline) != 0 {
+		b = baseline[0]
+	}
+
+	if violations := b.check(s.resp, s.header); len(violations) != 0 {
+		s.chain.fail("\nexpected response conforming to security baseline,"+
+			" but got violations:\n - %s", strings.Join(violations, "\n - "))
+	}
+
+	return s
+}
+
+// SecurityBaselineMatcher returns a matcher that checks every response
+// using SecurityHeaders.Baseline.
+//
+// Example:
+//  e := httpexpect.New(t, "http://example.com").
+//      Matcher(httpexpect.SecurityBaselineMatcher())
+func SecurityBaselineMatcher(baseline ...SecurityBaseline) func(*Response) {
+	return func(resp *Response) {
+		resp.SecurityHeaders().Baseline(baseline...)
+	}
+}
+
+func (b SecurityBaseline) skipped(header string) bool {
+	return containsFold(b.Skip, header)
+}
+
+func (b SecurityBaseline) check(resp *http.Response, header http.Header) []string {
+	var violations []string
+
+	add := func(err string) {
+		if err != "" {
+			violations = append(violations, strings.Replace(err, ":\n ", ": ", -1))
+		}
+	}
+
+	isHTTPS := resp != nil && resp.Request != nil && resp.Request.URL != nil &&
+		resp.Request.URL.Scheme == "https"
+
+	if isHTTPS && !b.skipped("Strict-Transport-Security") {
+		minAge := b.HSTSMinAge
+		if minAge == 0 {
+			minAge = defaultHSTSMinAge
+		}
+		add(checkHSTS(header, minAge, b.HSTSIncludeSubdomains))
+	}
+
+	if !b.skipped("X-Content-Type-Options") {
+		add(checkContentTypeOptions(header))
+	}
+
+	policies := parseCSP(header["Content-Security-Policy"])
+
+	if !b.skipped("X-Frame-Options") {
+		frameAncestors := false
+		for _, p := range policies {
+			if _, ok := p["frame-ancestors"]; ok {
+				frameAncestors = true
+			}
+		}
+		if !frameAncestors {
+			allowed := b.FrameOptions
+			if len(allowed) == 0 {
+				allowed = defaultFrameOptions
+			}
+			add(checkHeaderOneOf(header, "X-Frame-Options", allowed))
+		}
+	}
+
+	if !b.skipped("Referrer-Policy") {
+		allowed := b.ReferrerPolicies
+		if len(allowed) == 0 {
+			allowed = defaultReferrerPolicies
+		}
+		add(checkReferrerPolicy(header, allowed))
+	}
+
+	if !b.skipped("Content-Security-Policy") {
+		if len(policies) == 0 {
+			add("expected response with \"Content-Security-Policy\" header")
+		} else {
+			forbidden := b.CSPForbiddenSources
+			if len(forbidden) == 0 {
+				forbidden = defaultCSPForbiddenSources
+			}
+			for _, source := range forbidden {
+				if cspAllows(policies, "script-src", source) {
+					add(fmt.Sprintf(
+						"expected \"Content-Security-Policy\" not allowing %s"+
+							" for script-src", source))
+				}
+			}
+		}
+	}
+
+	if len(b.COOP) != 0 && !b.skipped("Cross-Origin-Opener-Policy") {
+		add(checkHeaderOneOf(header, "Cross-Origin-Opener-Policy", b.COOP))
+	}
+
+	if len(b.COEP) != 0 && !b.skipped("Cross-Origin-Embedder-Policy") {
+		add(checkHeaderOneOf(header, "Cross-Origin-Embedder-Policy", b.COEP))
+	}
+
+	return violations
+}
+
+func checkHSTS(header http.Header, minAge time.Duration, includeSubdomains bool) string {
+	value := header.Get("Strict-Transport-Security")
+	if value == "" {
+		return "expected response with \"Strict-Transport-Security\" header"
+	}
+
+	directives := parseCacheDirectives(strings.Replace(value, ";", ",", -1))
+
+	sec, err := strconv.ParseUint(directives["max-age"], 10, 32)
+	if err != nil {
+		return fmt.Sprintf(
+			"expected \"Strict-Transport-Security\" header with max-age, but got:\n %q",
+			value)
+	}
+
+	if maxAge := time.Duration(sec) * time.Second; maxAge < minAge {
+		return fmt.Sprintf(
+			"expected \"Strict-Transport-Security\" max-age at least %s, but got:\n %q",
+			minAge, value)
+	}
+
+	if _, ok := directives["includesubdomains"]; includeSubdomains && !ok {
+		return fmt.Sprintf(
+			"expected \"Strict-Transport-Security\" with includeSubDomains,"+
+				" but got:\n %q", value)
+	}
+
+	return ""
+}
+
+func checkContentTypeOptions(header http.Header) string {
+	value := header.Get("X-Content-Type-Options")
+	if !strings.EqualFold(strings.TrimSpace(value), "nosniff") {
+		return fmt.Sprintf(
+			"expected \"X-Content-Type-Options\" header equal to \"nosniff\","+
+				" but got:\n %q", value)
+	}
+	return ""
+}
+
+// checkHeaderOneOf checks that header value, without parameters, is equal
+// to one of allowed values.
+func checkHeaderOneOf(header http.Header, name string, allowed []string) string {
+	value := header.Get(name)
+	token := value
+	if n := strings.IndexByte(token, ';'); n >= 0 {
+		token = token[:n]
+	}
+	if !containsFold(allowed, strings.TrimSpace(token)) {
+		return fmt.Sprintf("expected %q header equal to one of %q, but got:\n %q",
+			name, allowed, value)
+	}
+	return ""
+}
+
+var knownReferrerPolicies = []string{
+	"no-referrer",
+	"no-referrer-when-downgrade",
+	"same-origin",
+	"origin",
+	"strict-origin",
+	"origin-when-cross-origin",
+	"strict-origin-when-cross-origin",
+	"unsafe-url",
+}
+
+func checkReferrerPolicy(header http.Header, allowed []string) string {
+	effective := ""
+	for _, token := range splitHeaderList(header, "Referrer-Policy") {
+		if containsFold(knownReferrerPolicies, token) {
+			effective = strings.ToLower(token)
+		}
+	}
+	if !containsFold(allowed, effective) {
+		return fmt.Sprintf(
+			"expected \"Referrer-Policy\" header equal to one of %q, but got:\n %q",
+			allowed, strings.Join(header["Referrer-Policy"], ", "))
+	}
+	return ""
+}
+
+// parsePermissionsPolicy parses Permissions-Policy structured field
+// dictionary into map from feature to allowlist.
+func parsePermissionsPolicy(value string) (map[string]interface{}, error) {
+	members, err := parseSignatureDictionary(value)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := map[string]interface{}{}
+
+	for _, m := range members {
+		var elems []string
+		if item := m.value; strings.HasPrefix(item, "(") {
+			end := strings.IndexByte(item, ')')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated inner list for %q", m.key)
+			}
+			elems = strings.Fields(item[1:end])
+		} else {
+			if n := strings.IndexByte(item, ';'); n >= 0 {
+				item = item[:n]
+			}
+			elems = []string{strings.TrimSpace(item)}
+		}
+
+		allowlist := []interface{}{}
+		for _, elem := range elems {
+			if strings.HasPrefix(elem, `"`) {
+				unquoted, err := strconv.Unquote(elem)
+				if err != nil {
+					return nil, fmt.Errorf("invalid string %s for %q", elem, m.key)
+				}
+				elem = unquoted
+			}
+			allowlist = append(allowlist, elem)
+		}
+
+		policy[m.key] = allowlist
+	}
+
+	return policy, nil
+}
+
+// CSP provides methods to inspect Content-Security-Policy header.
+//
+// If response has multiple policies, in multiple headers or separated with
+// commas, a source is considered allowed only if it's allowed by every
+// policy.
+type CSP struct {
+	chain    chain
+	policies []map[string][]string
+}
+
+// NewCSP returns a new CSP object given a reporter used to report failures
+// and Content-Security-Policy header value to be inspected.
+//
+// reporter should not be nil.
+//
+// Example:
+//  csp := NewCSP(reporter, "default-src 'self'; img-src *")
+//  csp.AllowsSource("img-src", "https://cdn.example.com")
+func NewCSP(reporter Reporter, value string) *CSP {
+	return &CSP{makeChain(reporter).root("CSP"), parseCSP([]string{value})}
+}
+
+func parseCSP(values []string) []map[string][]string {
+	var policies []map[string][]string
+
+	for _, value := range values {
+		for _, serialized := range strings.Split(value, ",") {
+			policy := map[string][]string{}
+			for _, directive := range strings.Split(serialized, ";") {
+				fields := strings.Fields(directive)
+				if len(fields) == 0 {
+					continue
+				}
+				name := strings.ToLower(fields[0])
+				// duplicate directives are ignored
+				if _, ok := policy[name]; !ok {
+					policy[name] = fields[1:]
+				}
+			}
+			if len(policy) != 0 {
+				policies = append(policies, policy)
+			}
+		}
+	}
+
+	return policies
+}
+
+// Raw returns parsed policies, each mapping lower-cased directive name to
+// its source list.
+func (c *CSP) Raw() []map[string][]string {
+	return c.policies
+}
+
+// Contains succeeds if policy contains given directive.
+//
+// Example:
+//  csp.Contains("frame-ancestors")
+func (c *CSP) Contains(directive string) *CSP {
+	if c.chain.failed() {
+		return c
+	}
+	if _, ok := c.directive(directive); !ok {
+		c.chain.fail("\nexpected Content-Security-Policy containing %q directive",
+			directive)
+	}
+	return c
+}
+
+// NotContains succeeds if policy doesn't contain given directive.
+func (c *CSP) NotContains(directive string) *CSP {
+	if c.chain.failed() {
+		return c
+	}
+	if _, ok := c.directive(directive); ok {
+		c.chain.fail("\nexpected Content-Security-Policy not containing %q directive",
+			directive)
+	}
+	return c
+}
+
+// Directive returns a new Array object that may be used to inspect source
+// list of given directive. Array contains a String value for every source
+// expression.
+//
+// If directive is missing, failure is reported.
+//
+// Example:
+//  csp.Directive("script-src").Contains("'self'")
+func (c *CSP) Directive(name string) *Array {
+	sources, ok := c.directive(name)
+	if !c.chain.failed() && !ok {
+		c.chain.fail("\nexpected Content-Security-Policy containing %q directive", name)
+	}
+	return &Array{c.chain.enter("Directive(%q)", name), stringsToArray(sources)}
+}
+
+// AllowsSource succeeds if given source expression is allowed for given
+// directive, taking into account fallback directives, e.g. default-src
+// for script-src. Source expressions are compared as strings, case-
+// insensitively, so keywords should be quoted, e.g. "'self'". The only
+// exception is "*", which allows any URL, except data:, blob:, and
+// filesystem: ones.
+//
+// Example:
+//  csp.AllowsSource("img-src", "'self'")
+func (c *CSP) AllowsSource(directive, source string) *CSP {
+	if c.chain.failed() {
+		return c
+	}
+	if !cspAllows(c.policies, directive, source) {
+		c.chain.fail("\nexpected Content-Security-Policy allowing %s for %s",
+			source, directive)
+	}
+	return c
+}
+
+// NotAllowsSource succeeds if given source expression is not allowed for
+// given directive. See AllowsSource.
+//
+// Example:
+//  csp.NotAllowsSource("script-src", "'unsafe-inline'")
+func (c *CSP) NotAllowsSource(directive, source string) *CSP {
+	if c.chain.failed() {
+		return c
+	}
+	if cspAllows(c.policies, directive, source) {
+		c.chain.fail("\nexpected Content-Security-Policy not allowing %s for %s",
+			source, directive)
+	}
+	return c
+}
+
+func (c *CSP) directive(name string) ([]string, bool) {
+	name = strings.ToLower(name)
+	for _, policy := range c.policies {
+		if sources, ok := policy[name]; ok {
+			return sources, true
+		}
+	}
+	return nil, false
+}
+
+var cspFallbacks = map[string][]string{
+	"script-src-elem": {"script-src", "default-src"},
+	"script-src-attr": {"script-src", "default-src"},
+	"script-src":      {"default-src"},
+	"style-src-elem":  {"style-src", "default-src"},
+	"style-src-attr":  {"style-src", "default-src"},
+	"style-src":       {"default-src"},
+	"worker-src":      {"child-src", "script-src", "default-src"},
+	"frame-src":       {"child-src", "default-src"},
+	"child-src":       {"default-src"},
+	"connect-src":     {"default-src"},
+	"font-src":        {"default-src"},
+	"img-src":         {"default-src"},
+	"manifest-src":    {"default-src"},
+	"media-src":       {"default-src"},
+	"object-src":      {"default-src"},
+}
+
+// cspAllows reports whether every policy allows source for directive.
+func cspAllows(policies []map[string][]string, directive, source string) bool {
+	directive = strings.ToLower(directive)
+
+	for _, policy := range policies {
+		sources, ok := policy[directive]
+		for _, fallback := range cspFallbacks[directive] {
+			if ok {
+				break
+			}
+			sources, ok = policy[fallback]
+		}
+		if !ok {
+			continue
+		}
+		if !cspSourceListMatches(sources, source) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// cspSourceListMatches reports whether source list contains given source
+// expression. Wildcard "*" matches any URL or scheme, except data:, blob:,
+// and filesystem:, but doesn't match keywords, like "'unsafe-inline'".
+func cspSourceListMatches(sources []string, source string) bool {
+	if containsFold(sources, source) {
+		return true
+	}
+	if !containsFold(sources, "*") || strings.HasPrefix(source, "'") {
+		return false
+	}
+	for _, scheme := range []string{"data:", "blob:", "filesystem:"} {
+		if strings.HasPrefix(strings.ToLower(source), scheme) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,318 @@
+package httpexpect
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func secureHeaders() http.Header {
+	return http.Header{
+		"Strict-Transport-Security": {"max-age=31536000; includeSubDomains; preload"},
+		"X-Content-Type-Options":    {"nosniff"},
+		"X-Frame-Options":           {"DENY"},
+		"Referrer-Policy":           {"no-referrer, strict-origin-when-cross-origin"},
+		"Content-Security-Policy":   {"default-src 'self'; img-src *; object-src 'none'"},
+		"Permissions-Policy": {
+			`camera=(), geolocation=(self "https://maps.example.com")`,
+		},
+		"Cross-Origin-Opener-Policy": {
+			"same-origin; report-to=\"coop\"",
+		},
+		"Cross-Origin-Embedder-Policy": {"require-corp"},
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.com", nil)
+	resp := NewResponse(newMockReporter(t), &http.Response{
+		Header:  secureHeaders(),
+		Request: req,
+	})
+
+	sh := resp.SecurityHeaders()
+
+	sh.HSTS(365*24*time.Hour, true).
+		ContentTypeOptions().
+		FrameOptions().
+		FrameOptions("deny").
+		ReferrerPolicy().
+		ReferrerPolicy("strict-origin-when-cross-origin").
+		COOP().
+		COEP("require-corp", "credentialless")
+	sh.chain.assertOK(t)
+
+	pp := sh.PermissionsPolicy()
+	pp.Value("camera").Array().Empty()
+	pp.Value("geolocation").Array().Elements("self", "https://maps.example.com")
+	pp.chain.assertOK(t)
+
+	for _, fn := range []func(){
+		func() { sh.HSTS(2*365*24*time.Hour, false) },
+		func() { sh.FrameOptions("SAMEORIGIN") },
+		func() { sh.ReferrerPolicy("no-referrer") },
+		func() { sh.COOP("unsafe-none") },
+		func() { sh.COEP("credentialless") },
+	} {
+		fn()
+		sh.chain.assertFailed(t)
+		sh.chain.reset()
+	}
+}
+
+func TestSecurityHeadersMissing(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.com", nil)
+	resp := NewResponse(newMockReporter(t), &http.Response{
+		Header: http.Header{
+			"Strict-Transport-Security": {"max-age=600"},
+			"X-Content-Type-Options":    {"sniff"},
+			"Referrer-Policy":           {"unsafe-url, bogus"},
+			"Permissions-Policy":        {"camera=(self"},
+		},
+		Request: req,
+	})
+
+	sh := resp.SecurityHeaders()
+
+	for _, fn := range []func(){
+		func() { sh.HSTS(0, true) },
+		func() { sh.HSTS(time.Hour, false) },
+		func() { sh.ContentTypeOptions() },
+		func() { sh.FrameOptions() },
+		func() { sh.ReferrerPolicy() },
+		func() { sh.COOP() },
+		func() { sh.COEP() },
+		func() { sh.PermissionsPolicy() },
+		func() { sh.CSP() },
+	} {
+		fn()
+		sh.chain.assertFailed(t)
+		sh.chain.reset()
+	}
+
+	sh.HSTS(time.Minute, false)
+	sh.ReferrerPolicy("unsafe-url")
+	sh.chain.assertOK(t)
+}
+
+func TestSecurityHeadersPermissionsPolicy(t *testing.T) {
+	policy, err := parsePermissionsPolicy(
+		`fullscreen=*, camera=(), usb=self;report-to=x,` +
+			` geolocation=(self "https://a.com")`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"fullscreen":  []interface{}{"*"},
+		"camera":      []interface{}{},
+		"usb":         []interface{}{"self"},
+		"geolocation": []interface{}{"self", "https://a.com"},
+	}, policy)
+
+	_, err = parsePermissionsPolicy(`camera=(self`)
+	assert.Error(t, err)
+}
+
+func TestSecurityHeadersCSP(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	csp := NewCSP(reporter,
+		"default-src 'self'; script-src 'self' https://cdn.example.com; "+
+			"img-src * data:; frame-ancestors 'none'")
+
+	csp.Contains("frame-ancestors").
+		NotContains("style-src").
+		AllowsSource("script-src", "https://cdn.example.com").
+		AllowsSource("script-src-elem", "'self'").
+		AllowsSource("style-src", "'self'").
+		AllowsSource("img-src", "https://any.example.com").
+		AllowsSource("img-src", "data:").
+		AllowsSource("report-to", "anything").
+		NotAllowsSource("script-src", "'unsafe-inline'").
+		NotAllowsSource("style-src", "https://cdn.example.com").
+		NotAllowsSource("img-src", "'unsafe-inline'")
+	csp.chain.assertOK(t)
+
+	csp.Directive("script-src").Elements("'self'", "https://cdn.example.com")
+	csp.Directive("frame-ancestors").Elements("'none'")
+	csp.chain.assertOK(t)
+
+	for _, fn := range []func(){
+		func() { csp.Contains("style-src") },
+		func() { csp.NotContains("img-src") },
+		func() { csp.Directive("style-src") },
+		func() { csp.AllowsSource("script-src", "'unsafe-eval'") },
+		func() { csp.NotAllowsSource("script-src", "'self'") },
+	} {
+		fn()
+		csp.chain.assertFailed(t)
+		csp.chain.reset()
+	}
+
+	// every policy should allow source
+	csp = NewCSP(reporter, "script-src 'self' https://a.com, script-src 'self'")
+
+	csp.AllowsSource("script-src", "'self'").
+		NotAllowsSource("script-src", "https://a.com")
+	csp.chain.assertOK(t)
+
+	csp = NewCSP(reporter, "img-src *")
+
+	csp.NotAllowsSource("img-src", "blob:").
+		AllowsSource("script-src", "'unsafe-inline'")
+	csp.chain.assertOK(t)
+}
+
+func TestSecurityHeadersBaseline(t *testing.T) {
+	httpsReq, _ := http.NewRequest("GET", "https://example.com", nil)
+	httpReq, _ := http.NewRequest("GET", "http://example.com", nil)
+
+	resp := NewResponse(newMockReporter(t), &http.Response{
+		Header:  secureHeaders(),
+		Request: httpsReq,
+	})
+	sh := resp.SecurityHeaders().Baseline()
+	sh.chain.assertOK(t)
+
+	resp = NewResponse(newMockReporter(t), &http.Response{
+		Header:  secureHeaders(),
+		Request: httpsReq,
+	})
+	sh = resp.SecurityHeaders().Baseline(SecurityBaseline{
+		HSTSMinAge:            2 * 365 * 24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+	})
+	sh.chain.assertFailed(t)
+
+	// HSTS is checked only for HTTPS
+	header := secureHeaders()
+	header.Del("Strict-Transport-Security")
+
+	resp = NewResponse(newMockReporter(t), &http.Response{
+		Header:  header,
+		Request: httpReq,
+	})
+	sh = resp.SecurityHeaders().Baseline()
+	sh.chain.assertOK(t)
+
+	resp = NewResponse(newMockReporter(t), &http.Response{
+		Header:  header,
+		Request: httpsReq,
+	})
+	sh = resp.SecurityHeaders().Baseline()
+	sh.chain.assertFailed(t)
+
+	resp = NewResponse(newMockReporter(t), &http.Response{
+		Header:  header,
+		Request: httpsReq,
+	})
+	sh = resp.SecurityHeaders().Baseline(SecurityBaseline{
+		Skip: []string{"strict-transport-security"},
+	})
+	sh.chain.assertOK(t)
+
+	// frame-ancestors replaces X-Frame-Options
+	header = secureHeaders()
+	header.Del("X-Frame-Options")
+
+	resp = NewResponse(newMockReporter(t), &http.Response{
+		Header:  header,
+		Request: httpReq,
+	})
+	sh = resp.SecurityHeaders().Baseline()
+	sh.chain.assertFailed(t)
+
+	header.Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'self'")
+
+	resp = NewResponse(newMockReporter(t), &http.Response{
+		Header:  header,
+		Request: httpReq,
+	})
+	sh = resp.SecurityHeaders().Baseline()
+	sh.chain.assertOK(t)
+
+	// inline scripts
+	header = secureHeaders()
+	header.Set("Content-Security-Policy", "script-src 'self' 'unsafe-inline'")
+
+	resp = NewResponse(newMockReporter(t), &http.Response{
+		Header:  header,
+		Request: httpReq,
+	})
+	sh = resp.SecurityHeaders().Baseline()
+	sh.chain.assertFailed(t)
+
+	resp = NewResponse(newMockReporter(t), &http.Response{
+		Header:  header,
+		Request: httpReq,
+	})
+	sh = resp.SecurityHeaders().Baseline(SecurityBaseline{
+		CSPForbiddenSources: []string{"'unsafe-eval'"},
+	})
+	sh.chain.assertOK(t)
+
+	// COOP and COEP are checked only if configured
+	header = secureHeaders()
+	header.Del("Cross-Origin-Opener-Policy")
+
+	resp = NewResponse(newMockReporter(t), &http.Response{
+		Header:  header,
+		Request: httpReq,
+	})
+	sh = resp.SecurityHeaders().Baseline()
+	sh.chain.assertOK(t)
+
+	resp = NewResponse(newMockReporter(t), &http.Response{
+		Header:  header,
+		Request: httpReq,
+	})
+	sh = resp.SecurityHeaders().Baseline(SecurityBaseline{
+		COOP: []string{"same-origin"},
+	})
+	sh.chain.assertFailed(t)
+}
+
+func TestSecurityHeadersBaselineViolations(t *testing.T) {
+	reporter := newMockFailureReporter(t)
+
+	req, _ := http.NewRequest("GET", "https://example.com", nil)
+	resp := NewResponse(reporter, &http.Response{
+		Header:  http.Header{},
+		Request: req,
+	})
+
+	sh := resp.SecurityHeaders().Baseline()
+	sh.chain.assertFailed(t)
+
+	if assert.Equal(t, 1, len(reporter.failures)) {
+		assert.Equal(t, 5, strings.Count(reporter.failures[0].Message, "\n - "))
+	}
+}
+
+func TestSecurityHeadersMatcher(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for name, values := range secureHeaders() {
+			w.Header()[name] = values
+		}
+		if r.URL.Path == "/insecure" {
+			w.Header().Del("X-Content-Type-Options")
+		}
+	})
+
+	reporter := newMockReporter(t)
+
+	e := WithConfig(Config{
+		BaseURL:  "http://example.com",
+		Reporter: reporter,
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+	}).Matcher(SecurityBaselineMatcher())
+
+	e.GET("/secure").Expect()
+	assert.False(t, reporter.reported)
+
+	e.GET("/insecure").Expect()
+	assert.True(t, reporter.reported)
+}
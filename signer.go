@@ -0,0 +1,256 @@
+package httpexpect
+
+import (
+	"crypto"
+	"crypto/hmac"
+	_ "crypto/sha256" // register hash for HMACSigner
+	_ "crypto/sha512" // register hash for HMACSigner
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signer signs requests.
+//
+// Sign is invoked before every attempt to send request, after request is
+// fully built (URL, query, headers, and body are finalized and credentials
+// are added by Authenticator), and before request is passed to printers.
+// If Sign overwrites a header set by Authenticator, failure is reported.
+//
+// Example:
+//  e := httpexpect.WithConfig(httpexpect.Config{
+//      BaseURL:  "http://example.com",
+//      Reporter: httpexpect.NewAssertReporter(t),
+//      Signer: httpexpect.NewHMACSigner(httpexpect.HMACOpts{
+//          KeyID: "my-key",
+//          Key:   []byte("secret"),
+//      }),
+//  })
+type Signer interface {
+	// Sign adds signature to request, e.g. to its headers. body is the
+	// request body, which should not be modified.
+	Sign(req *http.Request, body []byte) error
+}
+
+// SignatureVerifier verifies signatures of responses.
+// It is used by Response.VerifySignature.
+type SignatureVerifier interface {
+	// Verify returns error if response signature is missing or invalid.
+	// body is the raw response body, before Content-Encoding is decoded.
+	Verify(resp *http.Response, body []byte) error
+}
+
+// HMACOpts defines parameters of HMACSigner.
+type HMACOpts struct {
+	// KeyID identifies the key. May be empty.
+	KeyID string
+
+	// Key is the shared secret key.
+	Key []byte
+
+	// Hash is the hash function. Default is crypto.SHA256.
+	Hash crypto.Hash
+
+	// Header is the header that contains signature.
+	// Default is "Authorization". If Authenticator is used too, and it sets
+	// "Authorization" header, another header should be used here.
+	Header string
+
+	// SignedHeaders is the list of headers covered by signature, in
+	// addition to the method, URL, and body. If it contains "Date" header,
+	// the header is set to current time every time request is signed, so
+	// that retried attempts get fresh date.
+	SignedHeaders []string
+}
+
+// HMACSigner signs requests and verifies responses using HMAC over
+// canonical form of the message.
+//
+// Canonical request consists of the following lines, separated with "\n":
+//  - method
+//  - request URI, i.e. path and query
+//  - one line for every signed header, in form "name:value", with name
+//    lower-cased, and multiple values joined with ","
+//  - empty line
+//  - hex-encoded hash of the body
+//
+// Canonical response is the same, but its first line is status code, and
+// there is no request URI line.
+//
+// Signature is put in header of the following form (with "SHA256"
+// replaced with the name of the hash function):
+//  Authorization: HMAC-SHA256 keyId="my-key", headers="date x-foo", signature="<base64>"
+type HMACSigner struct {
+	opts HMACOpts
+	now  func() time.Time
+}
+
+// NewHMACSigner returns a new HMACSigner with given options.
+//
+// Example:
+//  signer := NewHMACSigner(HMACOpts{
+//      KeyID:         "my-key",
+//      Key:           []byte("secret"),
+//      SignedHeaders: []string{"Date", "Content-Type"},
+//  })
+func NewHMACSigner(opts HMACOpts) *HMACSigner {
+	if opts.Hash == 0 {
+		opts.Hash = crypto.SHA256
+	}
+	if opts.Header == "" {
+		opts.Header = "Authorization"
+	}
+	return &HMACSigner{opts: opts, now: time.Now}
+}
+
+var hmacHashNames = map[crypto.Hash]string{
+	crypto.SHA256: "SHA256",
+	crypto.SHA384: "SHA384",
+	crypto.SHA512: "SHA512",
+}
+
+func (s *HMACSigner) scheme() (string, error) {
+	name, ok := hmacHashNames[s.opts.Hash]
+	if !ok || !s.opts.Hash.Available() {
+		return "", fmt.Errorf("unsupported HMAC hash function %d", s.opts.Hash)
+	}
+	return "HMAC-" + name, nil
+}
+
+// Sign implements Signer.Sign.
+func (s *HMACSigner) Sign(req *http.Request, body []byte) error {
+	scheme, err := s.scheme()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(s.opts.SignedHeaders))
+	for _, name := range s.opts.SignedHeaders {
+		if strings.EqualFold(name, "Date") {
+			req.Header.Set("Date", s.now().UTC().Format(http.TimeFormat))
+		}
+		names = append(names, strings.ToLower(name))
+	}
+
+	canonical := s.canonical(
+		[]string{req.Method, req.URL.RequestURI()}, req.Header, names, body)
+
+	req.Header.Set(s.opts.Header, fmt.Sprintf(
+		"%s keyId=%s, headers=%s, signature=%s",
+		scheme,
+		quoteAuthParam(s.opts.KeyID),
+		quoteAuthParam(strings.Join(names, " ")),
+		quoteAuthParam(base64.StdEncoding.EncodeToString(s.sign(canonical)))))
+
+	return nil
+}
+
+// Verify implements SignatureVerifier.Verify.
+func (s *HMACSigner) Verify(resp *http.Response, body []byte) error {
+	scheme, err := s.scheme()
+	if err != nil {
+		return err
+	}
+
+	header := resp.Header.Get(s.opts.Header)
+	if header == "" {
+		return fmt.Errorf("missing %q header", s.opts.Header)
+	}
+
+	gotScheme, rest := splitAuthHeader(header)
+	if !strings.EqualFold(gotScheme, scheme) {
+		return fmt.Errorf("expected %q signature scheme, but got %q", scheme, gotScheme)
+	}
+
+	params := parseAuthParams(rest)
+
+	if params["keyid"] != s.opts.KeyID {
+		return fmt.Errorf("expected key ID %q, but got %q",
+			s.opts.KeyID, params["keyid"])
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %s", err.Error())
+	}
+
+	var names []string
+	if params["headers"] != "" {
+		names = strings.Split(params["headers"], " ")
+	}
+
+	for _, required := range s.opts.SignedHeaders {
+		found := false
+		for _, name := range names {
+			if strings.EqualFold(name, required) {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("expected signature covering %q header", required)
+		}
+	}
+
+	canonical := s.canonical(
+		[]string{strconv.Itoa(resp.StatusCode)}, resp.Header, names, body)
+
+	if !hmac.Equal(signature, s.sign(canonical)) {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
+
+func (s *HMACSigner) canonical(
+	start []string, header http.Header, names []string, body []byte,
+) string {
+	lines := append([]string{}, start...)
+
+	for _, name := range names {
+		lines = append(lines, name+":"+canonicalHeaderValue(header, name, ","))
+	}
+
+	hasher := s.opts.Hash.New()
+	_, _ = hasher.Write(body)
+
+	lines = append(lines, "", hex.EncodeToString(hasher.Sum(nil)))
+
+	return strings.Join(lines, "\n")
+}
+
+func (s *HMACSigner) sign(canonical string) []byte {
+	mac := hmac.New(s.opts.Hash.New, s.opts.Key)
+	_, _ = mac.Write([]byte(canonical))
+	return mac.Sum(nil)
+}
+
+// canonicalHeaderValue returns all values of given header, with whitespaces
+// trimmed, joined with given separator.
+func canonicalHeaderValue(header http.Header, name string, sep string) string {
+	values := header[http.CanonicalHeaderKey(name)]
+	trimmed := make([]string, 0, len(values))
+	for _, v := range values {
+		trimmed = append(trimmed, strings.TrimSpace(v))
+	}
+	return strings.Join(trimmed, sep)
+}
+
+// canonicalHost returns lower-cased host of request without default port.
+func canonicalHost(req *http.Request) string {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	host = strings.ToLower(host)
+	switch {
+	case strings.HasSuffix(host, ":80") && req.URL.Scheme == "http",
+		strings.HasSuffix(host, ":443") && req.URL.Scheme == "https":
+		host = host[:strings.LastIndexByte(host, ':')]
+	}
+	return host
+}
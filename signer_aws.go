@@ -0,0 +1,208 @@
+package httpexpect
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSSigV4Opts defines parameters of AWSSigV4Signer.
+type AWSSigV4Opts struct {
+	// AccessKeyID and SecretAccessKey are AWS credentials.
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// SessionToken is the temporary session token. May be empty.
+	SessionToken string
+
+	// Region is AWS region, e.g. "us-east-1".
+	Region string
+
+	// Service is the signing name of AWS service, e.g. "execute-api".
+	Service string
+
+	// UnsignedPayload disables signing of request body.
+	UnsignedPayload bool
+}
+
+// AWSSigV4Signer signs requests using AWS Signature Version 4, as used by
+// AWS services and API Gateway.
+//
+// X-Amz-Date header and, if session token is given, X-Amz-Security-Token
+// header are added to request. For "s3" service, or if payload is unsigned,
+// X-Amz-Content-Sha256 header is added as well. All request headers except
+// Authorization and User-Agent are signed.
+//
+// See https://docs.aws.amazon.com/general/latest/gr/signature-version-4.html.
+type AWSSigV4Signer struct {
+	opts AWSSigV4Opts
+	now  func() time.Time
+}
+
+// NewAWSSigV4Signer returns a new AWSSigV4Signer with given options.
+//
+// Example:
+//  signer := NewAWSSigV4Signer(AWSSigV4Opts{
+//      AccessKeyID:     "AKID",
+//      SecretAccessKey: "secret",
+//      Region:          "us-east-1",
+//      Service:         "execute-api",
+//  })
+func NewAWSSigV4Signer(opts AWSSigV4Opts) *AWSSigV4Signer {
+	return &AWSSigV4Signer{opts: opts, now: time.Now}
+}
+
+const awsSigV4Algorithm = "AWS4-HMAC-SHA256"
+
+// Sign implements Signer.Sign.
+func (s *AWSSigV4Signer) Sign(req *http.Request, body []byte) error {
+	if s.opts.AccessKeyID == "" || s.opts.SecretAccessKey == "" {
+		return fmt.Errorf("missing AWS credentials")
+	}
+	if s.opts.Region == "" || s.opts.Service == "" {
+		return fmt.Errorf("missing AWS region or service")
+	}
+
+	t := s.now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+
+	req.Header.Del("Authorization")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	if s.opts.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.opts.SessionToken)
+	}
+
+	payloadHash := "UNSIGNED-PAYLOAD"
+	if !s.opts.UnsignedPayload {
+		payloadHash = hexSHA256(body)
+	}
+
+	if s.opts.Service == "s3" || s.opts.UnsignedPayload {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	canonicalHeaders, signedHeaders := s.canonicalHeaders(req)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s.canonicalURI(req),
+		s.canonicalQuery(req),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join(
+		[]string{date, s.opts.Region, s.opts.Service, "aws4_request"}, "/")
+
+	stringToSign := strings.Join([]string{
+		awsSigV4Algorithm,
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.opts.SecretAccessKey), date)
+	key = hmacSHA256(key, s.opts.Region)
+	key = hmacSHA256(key, s.opts.Service)
+	key = hmacSHA256(key, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		awsSigV4Algorithm, s.opts.AccessKeyID, scope, signedHeaders, signature))
+
+	return nil
+}
+
+func (s *AWSSigV4Signer) canonicalURI(req *http.Request) string {
+	uri := req.URL.EscapedPath()
+	if uri == "" {
+		uri = "/"
+	}
+	// every service except S3 requires path to be encoded twice
+	if s.opts.Service != "s3" {
+		uri = awsURIEncode(uri, true)
+	}
+	return uri
+}
+
+func (s *AWSSigV4Signer) canonicalQuery(req *http.Request) string {
+	query := req.URL.Query()
+
+	pairs := []string{}
+	for _, key := range sortedKeys(query) {
+		values := append([]string{}, query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs,
+				awsURIEncode(key, false)+"="+awsURIEncode(value, false))
+		}
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, "&")
+}
+
+func (s *AWSSigV4Signer) canonicalHeaders(req *http.Request) (string, string) {
+	header := http.Header{}
+	for name, values := range req.Header {
+		switch strings.ToLower(name) {
+		case "authorization", "user-agent", "x-amzn-trace-id":
+			continue
+		}
+		header[strings.ToLower(name)] = values
+	}
+	header["host"] = []string{canonicalHost(req)}
+
+	names := sortedKeys(header)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		values := make([]string, 0, len(header[name]))
+		for _, v := range header[name] {
+			values = append(values, strings.Join(strings.Fields(v), " "))
+		}
+		lines = append(lines, name+":"+strings.Join(values, ",")+"\n")
+	}
+
+	return strings.Join(lines, ""), strings.Join(names, ";")
+}
+
+// awsURIEncode encodes all characters except unreserved ones, as defined
+// by AWS Signature Version 4.
+func awsURIEncode(s string, path bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && path:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
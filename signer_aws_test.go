@@ -0,0 +1,104 @@
+package httpexpect
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newAWSTestSigner(opts AWSSigV4Opts) *AWSSigV4Signer {
+	opts.AccessKeyID = "AKIDEXAMPLE"
+	opts.SecretAccessKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	opts.Region = "us-east-1"
+
+	signer := NewAWSSigV4Signer(opts)
+	signer.now = func() time.Time {
+		return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	}
+	return signer
+}
+
+func TestAWSSigV4Vanilla(t *testing.T) {
+	cases := []struct {
+		url       string
+		signature string
+	}{
+		{
+			url:       "http://example.amazonaws.com/",
+			signature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			url:       "http://example.amazonaws.com/?Param2=value2&Param1=value1",
+			signature: "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+	}
+
+	for _, tc := range cases {
+		signer := newAWSTestSigner(AWSSigV4Opts{Service: "service"})
+
+		req, _ := http.NewRequest("GET", tc.url, nil)
+
+		assert.NoError(t, signer.Sign(req, []byte{}))
+
+		assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+		assert.Equal(t, "AWS4-HMAC-SHA256 "+
+			"Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+			"SignedHeaders=host;x-amz-date, "+
+			"Signature="+tc.signature,
+			req.Header.Get("Authorization"))
+	}
+}
+
+func TestAWSSigV4Headers(t *testing.T) {
+	signer := newAWSTestSigner(AWSSigV4Opts{
+		Service:      "s3",
+		SessionToken: "token",
+	})
+
+	req, _ := http.NewRequest("PUT", "http://bucket.s3.amazonaws.com/a%20b", nil)
+	req.Header.Set("User-Agent", "test")
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("Authorization", "Bearer stale")
+
+	assert.NoError(t, signer.Sign(req, []byte("hello")))
+
+	assert.Equal(t, "token", req.Header.Get("X-Amz-Security-Token"))
+	assert.Equal(t, hexSHA256([]byte("hello")), req.Header.Get("X-Amz-Content-Sha256"))
+
+	auth := req.Header.Get("Authorization")
+	assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 "))
+	assert.Contains(t, auth, "SignedHeaders=content-type;host;x-amz-content-sha256;"+
+		"x-amz-date;x-amz-security-token,")
+
+	assert.Equal(t, "/a%20b", signer.canonicalURI(req))
+
+	signer = newAWSTestSigner(AWSSigV4Opts{
+		Service:         "execute-api",
+		UnsignedPayload: true,
+	})
+
+	req, _ = http.NewRequest("PUT", "http://example.com/a%20b", nil)
+
+	assert.NoError(t, signer.Sign(req, []byte("hello")))
+	assert.Equal(t, "UNSIGNED-PAYLOAD", req.Header.Get("X-Amz-Content-Sha256"))
+	assert.Equal(t, "/a%2520b", signer.canonicalURI(req))
+}
+
+func TestAWSSigV4Errors(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com/", nil)
+
+	signer := NewAWSSigV4Signer(AWSSigV4Opts{
+		Region:  "us-east-1",
+		Service: "service",
+	})
+	assert.Error(t, signer.Sign(req, nil))
+
+	signer = NewAWSSigV4Signer(AWSSigV4Opts{
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+	})
+	assert.Error(t, signer.Sign(req, nil))
+}
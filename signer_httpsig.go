@@ -0,0 +1,668 @@
+package httpexpect
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPSignatureOpts defines parameters of HTTPSignatureSigner.
+type HTTPSignatureOpts struct {
+	// Label is the signature label. Default is "sig1".
+	//
+	// When verifying, signature with this label is used. If label is
+	// empty, the first signature is used.
+	Label string
+
+	// KeyID identifies the key. May be empty.
+	KeyID string
+
+	// Algorithm is the signature algorithm. Supported algorithms are
+	// "hmac-sha256", "rsa-pss-sha512", "rsa-v1_5-sha256",
+	// "ecdsa-p256-sha256", and "ecdsa-p384-sha384".
+	//
+	// If empty, algorithm is derived from the key, and "alg" parameter
+	// is not included in signature.
+	Algorithm string
+
+	// Key is the key used to sign or verify messages:
+	//  - []byte for "hmac-sha256"
+	//  - *rsa.PrivateKey for "rsa-pss-sha512" and "rsa-v1_5-sha256"
+	//  - *ecdsa.PrivateKey for "ecdsa-p256-sha256" and "ecdsa-p384-sha384"
+	//
+	// When verifying, *rsa.PublicKey and *ecdsa.PublicKey may be used too.
+	Key interface{}
+
+	// Components is the list of covered components: derived components,
+	// like "@method", "@authority", "@path", "@query", "@target-uri",
+	// "@scheme", "@request-target", "@status", and header names.
+	//
+	// Default for requests is "@method", "@authority", "@path", plus
+	// "@query" if URL has query, and "content-digest" if request has body.
+	// Default for responses is "@status", plus "content-digest" if
+	// response has body.
+	//
+	// If "content-digest" is covered, and request has no Content-Digest
+	// header, the header is set to SHA-256 digest of the body. When
+	// verifying, Content-Digest header is checked against the body.
+	Components []string
+
+	// Expires defines how long signature is valid. Zero means that
+	// "expires" parameter is not included.
+	Expires time.Duration
+
+	// Nonce and Tag are optional signature parameters.
+	Nonce string
+	Tag   string
+
+	// SignFunc and VerifyFunc may be used instead of Key to implement
+	// custom algorithms, e.g. "ed25519". They receive the signature base.
+	SignFunc   func(base []byte) ([]byte, error)
+	VerifyFunc func(base []byte, signature []byte) error
+}
+
+// HTTPSignatureSigner signs requests and verifies responses using HTTP
+// Message Signatures, as defined in RFC 9421.
+//
+// Signature is put in Signature-Input and Signature headers. If message
+// already has signatures, the new one is appended.
+type HTTPSignatureSigner struct {
+	opts HTTPSignatureOpts
+	now  func() time.Time
+}
+
+// NewHTTPSignatureSigner returns a new HTTPSignatureSigner with given options.
+//
+// Example:
+//  signer := NewHTTPSignatureSigner(HTTPSignatureOpts{
+//      KeyID:      "my-key",
+//      Key:        privateKey,
+//      Algorithm:  "rsa-pss-sha512",
+//      Components: []string{"@method", "@target-uri", "content-digest"},
+//  })
+func NewHTTPSignatureSigner(opts HTTPSignatureOpts) *HTTPSignatureSigner {
+	return &HTTPSignatureSigner{opts: opts, now: time.Now}
+}
+
+// httpsigMessage holds data of request or response used to build
+// signature base.
+type httpsigMessage struct {
+	req    *http.Request
+	status int
+	header http.Header
+}
+
+func (m httpsigMessage) component(name string) (string, error) {
+	if !strings.HasPrefix(name, "@") {
+		if _, ok := m.header[http.CanonicalHeaderKey(name)]; !ok {
+			return "", fmt.Errorf("missing %q header for covered component", name)
+		}
+		return canonicalHeaderValue(m.header, name, ", "), nil
+	}
+
+	if name == "@status" {
+		if m.req != nil {
+			return "", errors.New("\"@status\" component is not allowed in request")
+		}
+		return strconv.Itoa(m.status), nil
+	}
+
+	if m.req == nil {
+		return "", fmt.Errorf("unsupported response component %q", name)
+	}
+
+	u := m.req.URL
+
+	switch name {
+	case "@method":
+		return m.req.Method, nil
+	case "@target-uri":
+		return u.Scheme + "://" + canonicalHost(m.req) + u.RequestURI(), nil
+	case "@authority":
+		return canonicalHost(m.req), nil
+	case "@scheme":
+		return strings.ToLower(u.Scheme), nil
+	case "@request-target":
+		return u.RequestURI(), nil
+	case "@path":
+		if path := u.EscapedPath(); path != "" {
+			return path, nil
+		}
+		return "/", nil
+	case "@query":
+		return "?" + u.RawQuery, nil
+	default:
+		return "", fmt.Errorf("unsupported component %q", name)
+	}
+}
+
+// signatureBase builds signature base for given components and serialized
+// signature parameters.
+func (m httpsigMessage) signatureBase(
+	components []string, params string,
+) ([]byte, error) {
+	var b bytes.Buffer
+	for _, name := range components {
+		value, err := m.component(name)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "%q: %s\n", name, value)
+	}
+	fmt.Fprintf(&b, "%q: %s", "@signature-params", params)
+	return b.Bytes(), nil
+}
+
+// Sign implements Signer.Sign.
+func (s *HTTPSignatureSigner) Sign(req *http.Request, body []byte) error {
+	components := s.opts.Components
+	if components == nil {
+		components = []string{"@method", "@authority", "@path"}
+		if req.URL.RawQuery != "" {
+			components = append(components, "@query")
+		}
+		if len(body) != 0 {
+			components = append(components, "content-digest")
+		}
+	}
+
+	for _, name := range components {
+		if name == "content-digest" && req.Header.Get("Content-Digest") == "" {
+			req.Header.Set("Content-Digest", contentDigest(body))
+		}
+	}
+
+	algorithm, err := s.algorithm()
+	if err != nil {
+		return err
+	}
+
+	params := s.serializeParams(components)
+
+	base, err := httpsigMessage{req: req, header: req.Header}.
+		signatureBase(components, params)
+	if err != nil {
+		return err
+	}
+
+	var signature []byte
+	if s.opts.SignFunc != nil {
+		signature, err = s.opts.SignFunc(base)
+	} else {
+		signature, err = httpsigSign(algorithm, s.opts.Key, base)
+	}
+	if err != nil {
+		return err
+	}
+
+	label := s.opts.Label
+	if label == "" {
+		label = "sig1"
+	}
+
+	appendHeader(req.Header, "Signature-Input", label+"="+params)
+	appendHeader(req.Header, "Signature",
+		label+"=:"+base64.StdEncoding.EncodeToString(signature)+":")
+
+	return nil
+}
+
+// Verify implements SignatureVerifier.Verify.
+func (s *HTTPSignatureSigner) Verify(resp *http.Response, body []byte) error {
+	inputs, err := parseSignatureDictionary(resp.Header.Get("Signature-Input"))
+	if err != nil {
+		return fmt.Errorf("invalid \"Signature-Input\" header: %s", err.Error())
+	}
+	if len(inputs) == 0 {
+		return errors.New("missing \"Signature-Input\" header")
+	}
+
+	signatures, err := parseSignatureDictionary(resp.Header.Get("Signature"))
+	if err != nil {
+		return fmt.Errorf("invalid \"Signature\" header: %s", err.Error())
+	}
+
+	label := s.opts.Label
+	if label == "" {
+		label = inputs[0].key
+	}
+
+	input, ok := findSignatureMember(inputs, label)
+	if !ok {
+		return fmt.Errorf("missing signature input with label %q", label)
+	}
+
+	sigValue, ok := findSignatureMember(signatures, label)
+	if !ok {
+		return fmt.Errorf("missing signature with label %q", label)
+	}
+
+	if !strings.HasPrefix(sigValue, ":") || !strings.HasSuffix(sigValue, ":") ||
+		len(sigValue) < 2 {
+		return fmt.Errorf("invalid signature value %q", sigValue)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(sigValue[1 : len(sigValue)-1])
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %s", err.Error())
+	}
+
+	components, params, err := parseSignatureInput(input)
+	if err != nil {
+		return fmt.Errorf("invalid signature input %q: %s", input, err.Error())
+	}
+
+	if s.opts.KeyID != "" && params["keyid"] != s.opts.KeyID {
+		return fmt.Errorf("expected key ID %q, but got %q",
+			s.opts.KeyID, params["keyid"])
+	}
+
+	algorithm, err := s.algorithm()
+	if err != nil {
+		return err
+	}
+	if alg, ok := params["alg"]; ok && s.opts.SignFunc == nil &&
+		s.opts.VerifyFunc == nil && alg != algorithm {
+		return fmt.Errorf("expected algorithm %q, but got %q", algorithm, alg)
+	}
+
+	if expires, ok := params["expires"]; ok {
+		sec, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid expires parameter %q", expires)
+		}
+		if !s.now().Before(time.Unix(sec, 0)) {
+			return errors.New("signature expired")
+		}
+	}
+
+	expected := s.opts.Components
+	if expected == nil {
+		expected = []string{"@status"}
+		if len(body) != 0 {
+			expected = append(expected, "content-digest")
+		}
+	}
+	for _, name := range expected {
+		covered := false
+		for _, c := range components {
+			if c == name {
+				covered = true
+			}
+		}
+		if !covered {
+			return fmt.Errorf("expected signature covering %q component", name)
+		}
+	}
+
+	for _, name := range components {
+		if name == "content-digest" {
+			digest := resp.Header.Get("Content-Digest")
+			if err := checkContentDigest(digest, body); err != nil {
+				return err
+			}
+		}
+	}
+
+	base, err := httpsigMessage{status: resp.StatusCode, header: resp.Header}.
+		signatureBase(components, input)
+	if err != nil {
+		return err
+	}
+
+	if s.opts.VerifyFunc != nil {
+		return s.opts.VerifyFunc(base, signature)
+	}
+
+	return httpsigVerify(algorithm, s.opts.Key, base, signature)
+}
+
+func (s *HTTPSignatureSigner) algorithm() (string, error) {
+	if s.opts.Algorithm != "" || s.opts.SignFunc != nil || s.opts.VerifyFunc != nil {
+		return s.opts.Algorithm, nil
+	}
+
+	switch key := s.opts.Key.(type) {
+	case []byte:
+		return "hmac-sha256", nil
+	case *rsa.PrivateKey, *rsa.PublicKey:
+		return "rsa-pss-sha512", nil
+	case *ecdsa.PrivateKey:
+		return ecdsaAlgorithm(&key.PublicKey)
+	case *ecdsa.PublicKey:
+		return ecdsaAlgorithm(key)
+	default:
+		return "", fmt.Errorf("unsupported signature key type %T", s.opts.Key)
+	}
+}
+
+func ecdsaAlgorithm(key *ecdsa.PublicKey) (string, error) {
+	switch key.Curve.Params().BitSize {
+	case 256:
+		return "ecdsa-p256-sha256", nil
+	case 384:
+		return "ecdsa-p384-sha384", nil
+	default:
+		return "", fmt.Errorf("unsupported ECDSA curve %s", key.Curve.Params().Name)
+	}
+}
+
+func (s *HTTPSignatureSigner) serializeParams(components []string) string {
+	var b strings.Builder
+
+	b.WriteByte('(')
+	for n, name := range components {
+		if n != 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(strconv.Quote(strings.ToLower(name)))
+	}
+	b.WriteByte(')')
+
+	created := s.now()
+	fmt.Fprintf(&b, ";created=%d", created.Unix())
+	if s.opts.Expires != 0 {
+		fmt.Fprintf(&b, ";expires=%d", created.Add(s.opts.Expires).Unix())
+	}
+	if s.opts.Nonce != "" {
+		fmt.Fprintf(&b, ";nonce=%q", s.opts.Nonce)
+	}
+	if s.opts.Algorithm != "" {
+		fmt.Fprintf(&b, ";alg=%q", s.opts.Algorithm)
+	}
+	if s.opts.KeyID != "" {
+		fmt.Fprintf(&b, ";keyid=%q", s.opts.KeyID)
+	}
+	if s.opts.Tag != "" {
+		fmt.Fprintf(&b, ";tag=%q", s.opts.Tag)
+	}
+
+	return b.String()
+}
+
+func httpsigSign(algorithm string, key interface{}, base []byte) ([]byte, error) {
+	switch algorithm {
+	case "hmac-sha256":
+		secret, ok := key.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("expected []byte key for %q, but got %T",
+				algorithm, key)
+		}
+		mac := hmac.New(sha256.New, secret)
+		_, _ = mac.Write(base)
+		return mac.Sum(nil), nil
+
+	case "rsa-pss-sha512", "rsa-v1_5-sha256":
+		private, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf(
+				"expected *rsa.PrivateKey key for %q, but got %T", algorithm, key)
+		}
+		if algorithm == "rsa-pss-sha512" {
+			digest := sha512.Sum512(base)
+			return rsa.SignPSS(rand.Reader, private, crypto.SHA512, digest[:],
+				&rsa.PSSOptions{SaltLength: 64})
+		}
+		digest := sha256.Sum256(base)
+		return rsa.SignPKCS1v15(rand.Reader, private, crypto.SHA256, digest[:])
+
+	case "ecdsa-p256-sha256", "ecdsa-p384-sha384":
+		private, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf(
+				"expected *ecdsa.PrivateKey key for %q, but got %T", algorithm, key)
+		}
+		digest, size := ecdsaDigest(algorithm, base)
+		r, s, err := ecdsa.Sign(rand.Reader, private, digest)
+		if err != nil {
+			return nil, err
+		}
+		signature := make([]byte, 2*size)
+		rb, sb := r.Bytes(), s.Bytes()
+		copy(signature[size-len(rb):size], rb)
+		copy(signature[2*size-len(sb):], sb)
+		return signature, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported signature algorithm %q", algorithm)
+	}
+}
+
+func httpsigVerify(
+	algorithm string, key interface{}, base []byte, signature []byte,
+) error {
+	switch algorithm {
+	case "hmac-sha256":
+		expected, err := httpsigSign(algorithm, key, base)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal(signature, expected) {
+			return errors.New("signature mismatch")
+		}
+		return nil
+
+	case "rsa-pss-sha512", "rsa-v1_5-sha256":
+		var public *rsa.PublicKey
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			public = &k.PublicKey
+		case *rsa.PublicKey:
+			public = k
+		default:
+			return fmt.Errorf("expected RSA key for %q, but got %T", algorithm, key)
+		}
+		var err error
+		if algorithm == "rsa-pss-sha512" {
+			digest := sha512.Sum512(base)
+			err = rsa.VerifyPSS(public, crypto.SHA512, digest[:], signature, nil)
+		} else {
+			digest := sha256.Sum256(base)
+			err = rsa.VerifyPKCS1v15(public, crypto.SHA256, digest[:], signature)
+		}
+		if err != nil {
+			return errors.New("signature mismatch")
+		}
+		return nil
+
+	case "ecdsa-p256-sha256", "ecdsa-p384-sha384":
+		var public *ecdsa.PublicKey
+		switch k := key.(type) {
+		case *ecdsa.PrivateKey:
+			public = &k.PublicKey
+		case *ecdsa.PublicKey:
+			public = k
+		default:
+			return fmt.Errorf("expected ECDSA key for %q, but got %T", algorithm, key)
+		}
+		digest, size := ecdsaDigest(algorithm, base)
+		if len(signature) != 2*size {
+			return errors.New("signature mismatch")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(public, digest, r, s) {
+			return errors.New("signature mismatch")
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported signature algorithm %q", algorithm)
+	}
+}
+
+func ecdsaDigest(algorithm string, base []byte) ([]byte, int) {
+	if algorithm == "ecdsa-p384-sha384" {
+		digest := sha512.Sum384(base)
+		return digest[:], 48
+	}
+	digest := sha256.Sum256(base)
+	return digest[:], 32
+}
+
+// contentDigest returns Content-Digest header value, as defined in RFC 9530.
+func contentDigest(body []byte) string {
+	digest := sha256.Sum256(body)
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(digest[:]) + ":"
+}
+
+func checkContentDigest(header string, body []byte) error {
+	if header == "" {
+		return errors.New("missing \"Content-Digest\" header")
+	}
+
+	digests, err := parseSignatureDictionary(header)
+	if err != nil {
+		return fmt.Errorf("invalid \"Content-Digest\" header: %s", err.Error())
+	}
+
+	for _, d := range digests {
+		var sum []byte
+		switch d.key {
+		case "sha-256":
+			s := sha256.Sum256(body)
+			sum = s[:]
+		case "sha-512":
+			s := sha512.Sum512(body)
+			sum = s[:]
+		default:
+			continue
+		}
+		expected := ":" + base64.StdEncoding.EncodeToString(sum) + ":"
+		if d.value != expected {
+			return fmt.Errorf("\"Content-Digest\" header doesn't match body")
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unsupported \"Content-Digest\" algorithm in %q", header)
+}
+
+type signatureMember struct {
+	key   string
+	value string
+}
+
+// parseSignatureDictionary splits structured field dictionary into
+// members, keeping their values unparsed.
+func parseSignatureDictionary(s string) ([]signatureMember, error) {
+	members := []signatureMember{}
+
+	for s = strings.TrimSpace(s); s != ""; {
+		n := strings.IndexByte(s, '=')
+		if n <= 0 {
+			return nil, fmt.Errorf("expected key=value pair in %q", s)
+		}
+
+		key := strings.TrimSpace(s[:n])
+		s = s[n+1:]
+
+		end := len(s)
+		quoted, depth := false, 0
+	loop:
+		for i := 0; i < len(s); i++ {
+			switch c := s[i]; {
+			case quoted && c == '\\':
+				i++
+			case c == '"':
+				quoted = !quoted
+			case quoted:
+			case c == '(':
+				depth++
+			case c == ')':
+				depth--
+			case c == ',' && depth == 0:
+				end = i
+				break loop
+			}
+		}
+
+		members = append(members, signatureMember{
+			key:   key,
+			value: strings.TrimSpace(s[:end]),
+		})
+
+		if end < len(s) {
+			end++
+		}
+		s = strings.TrimSpace(s[end:])
+	}
+
+	return members, nil
+}
+
+func findSignatureMember(members []signatureMember, key string) (string, bool) {
+	for _, m := range members {
+		if m.key == key {
+			return m.value, true
+		}
+	}
+	return "", false
+}
+
+// parseSignatureInput parses signature input inner list into covered
+// components and parameters.
+func parseSignatureInput(input string) ([]string, map[string]string, error) {
+	if !strings.HasPrefix(input, "(") {
+		return nil, nil, errors.New("expected inner list")
+	}
+
+	end := strings.IndexByte(input, ')')
+	if end < 0 {
+		return nil, nil, errors.New("unterminated inner list")
+	}
+
+	components := []string{}
+	for _, item := range strings.Fields(input[1:end]) {
+		name, err := strconv.Unquote(item)
+		if err != nil || !strings.HasPrefix(item, `"`) {
+			return nil, nil, fmt.Errorf("unsupported component %s", item)
+		}
+		components = append(components, name)
+	}
+
+	params := map[string]string{}
+	for _, param := range strings.Split(input[end+1:], ";") {
+		param = strings.TrimSpace(param)
+		if param == "" {
+			continue
+		}
+		n := strings.IndexByte(param, '=')
+		if n < 0 {
+			params[param] = ""
+			continue
+		}
+		key, value := param[:n], param[n+1:]
+		if strings.HasPrefix(value, `"`) {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid parameter %s", param)
+			}
+			value = unquoted
+		}
+		params[key] = value
+	}
+
+	return components, params, nil
+}
+
+func appendHeader(header http.Header, name, value string) {
+	if existing := header.Get(name); existing != "" {
+		header.Set(name, existing+", "+value)
+	} else {
+		header.Set(name, value)
+	}
+}
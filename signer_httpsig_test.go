@@ -0,0 +1,404 @@
+package httpexpect
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPSignatureVector(t *testing.T) {
+	// RFC 9421, B.2.5
+	key, _ := base64.StdEncoding.DecodeString(
+		"uzvJfB4u3N0Jy4T7NZ75MDVcr8zSTInedJtkgcu46YW4XByzNJjxBdtjUkdJPBtbmHhIDi" +
+			"6pcl8jsasjlTMtDQ==")
+
+	signer := NewHTTPSignatureSigner(HTTPSignatureOpts{
+		Label:      "sig-b25",
+		KeyID:      "test-shared-secret",
+		Key:        key,
+		Components: []string{"date", "@authority", "content-type"},
+	})
+	signer.now = func() time.Time {
+		return time.Unix(1618884473, 0)
+	}
+
+	req, _ := http.NewRequest("POST", "http://example.com/foo?param=Value&Pet=dog", nil)
+	req.Header.Set("Date", "Tue, 20 Apr 2021 02:07:55 GMT")
+	req.Header.Set("Content-Type", "application/json")
+
+	assert.NoError(t, signer.Sign(req, []byte(`{"hello": "world"}`)))
+
+	assert.Equal(t,
+		`sig-b25=("date" "@authority" "content-type")`+
+			`;created=1618884473;keyid="test-shared-secret"`,
+		req.Header.Get("Signature-Input"))
+	assert.Equal(t,
+		`sig-b25=:pxcQw6G3AjtMBQjwo8XzkZf/bws5LelbaMk5rGIGtE8=:`,
+		req.Header.Get("Signature"))
+}
+
+func TestHTTPSignatureComponents(t *testing.T) {
+	req, _ := http.NewRequest("POST", "https://Example.com:443/a%20b?x=1&y", nil)
+	msg := httpsigMessage{req: req, header: req.Header}
+
+	cases := map[string]string{
+		"@method":         "POST",
+		"@target-uri":     "https://example.com/a%20b?x=1&y",
+		"@authority":      "example.com",
+		"@scheme":         "https",
+		"@request-target": "/a%20b?x=1&y",
+		"@path":           "/a%20b",
+		"@query":          "?x=1&y",
+	}
+
+	for name, expected := range cases {
+		value, err := msg.component(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, value, name)
+	}
+
+	req.Header.Add("X-Foo", " a ")
+	req.Header.Add("X-Foo", "b")
+
+	value, err := msg.component("x-foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "a, b", value)
+
+	_, err = msg.component("x-bar")
+	assert.Error(t, err)
+
+	_, err = msg.component("@status")
+	assert.Error(t, err)
+
+	_, err = msg.component("@unknown")
+	assert.Error(t, err)
+}
+
+func TestHTTPSignatureDefaults(t *testing.T) {
+	signer := NewHTTPSignatureSigner(HTTPSignatureOpts{
+		Key:     []byte("secret"),
+		Nonce:   "abc",
+		Tag:     "app",
+		Expires: time.Minute,
+	})
+	signer.now = func() time.Time {
+		return time.Unix(1000, 0)
+	}
+
+	req, _ := http.NewRequest("POST", "http://example.com/path?q=1", nil)
+	req.Header.Set("Signature-Input", `other=("@method");created=1`)
+	req.Header.Set("Signature", `other=:AAAA:`)
+
+	assert.NoError(t, signer.Sign(req, []byte("hello")))
+
+	assert.Equal(t, contentDigest([]byte("hello")), req.Header.Get("Content-Digest"))
+	assert.Equal(t,
+		`other=("@method");created=1, `+
+			`sig1=("@method" "@authority" "@path" "@query" "content-digest")`+
+			`;created=1000;expires=1060;nonce="abc";tag="app"`,
+		req.Header.Get("Signature-Input"))
+	assert.True(t, strings.HasPrefix(req.Header.Get("Signature"), "other=:AAAA:, sig1=:"))
+}
+
+func TestHTTPSignatureAlgorithms(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		algorithm string
+		key       interface{}
+		verifyKey interface{}
+	}{
+		{"hmac-sha256", []byte("secret"), []byte("secret")},
+		{"rsa-pss-sha512", rsaKey, &rsaKey.PublicKey},
+		{"rsa-v1_5-sha256", rsaKey, &rsaKey.PublicKey},
+		{"ecdsa-p256-sha256", p256Key, &p256Key.PublicKey},
+		{"ecdsa-p384-sha384", p384Key, &p384Key.PublicKey},
+		{"", rsaKey, &rsaKey.PublicKey},
+		{"", p256Key, &p256Key.PublicKey},
+	}
+
+	for _, tc := range cases {
+		signer := NewHTTPSignatureSigner(HTTPSignatureOpts{
+			Algorithm: tc.algorithm,
+			Key:       tc.key,
+		})
+
+		algorithm, err := signer.algorithm()
+		assert.NoError(t, err)
+
+		base := []byte(`"@status": 200`)
+
+		signature, err := httpsigSign(algorithm, tc.key, base)
+		assert.NoError(t, err, algorithm)
+
+		assert.NoError(t,
+			httpsigVerify(algorithm, tc.verifyKey, base, signature), algorithm)
+		assert.Error(t,
+			httpsigVerify(algorithm, tc.verifyKey, []byte(`"@status": 201`), signature),
+			algorithm)
+	}
+
+	_, err = httpsigSign("rsa-pss-sha512", []byte("secret"), nil)
+	assert.Error(t, err)
+
+	_, err = httpsigSign("ed25519", []byte("secret"), nil)
+	assert.Error(t, err)
+}
+
+func signHTTPSigResponse(
+	s *HTTPSignatureSigner, resp *http.Response, body string,
+) {
+	components := []string{"@status", "content-type"}
+	if body != "" {
+		resp.Header.Set("Content-Digest", contentDigest([]byte(body)))
+		components = append(components, "content-digest")
+	}
+
+	params := s.serializeParams(components)
+
+	base, _ := httpsigMessage{status: resp.StatusCode, header: resp.Header}.
+		signatureBase(components, params)
+
+	algorithm, _ := s.algorithm()
+	signature, _ := httpsigSign(algorithm, s.opts.Key, base)
+
+	resp.Header.Set("Signature-Input", "sig1="+params)
+	resp.Header.Set("Signature",
+		"sig1=:"+base64.StdEncoding.EncodeToString(signature)+":")
+}
+
+func TestHTTPSignatureVerify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	now := time.Unix(1000, 0)
+
+	serverSigner := NewHTTPSignatureSigner(HTTPSignatureOpts{
+		KeyID:     "server",
+		Algorithm: "ecdsa-p256-sha256",
+		Key:       key,
+		Expires:   time.Minute,
+	})
+	serverSigner.now = func() time.Time { return now }
+
+	newResponse := func(body string) *http.Response {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header{
+				"Content-Type": []string{"text/plain"},
+			},
+			Body: ioutil.NopCloser(strings.NewReader(body)),
+		}
+		signHTTPSigResponse(serverSigner, resp, body)
+		return resp
+	}
+
+	newVerifier := func(opts HTTPSignatureOpts) *HTTPSignatureSigner {
+		if opts.Key == nil {
+			opts.Key = &key.PublicKey
+		}
+		verifier := NewHTTPSignatureSigner(opts)
+		verifier.now = func() time.Time { return now.Add(time.Second) }
+		return verifier
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		resp := NewResponse(newMockReporter(t), newResponse("hello"))
+		resp.VerifySignature(newVerifier(HTTPSignatureOpts{KeyID: "server"}))
+		resp.chain.assertOK(t)
+	})
+
+	t.Run("empty body", func(t *testing.T) {
+		resp := NewResponse(newMockReporter(t), newResponse(""))
+		resp.VerifySignature(newVerifier(HTTPSignatureOpts{}))
+		resp.chain.assertOK(t)
+	})
+
+	t.Run("body", func(t *testing.T) {
+		httpResp := newResponse("hello")
+		httpResp.Body = ioutil.NopCloser(strings.NewReader("hello!"))
+
+		resp := NewResponse(newMockReporter(t), httpResp)
+		resp.VerifySignature(newVerifier(HTTPSignatureOpts{}))
+		resp.chain.assertFailed(t)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		httpResp := newResponse("hello")
+		httpResp.StatusCode = http.StatusCreated
+
+		resp := NewResponse(newMockReporter(t), httpResp)
+		resp.VerifySignature(newVerifier(HTTPSignatureOpts{}))
+		resp.chain.assertFailed(t)
+	})
+
+	t.Run("key id", func(t *testing.T) {
+		resp := NewResponse(newMockReporter(t), newResponse("hello"))
+		resp.VerifySignature(newVerifier(HTTPSignatureOpts{KeyID: "other"}))
+		resp.chain.assertFailed(t)
+	})
+
+	t.Run("algorithm", func(t *testing.T) {
+		resp := NewResponse(newMockReporter(t), newResponse("hello"))
+		resp.VerifySignature(newVerifier(HTTPSignatureOpts{
+			Algorithm: "ecdsa-p384-sha384",
+		}))
+		resp.chain.assertFailed(t)
+	})
+
+	t.Run("components", func(t *testing.T) {
+		resp := NewResponse(newMockReporter(t), newResponse("hello"))
+		resp.VerifySignature(newVerifier(HTTPSignatureOpts{
+			Components: []string{"@status", "date"},
+		}))
+		resp.chain.assertFailed(t)
+	})
+
+	t.Run("label", func(t *testing.T) {
+		resp := NewResponse(newMockReporter(t), newResponse("hello"))
+		resp.VerifySignature(newVerifier(HTTPSignatureOpts{Label: "sig2"}))
+		resp.chain.assertFailed(t)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		verifier := newVerifier(HTTPSignatureOpts{})
+		verifier.now = func() time.Time { return now.Add(time.Hour) }
+
+		resp := NewResponse(newMockReporter(t), newResponse("hello"))
+		resp.VerifySignature(verifier)
+		resp.chain.assertFailed(t)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		httpResp := newResponse("hello")
+		httpResp.Header.Del("Signature-Input")
+
+		resp := NewResponse(newMockReporter(t), httpResp)
+		resp.VerifySignature(newVerifier(HTTPSignatureOpts{}))
+		resp.chain.assertFailed(t)
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		called := false
+
+		resp := NewResponse(newMockReporter(t), newResponse("hello"))
+		resp.VerifySignature(newVerifier(HTTPSignatureOpts{
+			VerifyFunc: func(base []byte, signature []byte) error {
+				called = true
+				return errors.New("bad signature")
+			},
+		}))
+		resp.chain.assertFailed(t)
+
+		assert.True(t, called)
+	})
+}
+
+func TestHTTPSignatureRequest(t *testing.T) {
+	key := []byte("secret")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		if len(body) != 0 &&
+			checkContentDigest(r.Header.Get("Content-Digest"), body) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		inputs, _ := parseSignatureDictionary(r.Header.Get("Signature-Input"))
+		signatures, _ := parseSignatureDictionary(r.Header.Get("Signature"))
+		if len(inputs) != 1 || len(signatures) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		components, _, _ := parseSignatureInput(inputs[0].value)
+
+		base, err := httpsigMessage{req: r, header: r.Header}.
+			signatureBase(components, inputs[0].value)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		signature, _ := httpsigSign("hmac-sha256", key, base)
+		if signatures[0].value !=
+			":"+base64.StdEncoding.EncodeToString(signature)+":" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+
+	e := WithConfig(Config{
+		BaseURL:  "http://example.com",
+		Reporter: NewAssertReporter(t),
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+		Signer: NewHTTPSignatureSigner(HTTPSignatureOpts{
+			KeyID: "client",
+			Key:   key,
+		}),
+	})
+
+	e.POST("/path").
+		WithQuery("q", "1").
+		WithJSON(map[string]string{"foo": "bar"}).
+		Expect().
+		Status(http.StatusOK)
+
+	e.GET("/path").
+		Expect().
+		Status(http.StatusOK)
+
+	e.GET("/path").
+		WithSigner(NewHTTPSignatureSigner(HTTPSignatureOpts{
+			Key: []byte("other"),
+		})).
+		Expect().
+		Status(http.StatusUnauthorized)
+}
+
+func TestHTTPSignatureParse(t *testing.T) {
+	members, err := parseSignatureDictionary(
+		`a=("x" "y,z");created=1, b=:YWJj:, c="q,\"r"`)
+	assert.NoError(t, err)
+	assert.Equal(t, []signatureMember{
+		{key: "a", value: `("x" "y,z");created=1`},
+		{key: "b", value: `:YWJj:`},
+		{key: "c", value: `"q,\"r"`},
+	}, members)
+
+	_, err = parseSignatureDictionary(`foo`)
+	assert.Error(t, err)
+
+	components, params, err := parseSignatureInput(
+		`("@method" "content-digest");created=1;keyid="k";alg="hmac-sha256"`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"@method", "content-digest"}, components)
+	assert.Equal(t, map[string]string{
+		"created": "1",
+		"keyid":   "k",
+		"alg":     "hmac-sha256",
+	}, params)
+
+	_, _, err = parseSignatureInput(`"@method"`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,328 @@
+package httpexpect
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingSigner struct {
+	bodies []string
+}
+
+func (s *recordingSigner) Sign(req *http.Request, body []byte) error {
+	s.bodies = append(s.bodies, string(body))
+	req.Header.Set("X-Signature", req.Method+" "+req.URL.RequestURI())
+	return nil
+}
+
+type signaturePrinter struct {
+	signatures []string
+}
+
+func (p *signaturePrinter) Request(req *http.Request) {
+	p.signatures = append(p.signatures, req.Header.Get("X-Signature"))
+}
+
+func (p *signaturePrinter) Response(*http.Response, time.Duration) {
+}
+
+func TestSignerStage(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		_, _ = w.Write([]byte(r.Header.Get("X-Signature") + " " + string(body)))
+	})
+
+	signer := &recordingSigner{}
+	printer := &signaturePrinter{}
+
+	e := WithConfig(Config{
+		BaseURL:  "http://example.com",
+		Reporter: NewAssertReporter(t),
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+		Printers: []Printer{printer},
+		Signer:   signer,
+	})
+
+	e.POST("/{id}").
+		WithPath("id", 1).
+		WithQuery("foo", "bar").
+		WithJSON(map[string]int{"a": 1}).
+		Expect().
+		Status(http.StatusOK).
+		Body().Equal(`POST /1?foo=bar {"a":1}`)
+
+	e.PUT("/path").
+		WithChunked(strings.NewReader("chunked")).
+		Expect().
+		Status(http.StatusOK).
+		Body().Equal(`PUT /path chunked`)
+
+	e.GET("/path").
+		Expect().
+		Status(http.StatusOK).
+		Body().Equal(`GET /path `)
+
+	e.GET("/path").
+		WithSigner(nil).
+		Expect().
+		Status(http.StatusOK).
+		Body().Equal(` `)
+
+	assert.Equal(t, []string{`{"a":1}`, `chunked`, ``}, signer.bodies)
+	assert.Equal(t,
+		[]string{"POST /1?foo=bar", "PUT /path", "GET /path", ""},
+		printer.signatures)
+}
+
+type failingSigner struct{}
+
+func (failingSigner) Sign(*http.Request, []byte) error {
+	return errors.New("no key")
+}
+
+func TestSignerFailed(t *testing.T) {
+	called := false
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	reporter := newMockReporter(t)
+
+	e := WithConfig(Config{
+		BaseURL:  "http://example.com",
+		Reporter: reporter,
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+		Signer: failingSigner{},
+	})
+
+	req := e.GET("/")
+	req.Expect()
+	req.chain.assertFailed(t)
+
+	assert.False(t, called)
+}
+
+func TestSignerHMAC(t *testing.T) {
+	now := time.Date(2021, 4, 20, 2, 7, 55, 0, time.UTC)
+
+	signer := NewHMACSigner(HMACOpts{
+		KeyID:         "my-key",
+		Key:           []byte("secret"),
+		SignedHeaders: []string{"Date", "Content-Type"},
+	})
+	signer.now = func() time.Time { return now }
+
+	req, _ := http.NewRequest("POST", "http://example.com/foo?bar=baz", nil)
+	req.Header.Set("Content-Type", "application/json")
+
+	err := signer.Sign(req, []byte(`{"hello": "world"}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Tue, 20 Apr 2021 02:07:55 GMT", req.Header.Get("Date"))
+
+	canonical := "POST\n" +
+		"/foo?bar=baz\n" +
+		"date:Tue, 20 Apr 2021 02:07:55 GMT\n" +
+		"content-type:application/json\n" +
+		"\n" +
+		hexSHA256([]byte(`{"hello": "world"}`))
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	_, _ = mac.Write([]byte(canonical))
+
+	scheme, rest := splitAuthHeader(req.Header.Get("Authorization"))
+	assert.Equal(t, "HMAC-SHA256", scheme)
+
+	params := parseAuthParams(rest)
+	assert.Equal(t, "my-key", params["keyid"])
+	assert.Equal(t, "date content-type", params["headers"])
+	assert.Equal(t,
+		base64.StdEncoding.EncodeToString(mac.Sum(nil)), params["signature"])
+}
+
+func TestSignerHMACHash(t *testing.T) {
+	signer := NewHMACSigner(HMACOpts{
+		Key:  []byte("secret"),
+		Hash: crypto.SHA512,
+	})
+
+	req, _ := http.NewRequest("GET", "http://example.com/", nil)
+	assert.NoError(t, signer.Sign(req, nil))
+	assert.True(t,
+		strings.HasPrefix(req.Header.Get("Authorization"), "HMAC-SHA512 "))
+
+	signer = NewHMACSigner(HMACOpts{
+		Key:  []byte("secret"),
+		Hash: crypto.MD5,
+	})
+
+	req, _ = http.NewRequest("GET", "http://example.com/", nil)
+	assert.Error(t, signer.Sign(req, nil))
+}
+
+func signHMACResponse(s *HMACSigner, resp *http.Response, body string) {
+	canonical := s.canonical(
+		[]string{"200"}, resp.Header, []string{"content-type"}, []byte(body))
+
+	resp.Header.Set("Authorization", `HMAC-SHA256 keyId="my-key", `+
+		`headers="content-type", signature="`+
+		base64.StdEncoding.EncodeToString(s.sign(canonical))+`"`)
+}
+
+func TestSignerHMACVerify(t *testing.T) {
+	signer := NewHMACSigner(HMACOpts{
+		KeyID:         "my-key",
+		Key:           []byte("secret"),
+		SignedHeaders: []string{"Content-Type"},
+	})
+
+	newResponse := func() *http.Response {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header{
+				"Content-Type": []string{"text/plain"},
+			},
+			Body: ioutil.NopCloser(strings.NewReader("hello")),
+		}
+		signHMACResponse(signer, resp, "hello")
+		return resp
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		resp := NewResponse(newMockReporter(t), newResponse())
+		resp.VerifySignature(signer)
+		resp.chain.assertOK(t)
+	})
+
+	t.Run("body", func(t *testing.T) {
+		httpResp := newResponse()
+		httpResp.Body = ioutil.NopCloser(strings.NewReader("hello!"))
+
+		resp := NewResponse(newMockReporter(t), httpResp)
+		resp.VerifySignature(signer)
+		resp.chain.assertFailed(t)
+	})
+
+	t.Run("header", func(t *testing.T) {
+		httpResp := newResponse()
+		httpResp.Header.Set("Content-Type", "text/html")
+
+		resp := NewResponse(newMockReporter(t), httpResp)
+		resp.VerifySignature(signer)
+		resp.chain.assertFailed(t)
+	})
+
+	t.Run("key", func(t *testing.T) {
+		resp := NewResponse(newMockReporter(t), newResponse())
+		resp.VerifySignature(NewHMACSigner(HMACOpts{
+			KeyID: "my-key",
+			Key:   []byte("other"),
+		}))
+		resp.chain.assertFailed(t)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		httpResp := newResponse()
+		httpResp.Header.Del("Authorization")
+
+		resp := NewResponse(newMockReporter(t), httpResp)
+		resp.VerifySignature(signer)
+		resp.chain.assertFailed(t)
+	})
+
+	t.Run("compressed", func(t *testing.T) {
+		compressed := mustGzip([]byte("hello"))
+
+		httpResp := newResponse()
+		httpResp.Header.Set("Content-Encoding", "gzip")
+		httpResp.Body = ioutil.NopCloser(bytes.NewReader(compressed))
+		signHMACResponse(signer, httpResp, string(compressed))
+
+		resp := NewResponse(newMockReporter(t), httpResp)
+		resp.VerifySignature(signer)
+		resp.Body().Equal("hello")
+		resp.chain.assertOK(t)
+	})
+}
+
+func TestSignerHMACDate(t *testing.T) {
+	now := time.Date(2021, 4, 20, 2, 7, 55, 0, time.UTC)
+
+	signer := NewHMACSigner(HMACOpts{
+		Key:           []byte("secret"),
+		SignedHeaders: []string{"Date"},
+	})
+	signer.now = func() time.Time { return now }
+
+	req, _ := http.NewRequest("GET", "http://example.com/", nil)
+
+	assert.NoError(t, signer.Sign(req, nil))
+	assert.Equal(t, "Tue, 20 Apr 2021 02:07:55 GMT", req.Header.Get("Date"))
+
+	signature := req.Header.Get("Authorization")
+
+	now = now.Add(time.Minute)
+
+	assert.NoError(t, signer.Sign(req, nil))
+	assert.Equal(t, "Tue, 20 Apr 2021 02:08:55 GMT", req.Header.Get("Date"))
+	assert.NotEqual(t, signature, req.Header.Get("Authorization"))
+}
+
+func TestSignerAuthenticator(t *testing.T) {
+	var authorization, signature string
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		signature = r.Header.Get("Signature")
+	})
+
+	config := newBinderConfig(t, handler)
+	config.Authenticator = NewBearerAuthenticator("token")
+	config.Signer = NewHMACSigner(HMACOpts{
+		Key: []byte("secret"),
+	})
+
+	req := NewRequest(config, "GET", "/")
+	req.Expect()
+	req.chain.assertFailed(t)
+	assert.Equal(t, "", authorization)
+
+	config.Signer = NewHMACSigner(HMACOpts{
+		Key:    []byte("secret"),
+		Header: "Signature",
+	})
+
+	req = NewRequest(config, "GET", "/")
+	req.Expect()
+	req.chain.assertOK(t)
+
+	assert.Equal(t, "Bearer token", authorization)
+	assert.True(t, strings.HasPrefix(signature, "HMAC-SHA256 "))
+
+	config.Authenticator = nil
+	config.Signer = NewHMACSigner(HMACOpts{
+		Key: []byte("secret"),
+	})
+
+	req = NewRequest(config, "GET", "/").WithHeader("Authorization", "ignored")
+	req.Expect()
+	req.chain.assertOK(t)
+
+	assert.True(t, strings.HasPrefix(authorization, "HMAC-SHA256 "))
+}
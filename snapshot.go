@@ -0,0 +1,303 @@
+package httpexpect
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// SnapshotUpdateEnv is the name of the environment variable that enables
+// updating of snapshot files. If it's set to a non-empty value other than
+// "0" or "false", Snapshot methods rewrite snapshot files with actual values
+// instead of comparing them.
+const SnapshotUpdateEnv = "HTTPEXPECT_UPDATE"
+
+// SnapshotRedacted is the placeholder that replaces redacted values in
+// JSON snapshots.
+const SnapshotRedacted = "[REDACTED]"
+
+// SnapshotOpts define parameters for snapshot assertions.
+type SnapshotOpts struct {
+	// Directory where snapshot files are stored.
+	// If empty, "testdata" is used.
+	Dir string
+
+	// JSONPath expressions of values that are removed before comparison,
+	// e.g. "$.createdAt", "$.items[*].id", or "$..etag". Expressions have
+	// the same syntax as in Value.Path.
+	Ignore []string
+
+	// JSONPath expressions of values that are replaced with SnapshotRedacted
+	// before comparison. Unlike ignored values, redacted values should be
+	// present.
+	Redact []string
+}
+
+// Snapshot succeeds if value matches snapshot stored in a golden file.
+//
+// The snapshot is stored in JSON format in "<dir>/<name>.json" file, where dir
+// is "testdata" by default. Before comparison, ignored paths are removed from
+// value, and redacted paths are replaced with SnapshotRedacted.
+//
+// If HTTPEXPECT_UPDATE environment variable is set (see SnapshotUpdateEnv),
+// the file is rewritten with the actual value instead.
+//
+// Example:
+//  value := NewValue(t, map[string]interface{}{"id": 123, "createdAt": "..."})
+//  value.Snapshot("user", SnapshotOpts{Redact: []string{"$.createdAt"}})
+func (v *Value) Snapshot(name string, opts ...SnapshotOpts) *Value {
+	checkSnapshot(&v.chain, name, v.value, opts)
+	return v
+}
+
+// Snapshot succeeds if object matches snapshot stored in a golden file.
+// See Value.Snapshot for details.
+//
+// Example:
+//  object := NewObject(t, map[string]interface{}{"foo": 123})
+//  object.Snapshot("foo")
+func (o *Object) Snapshot(name string, opts ...SnapshotOpts) *Object {
+	checkSnapshot(&o.chain, name, o.value, opts)
+	return o
+}
+
+// Snapshot succeeds if array matches snapshot stored in a golden file.
+// See Value.Snapshot for details.
+//
+// Example:
+//  array := NewArray(t, []interface{}{"foo", 123})
+//  array.Snapshot("foo")
+func (a *Array) Snapshot(name string, opts ...SnapshotOpts) *Array {
+	checkSnapshot(&a.chain, name, a.value, opts)
+	return a
+}
+
+// Snapshot succeeds if string matches snapshot stored in a golden file.
+//
+// The snapshot is stored as is in "<dir>/<name>.txt" file, where dir is
+// "testdata" by default. Ignore and Redact options are not applicable to
+// strings and are not allowed.
+//
+// If HTTPEXPECT_UPDATE environment variable is set (see SnapshotUpdateEnv),
+// the file is rewritten with the actual value instead.
+//
+// Example:
+//  str := NewString(t, "Hello")
+//  str.Snapshot("hello")
+func (s *String) Snapshot(name string, opts ...SnapshotOpts) *String {
+	if s.chain.failed() {
+		return s
+	}
+
+	opt := mergeSnapshotOpts(opts)
+
+	if len(opt.Ignore) != 0 || len(opt.Redact) != 0 {
+		s.chain.fail("\nunexpected Ignore or Redact option in String.Snapshot")
+		return s
+	}
+
+	filename := snapshotFile(opt, name, ".txt")
+
+	if snapshotUpdate() {
+		writeSnapshot(&s.chain, filename, []byte(s.value))
+		return s
+	}
+
+	expected, ok := readSnapshot(&s.chain, filename)
+	if !ok {
+		return s
+	}
+
+	if string(expected) != s.value {
+		s.chain.failExpected(string(expected), s.value,
+			"\nexpected string matching snapshot %q:\n %q\n\nbut got:\n %q",
+			filename, string(expected), s.value)
+	}
+
+	return s
+}
+
+func checkSnapshot(chain *chain, name string, value interface{}, opts []SnapshotOpts) {
+	if chain.failed() {
+		return
+	}
+
+	opt := mergeSnapshotOpts(opts)
+
+	actual, err := normalizeSnapshot(value, opt)
+	if err != nil {
+		chain.fail("\n%s", err.Error())
+		return
+	}
+
+	filename := snapshotFile(opt, name, ".json")
+
+	if snapshotUpdate() {
+		data, err := json.MarshalIndent(actual, "", "  ")
+		if err != nil {
+			chain.fail(err.Error())
+			return
+		}
+		writeSnapshot(chain, filename, append(data, '\n'))
+		return
+	}
+
+	data, ok := readSnapshot(chain, filename)
+	if !ok {
+		return
+	}
+
+	var expected interface{}
+	if err := json.Unmarshal(data, &expected); err != nil {
+		chain.fail("\nfailed to parse snapshot %q:\n %s", filename, err.Error())
+		return
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		chain.failExpected(expected, actual,
+			"\nexpected value matching snapshot %q:\n%s\n\nbut got:\n%s\n\ndiff:\n%s",
+			filename,
+			dumpValue(expected),
+			dumpValue(actual),
+			diffValues(expected, actual))
+	}
+}
+
+func mergeSnapshotOpts(opts []SnapshotOpts) SnapshotOpts {
+	var ret SnapshotOpts
+	for _, opt := range opts {
+		if opt.Dir != "" {
+			ret.Dir = opt.Dir
+		}
+		ret.Ignore = append(ret.Ignore, opt.Ignore...)
+		ret.Redact = append(ret.Redact, opt.Redact...)
+	}
+	if ret.Dir == "" {
+		ret.Dir = "testdata"
+	}
+	return ret
+}
+
+func snapshotFile(opt SnapshotOpts, name, ext string) string {
+	return filepath.Join(opt.Dir, filepath.FromSlash(name)+ext)
+}
+
+func snapshotUpdate() bool {
+	switch os.Getenv(SnapshotUpdateEnv) {
+	case "", "0", "false":
+		return false
+	default:
+		return true
+	}
+}
+
+func readSnapshot(chain *chain, filename string) ([]byte, bool) {
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		chain.fail("\nsnapshot %q doesn't exist, run tests with %s=1 to create it",
+			filename, SnapshotUpdateEnv)
+		return nil, false
+	}
+	if err != nil {
+		chain.fail("\nfailed to read snapshot %q:\n %s", filename, err.Error())
+		return nil, false
+	}
+	return data, true
+}
+
+func writeSnapshot(chain *chain, filename string, data []byte) {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		chain.fail("\nfailed to write snapshot %q:\n %s", filename, err.Error())
+		return
+	}
+	if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+		chain.fail("\nfailed to write snapshot %q:\n %s", filename, err.Error())
+	}
+}
+
+// normalizeSnapshot returns a deep copy of value in the form produced by JSON
+// decoder, with ignored paths removed and redacted paths replaced.
+func normalizeSnapshot(value interface{}, opt SnapshotOpts) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret interface{}
+	if err := json.Unmarshal(data, &ret); err != nil {
+		return nil, err
+	}
+
+	for _, path := range opt.Ignore {
+		if err := updateSnapshotPath(ret, path, snapshotIgnored); err != nil {
+			return nil, err
+		}
+	}
+
+	ret = pruneSnapshot(ret)
+
+	for _, path := range opt.Redact {
+		if err := updateSnapshotPath(ret, path, SnapshotRedacted); err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+// snapshotIgnored marks ignored values until they're removed by
+// pruneSnapshot.
+var snapshotIgnored = &struct{}{}
+
+// updateSnapshotPath replaces every value matching JSONPath query with
+// given value.
+func updateSnapshotPath(root interface{}, path string, value interface{}) error {
+	query, err := parseJSONPath(path)
+	if err != nil {
+		return err
+	}
+
+	if len(query.segments) == 0 {
+		return fmt.Errorf("invalid snapshot path %q: root can't be ignored or redacted",
+			path)
+	}
+
+	for _, loc := range query.locate(root, root) {
+		switch parent := loc.parent.(type) {
+		case map[string]interface{}:
+			parent[loc.key.(string)] = value
+		case []interface{}:
+			parent[loc.key.(int)] = value
+		}
+	}
+
+	return nil
+}
+
+// pruneSnapshot removes values marked with snapshotIgnored.
+func pruneSnapshot(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, elem := range v {
+			if elem == snapshotIgnored {
+				delete(v, key)
+			} else {
+				v[key] = pruneSnapshot(elem)
+			}
+		}
+
+	case []interface{}:
+		ret := v[:0]
+		for _, elem := range v {
+			if elem != snapshotIgnored {
+				ret = append(ret, pruneSnapshot(elem))
+			}
+		}
+		return ret
+	}
+
+	return value
+}
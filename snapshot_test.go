@@ -0,0 +1,230 @@
+package httpexpect
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withSnapshotUpdate(t *testing.T, fn func()) {
+	require.Nil(t, os.Setenv(SnapshotUpdateEnv, "1"))
+	defer os.Unsetenv(SnapshotUpdateEnv)
+
+	fn()
+}
+
+func TestSnapshotValue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpexpect")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	reporter := newMockReporter(t)
+
+	opts := SnapshotOpts{Dir: dir}
+
+	data := map[string]interface{}{
+		"foo": 123,
+		"bar": []interface{}{"a", "b"},
+	}
+
+	value1 := NewValue(reporter, data)
+	value1.Snapshot("value")
+	value1.chain.assertFailed(t)
+
+	withSnapshotUpdate(t, func() {
+		value2 := NewValue(reporter, data)
+		value2.Snapshot("value", opts)
+		value2.chain.assertOK(t)
+	})
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "value.json"))
+	require.Nil(t, err)
+	assert.Equal(t,
+		"{\n  \"bar\": [\n    \"a\",\n    \"b\"\n  ],\n  \"foo\": 123\n}\n",
+		string(content))
+
+	value3 := NewValue(reporter, data)
+	value3.Snapshot("value", opts)
+	value3.chain.assertOK(t)
+
+	value4 := NewValue(reporter, map[string]interface{}{"foo": 123})
+	value4.Snapshot("value", opts)
+	value4.chain.assertFailed(t)
+
+	object := NewObject(reporter, data)
+	object.Snapshot("value", opts)
+	object.chain.assertOK(t)
+
+	array := NewArray(reporter, []interface{}{"a", "b"})
+	array.Snapshot("value", opts)
+	array.chain.assertFailed(t)
+
+	withSnapshotUpdate(t, func() {
+		NewArray(reporter, []interface{}{"a", "b"}).
+			Snapshot("nested/array", opts).
+			chain.assertOK(t)
+	})
+
+	NewArray(reporter, []interface{}{"a", "b"}).
+		Snapshot("nested/array", opts).
+		chain.assertOK(t)
+
+	NewArray(reporter, []interface{}{"b", "a"}).
+		Snapshot("nested/array", opts).
+		chain.assertFailed(t)
+}
+
+func TestSnapshotString(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpexpect")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	reporter := newMockReporter(t)
+
+	opts := SnapshotOpts{Dir: dir}
+
+	withSnapshotUpdate(t, func() {
+		str := NewString(reporter, "hello\nworld")
+		str.Snapshot("text", opts)
+		str.chain.assertOK(t)
+	})
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "text.txt"))
+	require.Nil(t, err)
+	assert.Equal(t, "hello\nworld", string(content))
+
+	str1 := NewString(reporter, "hello\nworld")
+	str1.Snapshot("text", opts)
+	str1.chain.assertOK(t)
+
+	str2 := NewString(reporter, "hello")
+	str2.Snapshot("text", opts)
+	str2.chain.assertFailed(t)
+
+	str3 := NewString(reporter, "hello\nworld")
+	str3.Snapshot("text", SnapshotOpts{Dir: dir, Ignore: []string{"$.foo"}})
+	str3.chain.assertFailed(t)
+}
+
+func TestSnapshotNormalize(t *testing.T) {
+	value := map[string]interface{}{
+		"id":        123,
+		"createdAt": "2020-01-01",
+		"items": []interface{}{
+			map[string]interface{}{"id": 1, "etag": "a"},
+			map[string]interface{}{"id": 2, "etag": "b"},
+		},
+		"meta": map[string]interface{}{
+			"etag":  "c",
+			"owner": map[string]interface{}{"token": "secret"},
+		},
+	}
+
+	actual, err := normalizeSnapshot(value, SnapshotOpts{
+		Ignore: []string{"$.createdAt", "$..etag"},
+		Redact: []string{"$.items[*].id", "$.meta['owner'].token"},
+	})
+	require.Nil(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"id": 123.0,
+		"items": []interface{}{
+			map[string]interface{}{"id": SnapshotRedacted},
+			map[string]interface{}{"id": SnapshotRedacted},
+		},
+		"meta": map[string]interface{}{
+			"owner": map[string]interface{}{"token": SnapshotRedacted},
+		},
+	}, actual)
+
+	assert.Equal(t, 123, value["id"])
+
+	actual, err = normalizeSnapshot(value, SnapshotOpts{
+		Ignore: []string{"$.items[0]", "$.meta.*", "$.missing.path"},
+	})
+	require.Nil(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"id":        123.0,
+		"createdAt": "2020-01-01",
+		"items": []interface{}{
+			map[string]interface{}{"id": 2.0, "etag": "b"},
+		},
+		"meta": map[string]interface{}{},
+	}, actual)
+
+	value = map[string]interface{}{
+		"it's": "a",
+		"node": map[string]interface{}{
+			"id": 1,
+			"children": []interface{}{
+				map[string]interface{}{"id": 2, "children": []interface{}{}},
+				map[string]interface{}{"id": 3, "secret": true},
+			},
+		},
+	}
+
+	actual, err = normalizeSnapshot(value, SnapshotOpts{
+		Ignore: []string{`$['it\'s']`, "$..children[?@.secret]"},
+		Redact: []string{"$..id"},
+	})
+	require.Nil(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"node": map[string]interface{}{
+			"id": SnapshotRedacted,
+			"children": []interface{}{
+				map[string]interface{}{
+					"id":       SnapshotRedacted,
+					"children": []interface{}{},
+				},
+			},
+		},
+	}, actual)
+
+	for _, path := range []string{"", "foo", "$", "$.", "$[", "$['foo"} {
+		_, err := normalizeSnapshot(value, SnapshotOpts{Ignore: []string{path}})
+		assert.NotNil(t, err, path)
+	}
+}
+
+func TestSnapshotRedact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpexpect")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	reporter := newMockReporter(t)
+
+	opts := SnapshotOpts{
+		Dir:    dir,
+		Ignore: []string{"$.createdAt"},
+		Redact: []string{"$.token"},
+	}
+
+	withSnapshotUpdate(t, func() {
+		NewObject(reporter, map[string]interface{}{
+			"id":        1,
+			"token":     "abc",
+			"createdAt": "2020-01-01",
+		}).Snapshot("object", opts).chain.assertOK(t)
+	})
+
+	NewObject(reporter, map[string]interface{}{
+		"id":        1,
+		"token":     "def",
+		"createdAt": "2021-01-01",
+	}).Snapshot("object", opts).chain.assertOK(t)
+
+	NewObject(reporter, map[string]interface{}{
+		"id":    1,
+		"token": "def",
+	}).Snapshot("object", opts).chain.assertOK(t)
+
+	NewObject(reporter, map[string]interface{}{
+		"id": 1,
+	}).Snapshot("object", opts).chain.assertFailed(t)
+}
@@ -0,0 +1,556 @@
+package httpexpect
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EventStream provides methods to read and inspect Server-Sent Events
+// from "text/event-stream" response.
+//
+// Events are parsed according to the HTML Living Standard. Comments are
+// skipped, and event type defaults to "message".
+type EventStream struct {
+	config      Config
+	chain       chain
+	resp        *http.Response
+	cancel      context.CancelFunc
+	readTimeout time.Duration
+	lastEventID string
+	retry       *time.Duration
+	results     chan sseResult
+	done        chan struct{}
+	err         error
+	isClosed    bool
+}
+
+type sseMessage struct {
+	dispatch bool
+	id       string
+	event    string
+	data     string
+	retry    *time.Duration
+}
+
+type sseResult struct {
+	message sseMessage
+	err     error
+}
+
+// SSE returns a new EventStream object that may be used to read and
+// inspect Server-Sent Events from response.
+//
+// SSE succeeds if response contains "text/event-stream" Content-Type header
+// with empty or "utf-8" charset.
+//
+// Body of "text/event-stream" response is not read in advance. Events
+// are read from the connection one by one when Expect is called, and
+// the stream should be closed when it's not needed anymore. After SSE is
+// called, response content can't be inspected by Body and other methods.
+//
+// If instead Body or another method inspecting content is called first,
+// it reads the whole stream until it's closed by server, and then SSE
+// reads events from the content that was read. If neither is called,
+// the stream is closed when Response is garbage collected.
+//
+// When Binder is used, request should have "Accept: text/event-stream"
+// header, so that handler response is streamed instead of being read
+// after handler returns. See Binder for details.
+//
+// Example:
+//  stream := e.GET("/events").
+//      WithHeader("Accept", "text/event-stream").
+//      Expect().
+//      Status(http.StatusOK).
+//      SSE()
+//  defer stream.Close()
+//
+//  event := stream.WithReadTimeout(time.Second).Expect()
+//  event.Event().Equal("update")
+//  event.Data().JSON().Object().ValueEqual("id", 123)
+func (r *Response) SSE() *EventStream {
+	if !r.chain.failed() {
+		r.checkContentType("text/event-stream")
+	}
+
+	if !r.chain.failed() && r.takenBySSE {
+		r.chain.fail("\nresponse body was already taken by previous SSE() call")
+	}
+
+	s := &EventStream{
+		config: r.config,
+		chain:  r.chain.enter("SSE()"),
+	}
+
+	if !s.chain.failed() {
+		if r.unread {
+			r.unread = false
+			r.takenBySSE = true
+			runtime.SetFinalizer(r, nil)
+
+			s.resp = r.resp
+			s.cancel = r.cancel
+			r.cancel = nil
+		} else {
+			// body was already read when content was inspected
+			resp := *r.resp
+			resp.Body = ioutil.NopCloser(bytes.NewReader(r.content))
+			s.resp = &resp
+		}
+	}
+
+	return s
+}
+
+func isEventStream(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && mediaType == "text/event-stream"
+}
+
+// WithReadTimeout sets timeout duration for reading events.
+//
+// By default no timeout is used.
+func (s *EventStream) WithReadTimeout(timeout time.Duration) *EventStream {
+	s.readTimeout = timeout
+	return s
+}
+
+// WithoutReadTimeout removes timeout for reading events.
+func (s *EventStream) WithoutReadTimeout() *EventStream {
+	s.readTimeout = noDuration
+	return s
+}
+
+// Expect reads next event from the stream and returns a new SSEEvent
+// object to inspect it.
+//
+// If the stream is closed by server, or no event is received during
+// read timeout, failure is reported.
+//
+// Example:
+//  event := stream.Expect()
+//  event.ID().Equal("1")
+//  event.Data().Equal("hello")
+func (s *EventStream) Expect() *SSEEvent {
+	if !s.checkReadable() {
+		return &SSEEvent{chain: s.chain.enter("Expect()")}
+	}
+
+	for {
+		result, ok := s.next()
+		if !ok {
+			return &SSEEvent{chain: s.chain.enter("Expect()")}
+		}
+
+		if result.err == io.EOF {
+			s.chain.fail("\nexpected event, but event stream was closed by server")
+			return &SSEEvent{chain: s.chain.enter("Expect()")}
+		}
+
+		if result.err != nil {
+			s.chain.fail(
+				"\nexpected read event stream, but got failure: %s", result.err.Error())
+			return &SSEEvent{chain: s.chain.enter("Expect()")}
+		}
+
+		if result.message.dispatch {
+			return &SSEEvent{
+				chain: s.chain.enter("Expect()"),
+				id:    result.message.id,
+				event: result.message.event,
+				data:  result.message.data,
+				retry: result.message.retry,
+			}
+		}
+	}
+}
+
+// ExpectEnd succeeds if the stream is closed by server before any other
+// event is received.
+//
+// Example:
+//  stream.Expect().Data().Equal("last")
+//  stream.ExpectEnd()
+func (s *EventStream) ExpectEnd() *EventStream {
+	if !s.checkReadable() {
+		return s
+	}
+
+	for {
+		result, ok := s.next()
+		if !ok {
+			return s
+		}
+
+		if result.err == io.EOF {
+			return s
+		}
+
+		if result.err != nil {
+			s.chain.fail(
+				"\nexpected read event stream, but got failure: %s", result.err.Error())
+			return s
+		}
+
+		if result.message.dispatch {
+			s.chain.fail("\nexpected end of event stream, but got event:\n%s",
+				dumpValue(map[string]interface{}{
+					"id":    result.message.id,
+					"event": result.message.event,
+					"data":  result.message.data,
+				}))
+			return s
+		}
+	}
+}
+
+// LastEventID returns a new String object that may be used to inspect
+// the last event ID received from the stream.
+//
+// Example:
+//  stream.Expect()
+//  stream.LastEventID().Equal("42")
+func (s *EventStream) LastEventID() *String {
+	return &String{s.chain.enter("LastEventID()"), s.lastEventID}
+}
+
+// Retry returns a new Duration object that may be used to inspect the
+// reconnection time last received from the stream. If server didn't send
+// reconnection time, the returned object is not set.
+//
+// Example:
+//  stream.Expect()
+//  stream.Retry().Equal(3 * time.Second)
+func (s *EventStream) Retry() *Duration {
+	return &Duration{s.chain.enter("Retry()"), s.retry}
+}
+
+// Reconnect closes current connection and sends the original request again,
+// with "Last-Event-ID" header set to the last received event ID, if any.
+// Events are then read from the new connection.
+//
+// The request is sent immediately, regardless of reconnection time
+// received from server.
+//
+// If server doesn't respond with 200 status and "text/event-stream"
+// Content-Type, failure is reported.
+//
+// Example:
+//  stream.Expect().ID().Equal("1")
+//  stream.Reconnect()
+//  stream.Expect().ID().Equal("2")
+func (s *EventStream) Reconnect() *EventStream {
+	if s.chain.failed() {
+		return s
+	}
+
+	s.disconnect()
+
+	if s.resp.Request == nil || s.config.Client == nil {
+		s.chain.fail("\nunable to reconnect event stream without request and client")
+		return s
+	}
+
+	req := new(http.Request)
+	*req = *s.resp.Request
+
+	req.Header = make(http.Header, len(s.resp.Request.Header))
+	for k, v := range s.resp.Request.Header {
+		req.Header[k] = append([]string(nil), v...)
+	}
+
+	if s.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", s.lastEventID)
+	}
+
+	// Binder sets RequestURI, which is not allowed in client requests
+	req.RequestURI = ""
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			s.chain.fail(err.Error())
+			return s
+		}
+		req.Body = body
+	}
+
+	parent := s.config.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	ctx, cancel := parent, context.CancelFunc(func() {})
+	if s.config.RequestTimeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, s.config.RequestTimeout)
+	}
+	req = req.WithContext(ctx)
+
+	for _, printer := range s.config.Printers {
+		printer.Request(req)
+	}
+
+	start := time.Now()
+
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		cancel()
+		s.chain.fail("\nexpected reconnect event stream, but got failure: %s",
+			err.Error())
+		return s
+	}
+
+	for _, printer := range s.config.Printers {
+		printer.Response(resp, time.Since(start))
+	}
+
+	s.chain.request = req
+	s.chain.response = resp
+
+	if resp.StatusCode != http.StatusOK || !isEventStream(resp.Header) {
+		_ = resp.Body.Close()
+		cancel()
+		s.chain.fail("\nexpected reconnect event stream with status %d and"+
+			" \"text/event-stream\" Content-Type,\nbut got status %d and %q",
+			http.StatusOK, resp.StatusCode, resp.Header.Get("Content-Type"))
+		return s
+	}
+
+	s.resp = resp
+	s.cancel = cancel
+	s.err = nil
+
+	return s
+}
+
+// Close closes the underlying connection. It's okay to call this function
+// multiple times.
+//
+// It's recommended to always call this function after stream usage is over
+// to ensure that no resource leaks will happen.
+//
+// Example:
+//  stream := resp.SSE()
+//  defer stream.Close()
+func (s *EventStream) Close() *EventStream {
+	s.disconnect()
+	s.isClosed = true
+	return s
+}
+
+func (s *EventStream) disconnect() {
+	if s.done != nil {
+		close(s.done)
+		s.done = nil
+		s.results = nil
+	}
+	if s.resp != nil && s.resp.Body != nil {
+		_ = s.resp.Body.Close()
+	}
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+}
+
+func (s *EventStream) checkReadable() bool {
+	switch {
+	case s.chain.failed():
+		return false
+	case s.isClosed:
+		s.chain.fail("\nunexpected read from closed event stream")
+		return false
+	case s.resp == nil || s.resp.Body == nil:
+		s.chain.fail("\nunexpected read from failed event stream")
+		return false
+	}
+	return true
+}
+
+// next returns next message or error from the stream, or reports failure
+// if read timeout expires.
+func (s *EventStream) next() (sseResult, bool) {
+	if s.err != nil {
+		return sseResult{err: s.err}, true
+	}
+
+	if s.results == nil {
+		s.results = make(chan sseResult)
+		s.done = make(chan struct{})
+		go readSSE(s.resp.Body, s.results, s.done)
+	}
+
+	var timeout <-chan time.Time
+	if s.readTimeout != noDuration {
+		timer := time.NewTimer(s.readTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case result := <-s.results:
+		if result.err != nil {
+			s.err = result.err
+			return result, true
+		}
+		s.lastEventID = result.message.id
+		if result.message.retry != nil {
+			s.retry = result.message.retry
+		}
+		return result, true
+
+	case <-timeout:
+		s.chain.fail("\nexpected read event stream, but got timeout after %s",
+			s.readTimeout)
+		return sseResult{}, false
+	}
+}
+
+// readSSE parses event stream and sends every message to results channel,
+// until error occurs or done channel is closed.
+func readSSE(body io.Reader, results chan<- sseResult, done <-chan struct{}) {
+	reader := bufio.NewReader(body)
+
+	send := func(result sseResult) bool {
+		select {
+		case results <- result:
+			return true
+		case <-done:
+			return false
+		}
+	}
+
+	var (
+		id    string
+		event string
+		data  strings.Builder
+		retry *time.Duration
+		seen  bool
+	)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			// incomplete message at the end of stream is discarded
+			send(sseResult{err: err})
+			return
+		}
+
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			if !seen {
+				continue
+			}
+
+			message := sseMessage{
+				dispatch: data.Len() != 0,
+				id:       id,
+				event:    event,
+				data:     strings.TrimSuffix(data.String(), "\n"),
+				retry:    retry,
+			}
+			if message.event == "" {
+				message.event = "message"
+			}
+
+			if !send(sseResult{message: message}) {
+				return
+			}
+
+			event, retry, seen = "", nil, false
+			data.Reset()
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		seen = true
+
+		field, value := line, ""
+		if n := strings.IndexByte(line, ':'); n >= 0 {
+			field, value = line[:n], strings.TrimPrefix(line[n+1:], " ")
+		}
+
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				id = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 63); err == nil {
+				d := time.Duration(ms) * time.Millisecond
+				retry = &d
+			}
+		}
+	}
+}
+
+// SSEEvent provides methods to inspect event read from EventStream.
+type SSEEvent struct {
+	chain chain
+	id    string
+	event string
+	data  string
+	retry *time.Duration
+}
+
+// ID returns a new String object that may be used to inspect event ID.
+//
+// As defined by the standard, event ID persists across events: if event
+// has no "id" field, the ID of the previous event is returned.
+//
+// Example:
+//  event := stream.Expect()
+//  event.ID().Equal("1")
+func (e *SSEEvent) ID() *String {
+	return &String{e.chain.enter("ID()"), e.id}
+}
+
+// Event returns a new String object that may be used to inspect event type.
+// If event has no "event" field, the type is "message".
+//
+// Example:
+//  event := stream.Expect()
+//  event.Event().Equal("update")
+func (e *SSEEvent) Event() *String {
+	return &String{e.chain.enter("Event()"), e.event}
+}
+
+// Data returns a new String object that may be used to inspect event data.
+// Multiple "data" fields are joined with newlines.
+//
+// Example:
+//  event := stream.Expect()
+//  event.Data().Equal("hello")
+//  event.Data().JSON().Object().ValueEqual("id", 123)
+func (e *SSEEvent) Data() *String {
+	return &String{e.chain.enter("Data()"), e.data}
+}
+
+// Retry returns a new Duration object that may be used to inspect
+// reconnection time sent with the event. If event has no "retry" field,
+// the returned object is not set.
+//
+// Example:
+//  event := stream.Expect()
+//  event.Retry().Equal(time.Second)
+func (e *SSEEvent) Retry() *Duration {
+	return &Duration{e.chain.enter("Retry()"), e.retry}
+}
@@ -0,0 +1,428 @@
+package httpexpect
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSEFailed(t *testing.T) {
+	chain := makeChain(newMockReporter(t))
+
+	chain.fail("fail")
+
+	stream := &EventStream{chain: chain}
+
+	stream.WithReadTimeout(time.Second).chain.assertFailed(t)
+	stream.WithoutReadTimeout().chain.assertFailed(t)
+	stream.Expect().chain.assertFailed(t)
+	stream.ExpectEnd().chain.assertFailed(t)
+	stream.LastEventID().chain.assertFailed(t)
+	stream.Retry().chain.assertFailed(t)
+	stream.Reconnect().chain.assertFailed(t)
+	stream.Close().chain.assertFailed(t)
+
+	event := &SSEEvent{chain: chain}
+
+	event.ID().chain.assertFailed(t)
+	event.Event().chain.assertFailed(t)
+	event.Data().chain.assertFailed(t)
+	event.Retry().chain.assertFailed(t)
+}
+
+func TestSSEContentType(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	for _, contentType := range []string{
+		"text/event-stream",
+		"text/event-stream; charset=utf-8",
+	} {
+		httpResp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header(map[string][]string{
+				"Content-Type": {contentType},
+			}),
+			Body: ioutil.NopCloser(bytes.NewBufferString("data: foo\n\n")),
+		}
+
+		resp := NewResponse(reporter, httpResp)
+		resp.Body().Equal("data: foo\n\n").chain.assertOK(t)
+
+		stream := resp.SSE()
+		stream.chain.assertOK(t)
+		stream.Expect().Data().Equal("foo").chain.assertOK(t)
+		stream.Close()
+	}
+
+	for _, contentType := range []string{
+		"text/plain",
+		"text/event-stream; charset=latin1",
+		"",
+	} {
+		httpResp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header(map[string][]string{
+				"Content-Type": {contentType},
+			}),
+			Body: ioutil.NopCloser(bytes.NewBufferString("data: foo\n\n")),
+		}
+
+		resp := NewResponse(reporter, httpResp)
+
+		stream := resp.SSE()
+		stream.chain.assertFailed(t)
+		stream.Expect().chain.assertFailed(t)
+	}
+}
+
+func TestSSEParse(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	body := ": comment\n" +
+		"retry: 1500\n" +
+		"\n" +
+		"data: first\n" +
+		"data: second\n" +
+		"\n" +
+		"id: 7\n" +
+		"event: ping\n" +
+		"data\n" +
+		"retry: 2000\n" +
+		"\n" +
+		"event: bad\n" +
+		"\n" +
+		"data:no space\r\n" +
+		"\r\n" +
+		"id: a\x00b\n" +
+		"data:  {\"foo\": 123}\n" +
+		"\n" +
+		"id\n" +
+		"retry: 3s\n" +
+		"data: last\n" +
+		"\n" +
+		"data: incomplete\n"
+
+	httpResp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header(map[string][]string{
+			"Content-Type": {"text/event-stream"},
+		}),
+		Body: ioutil.NopCloser(bytes.NewBufferString(body)),
+	}
+
+	stream := NewResponse(reporter, httpResp).SSE()
+	defer stream.Close()
+
+	stream.LastEventID().Empty().chain.assertOK(t)
+	stream.Retry().NotSet().chain.assertOK(t)
+
+	e1 := stream.Expect()
+	e1.chain.assertOK(t)
+	e1.ID().Empty().chain.assertOK(t)
+	e1.Event().Equal("message").chain.assertOK(t)
+	e1.Data().Equal("first\nsecond").chain.assertOK(t)
+	e1.Retry().NotSet().chain.assertOK(t)
+
+	stream.Retry().Equal(1500 * time.Millisecond).chain.assertOK(t)
+
+	e2 := stream.Expect()
+	e2.ID().Equal("7").chain.assertOK(t)
+	e2.Event().Equal("ping").chain.assertOK(t)
+	e2.Data().Empty().chain.assertOK(t)
+	e2.Retry().Equal(2 * time.Second).chain.assertOK(t)
+
+	stream.LastEventID().Equal("7").chain.assertOK(t)
+	stream.Retry().Equal(2 * time.Second).chain.assertOK(t)
+
+	e3 := stream.Expect()
+	e3.ID().Equal("7").chain.assertOK(t)
+	e3.Event().Equal("message").chain.assertOK(t)
+	e3.Data().Equal("no space").chain.assertOK(t)
+
+	e4 := stream.Expect()
+	e4.ID().Equal("7").chain.assertOK(t)
+	e4.Data().Equal(` {"foo": 123}`).chain.assertOK(t)
+	e4.Data().JSON().Object().ValueEqual("foo", 123).chain.assertOK(t)
+
+	e5 := stream.Expect()
+	e5.ID().Empty().chain.assertOK(t)
+	e5.Data().Equal("last").chain.assertOK(t)
+	e5.Retry().NotSet().chain.assertOK(t)
+
+	stream.ExpectEnd().chain.assertOK(t)
+
+	stream.Expect().chain.assertFailed(t)
+	stream.chain.reset()
+
+	stream.Close()
+	stream.Expect().chain.assertFailed(t)
+}
+
+func TestSSEExpectEnd(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	httpResp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header(map[string][]string{
+			"Content-Type": {"text/event-stream"},
+		}),
+		Body: ioutil.NopCloser(bytes.NewBufferString("data: foo\n\n")),
+	}
+
+	stream := NewResponse(reporter, httpResp).SSE()
+	defer stream.Close()
+
+	stream.ExpectEnd().chain.assertFailed(t)
+}
+
+type sseClosingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *sseClosingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestSSEBody(t *testing.T) {
+	newResponse := func() (*Response, *sseClosingBody) {
+		body := &sseClosingBody{Reader: strings.NewReader("id: 1\ndata: hello\n\n")}
+		resp := NewResponse(newMockReporter(t), &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header(map[string][]string{
+				"Content-Type": {"text/event-stream"},
+			}),
+			Body: body,
+		})
+		return resp, body
+	}
+
+	t.Run("body", func(t *testing.T) {
+		resp, body := newResponse()
+		assert.False(t, body.closed)
+
+		resp.Body().Equal("id: 1\ndata: hello\n\n")
+		resp.chain.assertOK(t)
+		assert.True(t, body.closed)
+
+		stream := resp.SSE()
+		defer stream.Close()
+
+		stream.Expect().Data().Equal("hello")
+		stream.ExpectEnd()
+		stream.chain.assertOK(t)
+	})
+
+	t.Run("sse", func(t *testing.T) {
+		resp, body := newResponse()
+
+		stream := resp.SSE()
+		stream.Expect().Data().Equal("hello")
+		stream.chain.assertOK(t)
+		assert.False(t, body.closed)
+
+		resp.Body()
+		resp.chain.assertFailed(t)
+		resp.chain.reset()
+
+		resp.SSE()
+		resp.chain.assertFailed(t)
+
+		stream.Close()
+		assert.True(t, body.closed)
+	})
+
+	t.Run("release", func(t *testing.T) {
+		resp, body := newResponse()
+
+		resp.Status(http.StatusOK)
+		resp.release()
+		assert.True(t, body.closed)
+	})
+}
+
+func TestSSEExpectEventually(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		started  int
+		finished int
+	)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		started++
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusAccepted)
+		w.(http.Flusher).Flush()
+
+		<-r.Context().Done()
+
+		mu.Lock()
+		finished++
+		mu.Unlock()
+	})
+
+	config := newBinderConfig(t, handler)
+
+	resp := NewRequest(config, "GET", "/events").
+		WithHeader("Accept", "text/event-stream").
+		ExpectEventually(100*time.Millisecond, 10*time.Millisecond,
+			func(resp *Response) {
+				resp.Status(http.StatusOK)
+			})
+	resp.chain.assertFailed(t)
+	resp.release()
+
+	// every handler returns when its response is released
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		mu.Lock()
+		done := finished == started
+		mu.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.True(t, started > 1)
+	assert.Equal(t, started, finished)
+}
+
+func createSSEHandler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		switch r.Header.Get("Last-Event-ID") {
+		case "":
+			fmt.Fprint(w, "id: 1\ndata: {\"n\": 1}\n\n")
+			fmt.Fprint(w, "id: 2\nevent: update\nretry: 100\ndata: {\"n\": 2}\n\n")
+			w.(http.Flusher).Flush()
+
+			// hang until client disconnects
+			select {
+			case <-r.Context().Done():
+			case <-time.After(10 * time.Second):
+				t.Error("expected request cancellation")
+			}
+
+		case "2":
+			fmt.Fprint(w, "id: 3\ndata: {\"n\": 3}\n\n")
+
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+
+	return mux
+}
+
+func testSSEHandler(t *testing.T, e *Expect) {
+	stream := e.GET("/events").
+		WithHeader("Accept", "text/event-stream").
+		Expect().
+		Status(http.StatusOK).
+		SSE()
+
+	defer stream.Close()
+
+	stream.chain.assertOK(t)
+
+	e1 := stream.WithReadTimeout(5 * time.Second).Expect()
+	e1.ID().Equal("1").chain.assertOK(t)
+	e1.Event().Equal("message").chain.assertOK(t)
+	e1.Data().JSON().Object().ValueEqual("n", 1).chain.assertOK(t)
+
+	e2 := stream.Expect()
+	e2.ID().Equal("2").chain.assertOK(t)
+	e2.Event().Equal("update").chain.assertOK(t)
+	e2.Data().JSON().Object().ValueEqual("n", 2).chain.assertOK(t)
+
+	stream.Retry().Equal(100 * time.Millisecond).chain.assertOK(t)
+
+	stream.WithReadTimeout(10 * time.Millisecond).Expect().chain.assertFailed(t)
+	stream.chain.reset()
+
+	stream.WithoutReadTimeout()
+
+	stream.LastEventID().Equal("2").chain.assertOK(t)
+
+	stream.Reconnect().chain.assertOK(t)
+
+	e3 := stream.Expect()
+	e3.ID().Equal("3").chain.assertOK(t)
+	e3.Data().JSON().Object().ValueEqual("n", 3).chain.assertOK(t)
+
+	stream.ExpectEnd().chain.assertOK(t)
+
+	stream.Reconnect().chain.assertFailed(t)
+}
+
+func TestSSEBinder(t *testing.T) {
+	handler := createSSEHandler(t)
+
+	testSSEHandler(t, WithConfig(Config{
+		BaseURL:  "http://example.com",
+		Reporter: newMockReporter(t),
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+	}))
+}
+
+func TestSSELive(t *testing.T) {
+	handler := createSSEHandler(t)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	testSSEHandler(t, WithConfig(Config{
+		BaseURL:  server.URL,
+		Reporter: newMockReporter(t),
+	}))
+}
+
+func TestSSEPrinters(t *testing.T) {
+	handler := createSSEHandler(t)
+
+	printer := NewHARPrinter("")
+
+	e := WithConfig(Config{
+		BaseURL:  "http://example.com",
+		Reporter: newMockReporter(t),
+		Client: &http.Client{
+			Transport: NewBinder(handler),
+		},
+		Printers: []Printer{
+			NewDebugPrinter(t, true),
+			printer,
+		},
+	})
+
+	stream := e.GET("/events").
+		WithHeader("Accept", "text/event-stream").
+		Expect().
+		SSE()
+	defer stream.Close()
+
+	stream.Expect().ID().Equal("1").chain.assertOK(t)
+
+	entries := readHAR(t, printer)["log"].(map[string]interface{})["entries"]
+	assert.Equal(t, 1, len(entries.([]interface{})))
+}
@@ -1,6 +1,7 @@
 package httpexpect
 
 import (
+	"encoding/json"
 	"net/http"
 	"regexp"
 	"strings"
@@ -22,7 +23,7 @@ type String struct {
 // Example:
 //  str := NewString(t, "Hello")
 func NewString(reporter Reporter, value string) *String {
-	return &String{makeChain(reporter), value}
+	return &String{makeChain(reporter).root("String"), value}
 }
 
 // Raw returns underlying value attached to String.
@@ -40,6 +41,16 @@ func (s *String) Path(path string) *Value {
 	return getPath(&s.chain, s.value, path)
 }
 
+// PathAll is similar to Value.PathAll.
+func (s *String) PathAll(path string) *Array {
+	return getPathAll(&s.chain, s.value, path)
+}
+
+// Pointer is similar to Value.Pointer.
+func (s *String) Pointer(pointer string) *Value {
+	return getPointer(&s.chain, s.value, pointer)
+}
+
 // Schema is similar to Value.Schema.
 func (s *String) Schema(schema interface{}) *String {
 	checkSchema(&s.chain, s.value, schema)
@@ -52,7 +63,7 @@ func (s *String) Schema(schema interface{}) *String {
 //  str := NewString(t, "Hello")
 //  str.Length().Equal(5)
 func (s *String) Length() *Number {
-	return &Number{s.chain, float64(len(s.value))}
+	return &Number{s.chain.enter("Length()"), float64(len(s.value))}
 }
 
 // DateTime parses date/time from string an returns a new DateTime object.
@@ -69,7 +80,7 @@ func (s *String) Length() *Number {
 //   str.DateTime(time.RFC822).Lt(time.Now())
 func (s *String) DateTime(layout ...string) *DateTime {
 	if s.chain.failed() {
-		return &DateTime{s.chain, time.Unix(0, 0)}
+		return &DateTime{s.chain.enter("DateTime()"), time.Unix(0, 0)}
 	}
 	var (
 		t   time.Time
@@ -82,9 +93,29 @@ func (s *String) DateTime(layout ...string) *DateTime {
 	}
 	if err != nil {
 		s.chain.fail(err.Error())
-		return &DateTime{s.chain, time.Unix(0, 0)}
+		return &DateTime{s.chain.enter("DateTime()"), time.Unix(0, 0)}
+	}
+	return &DateTime{s.chain.enter("DateTime()"), t}
+}
+
+// JSON parses JSON from string and returns a new Value object.
+//
+// If parsing error occurred, JSON reports failure and returns empty
+// (but non-nil) object.
+//
+// Example:
+//  str := NewString(t, `{"foo": 123}`)
+//  str.JSON().Object().ValueEqual("foo", 123)
+func (s *String) JSON() *Value {
+	if s.chain.failed() {
+		return &Value{s.chain.enter("JSON()").rootJSON(), nil}
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(s.value), &value); err != nil {
+		s.chain.fail(err.Error())
+		return &Value{s.chain.enter("JSON()").rootJSON(), nil}
 	}
-	return &DateTime{s.chain, t}
+	return &Value{s.chain.enter("JSON()").rootJSON(), value}
 }
 
 // Empty succeeds if string is empty.
@@ -112,7 +143,8 @@ func (s *String) NotEmpty() *String {
 //  str.Equal("Hello")
 func (s *String) Equal(value string) *String {
 	if !(s.value == value) {
-		s.chain.fail("\nexpected string equal to:\n %q\n\nbut got:\n %q",
+		s.chain.failExpected(value, s.value,
+			"\nexpected string equal to:\n %q\n\nbut got:\n %q",
 			value, s.value)
 	}
 	return s
@@ -242,17 +274,17 @@ func (s *String) Match(re string) *Match {
 	r, err := regexp.Compile(re)
 	if err != nil {
 		s.chain.fail(err.Error())
-		return makeMatch(s.chain, nil, nil)
+		return makeMatch(s.chain.enter("Match()"), nil, nil)
 	}
 
 	m := r.FindStringSubmatch(s.value)
 	if m == nil {
 		s.chain.fail("\nexpected string matching regexp:\n `%s`\n\nbut got:\n %q",
 			re, s.value)
-		return makeMatch(s.chain, nil, nil)
+		return makeMatch(s.chain.enter("Match()"), nil, nil)
 	}
 
-	return makeMatch(s.chain, m, r.SubexpNames())
+	return makeMatch(s.chain.enter("Match()"), m, r.SubexpNames())
 }
 
 // MatchAll find all matches in string for given regexp and returns a list
@@ -287,7 +319,7 @@ func (s *String) MatchAll(re string) []Match {
 	ret := []Match{}
 	for _, m := range matches {
 		ret = append(ret, *makeMatch(
-			s.chain,
+			s.chain.enter("MatchAll()[%d]", len(ret)),
 			m,
 			r.SubexpNames()))
 	}
@@ -18,6 +18,7 @@ func TestStringFailed(t *testing.T) {
 	value.Schema("")
 
 	value.DateTime()
+	value.JSON().chain.assertFailed(t)
 	value.Empty()
 	value.NotEmpty()
 	value.Equal("")
@@ -215,6 +216,25 @@ func TestStringDateTime(t *testing.T) {
 	assert.True(t, time.Unix(0, 0).Equal(dt3.Raw()))
 }
 
+func TestStringJSON(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	value1 := NewString(reporter, `{"foo": [1, "bar"]}`)
+	json1 := value1.JSON()
+	value1.chain.assertOK(t)
+	json1.chain.assertOK(t)
+	json1.Object().Value("foo").Array().Elements(1, "bar").chain.assertOK(t)
+
+	value2 := NewString(reporter, `"foo"`)
+	value2.JSON().String().Equal("foo").chain.assertOK(t)
+
+	value3 := NewString(reporter, "bad")
+	json3 := value3.JSON()
+	value3.chain.assertFailed(t)
+	json3.chain.assertFailed(t)
+	assert.Nil(t, json3.Raw())
+}
+
 func TestStringMatchOne(t *testing.T) {
 	reporter := newMockReporter(t)
 
@@ -36,7 +36,7 @@ type Value struct {
 //  value := NewValue(t, nil)
 //  value.Null()
 func NewValue(reporter Reporter, value interface{}) *Value {
-	chain := makeChain(reporter)
+	chain := makeChain(reporter).root("Value").rootJSON()
 	if value != nil {
 		value, _ = canonValue(&chain, value)
 	}
@@ -56,12 +56,20 @@ func (v *Value) Raw() interface{} {
 // Path returns a new Value object for child object(s) matching given
 // JSONPath expression.
 //
-// JSONPath is a simple XPath-like query language.
-// See http://goessner.net/articles/JsonPath/.
+// JSONPath is a query language for JSON, defined in RFC 9535.
+// See https://www.rfc-editor.org/rfc/rfc9535.
 //
-// We currently use https://github.com/yalp/jsonpath, which implements
-// only a subset of JSONPath, yet useful for simple queries. It doesn't
-// support filters and requires double quotes for strings.
+// If query is singular, i.e. consists only of member names and array
+// indices, like "$.users[0].name", returned Value contains the selected
+// node. If there is no such node, failure is reported, telling which
+// segment of query failed to resolve.
+//
+// Otherwise, returned Value contains an array of all selected nodes,
+// which may be empty. Use PathAll to always get an array.
+//
+// Filter expressions and standard functions length(), count(), match(),
+// search(), and value() are supported. Object members are visited in
+// order of their names.
 //
 // Example 1:
 //  json := `{"users": [{"name": "john"}, {"name": "bob"}]}`
@@ -81,6 +89,38 @@ func (v *Value) Path(path string) *Value {
 	return getPath(&v.chain, v.value, path)
 }
 
+// PathAll returns a new Array object with all nodes matching given JSONPath
+// expression. Unlike Path, it always returns array, even if query is
+// singular, and doesn't report failure if nothing is selected.
+//
+// See Path for details on JSONPath.
+//
+// Example:
+//  json := `{"users": [{"name": "john", "age": 30}, {"name": "bob", "age": 17}]}`
+//  value := NewValue(t, json)
+//
+//  value.PathAll("$.users[?@.age >= 18].name").ContainsOnly("john")
+//  value.PathAll("$.users[?match(@.name, 'j.*')]").Length().Equal(1)
+func (v *Value) PathAll(path string) *Array {
+	return getPathAll(&v.chain, v.value, path)
+}
+
+// Pointer returns a new Value object for child object referenced by given
+// JSON Pointer, as defined in RFC 6901.
+//
+// Empty pointer references the whole value. If referenced node doesn't
+// exist, failure is reported, telling which segment of pointer failed to
+// resolve.
+//
+// Example:
+//  json := `{"users": [{"name": "john"}, {"name": "bob"}]}`
+//  value := NewValue(t, json)
+//
+//  value.Pointer("/users/0/name").String().Equal("john")
+func (v *Value) Pointer(pointer string) *Value {
+	return getPointer(&v.chain, v.value, pointer)
+}
+
 // Schema succeeds if value matches given JSON Schema.
 //
 // JSON Schema specifies a JSON-based format to define the structure of
@@ -136,7 +176,7 @@ func (v *Value) Object() *Object {
 		v.chain.fail("\nexpected object value (map or struct), but got:\n%s",
 			dumpValue(v.value))
 	}
-	return &Object{v.chain, data}
+	return &Object{v.chain.enter("Object()"), data}
 }
 
 // Array returns a new Array attached to underlying value.
@@ -153,7 +193,7 @@ func (v *Value) Array() *Array {
 		v.chain.fail("\nexpected array value, but got:\n%s",
 			dumpValue(v.value))
 	}
-	return &Array{v.chain, data}
+	return &Array{v.chain.enter("Array()"), data}
 }
 
 // String returns a new String attached to underlying value.
@@ -170,7 +210,7 @@ func (v *Value) String() *String {
 		v.chain.fail("\nexpected string value, but got:\n%s",
 			dumpValue(v.value))
 	}
-	return &String{v.chain, data}
+	return &String{v.chain.enter("String()"), data}
 }
 
 // Number returns a new Number attached to underlying value.
@@ -187,7 +227,7 @@ func (v *Value) Number() *Number {
 		v.chain.fail("\nexpected numeric value, but got:\n%s",
 			dumpValue(v.value))
 	}
-	return &Number{v.chain, data}
+	return &Number{v.chain.enter("Number()"), data}
 }
 
 // Boolean returns a new Boolean attached to underlying value.
@@ -204,7 +244,7 @@ func (v *Value) Boolean() *Boolean {
 		v.chain.fail("\nexpected boolean value, but got:\n%s",
 			dumpValue(v.value))
 	}
-	return &Boolean{v.chain, data}
+	return &Boolean{v.chain.enter("Boolean()"), data}
 }
 
 // Null succeeds if value is nil.
@@ -259,7 +299,8 @@ func (v *Value) Equal(value interface{}) *Value {
 		return v
 	}
 	if !reflect.DeepEqual(expected, v.value) {
-		v.chain.fail("\nexpected value equal to:\n%s\n\nbut got:\n%s\n\ndiff:\n%s",
+		v.chain.failExpected(expected, v.value,
+			"\nexpected value equal to:\n%s\n\nbut got:\n%s\n\ndiff:\n%s",
 			dumpValue(expected),
 			dumpValue(v.value),
 			diffValues(expected, v.value))
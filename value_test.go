@@ -412,7 +412,7 @@ func TestValuePathError(t *testing.T) {
 	}
 }
 
-// based on github.com/yalp/jsonpath
+// based on github.com/yalp/jsonpath, adjusted for RFC 9535
 func TestValuePathExpressions(t *testing.T) {
 	data := map[string]interface{}{
 		"A": []interface{}{
@@ -557,7 +557,7 @@ func TestValuePathExpressions(t *testing.T) {
 			"$..A..*":      []interface{}{"string", 23.3, 3.0, true, false, nil, "string3"},
 			"$.A..*":       []interface{}{"string", 23.3, 3.0, true, false, nil},
 			"$.A.*":        []interface{}{"string", 23.3, 3.0, true, false, nil},
-			"$..A[0,1]":    []interface{}{"string", 23.3},
+			"$..A[0,1]":    []interface{}{"string", 23.3, "string3"},
 			"$..A[0]":      []interface{}{"string", "string3"},
 			"$.*.V[0]":     []interface{}{"string2a", "string4a"},
 			"$.*.V[1]":     []interface{}{"string2b", "string4b"},
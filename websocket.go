@@ -25,7 +25,7 @@ type Websocket struct {
 // NewWebsocket returns a new Websocket given a Config with Reporter and
 // Printers, and websocket.Conn to be inspected and handled.
 func NewWebsocket(config Config, conn *websocket.Conn) *Websocket {
-	return makeWebsocket(config, makeChain(config.Reporter), conn)
+	return makeWebsocket(config, makeChain(config.Reporter).root("Websocket"), conn)
 }
 
 func makeWebsocket(config Config, chain chain, conn *websocket.Conn) *Websocket {
@@ -75,7 +75,7 @@ func (c *Websocket) WithoutWriteTimeout() *Websocket {
 // Subprotocol returns a new String object that may be used to inspect
 // negotiated protocol for the connection.
 func (c *Websocket) Subprotocol() *String {
-	s := &String{chain: c.chain}
+	s := &String{chain: c.chain.enter("Subprotocol()")}
 	if c.conn != nil {
 		s.value = c.conn.Subprotocol()
 	}
@@ -91,18 +91,18 @@ func (c *Websocket) Subprotocol() *String {
 func (c *Websocket) Expect() *WebsocketMessage {
 	switch {
 	case c.chain.failed():
-		return makeWebsocketMessage(c.chain)
+		return makeWebsocketMessage(c.chain.enter("Expect()"))
 	case c.conn == nil:
 		c.chain.fail("\nunexpected read from failed WebSocket connection")
-		return makeWebsocketMessage(c.chain)
+		return makeWebsocketMessage(c.chain.enter("Expect()"))
 	case c.isClosed:
 		c.chain.fail("\nunexpected read from closed WebSocket connection")
-		return makeWebsocketMessage(c.chain)
+		return makeWebsocketMessage(c.chain.enter("Expect()"))
 	case !c.setReadDeadline():
-		return makeWebsocketMessage(c.chain)
+		return makeWebsocketMessage(c.chain.enter("Expect()"))
 	}
 	var err error
-	m := makeWebsocketMessage(c.chain)
+	m := makeWebsocketMessage(c.chain.enter("Expect()"))
 	m.typ, m.content, err = c.conn.ReadMessage()
 	if err != nil {
 		if cls, ok := err.(*websocket.CloseError); ok {
@@ -114,7 +114,7 @@ func (c *Websocket) Expect() *WebsocketMessage {
 			c.chain.fail(
 				"\nexpected read WebSocket connection, "+
 					"but got failure: %s", err.Error())
-			return makeWebsocketMessage(c.chain)
+			return makeWebsocketMessage(c.chain.enter("Expect()"))
 		}
 	} else {
 		c.printRead(m.typ, m.content, m.closeCode)
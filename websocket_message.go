@@ -26,7 +26,7 @@ func NewWebsocketMessage(
 	reporter Reporter, typ int, content []byte, closeCode ...int,
 ) *WebsocketMessage {
 	m := &WebsocketMessage{
-		chain:   makeChain(reporter),
+		chain:   makeChain(reporter).root("WebsocketMessage"),
 		typ:     typ,
 		content: content,
 	}
@@ -140,7 +140,7 @@ func (m *WebsocketMessage) NotType(typ ...int) *WebsocketMessage {
 			} else {
 				m.chain.fail(
 					"\nexpected message type not equal:\n %d\n\nbut it did",
-					typ[0], m.typ)
+					typ[0])
 			}
 			return m
 		}
@@ -218,7 +218,7 @@ func (m *WebsocketMessage) NotCode(code ...int) *WebsocketMessage {
 			} else {
 				m.chain.fail(
 					"\nexpected close code not equal:\n %d\n\nbut it did",
-					code[0], m.closeCode)
+					code[0])
 			}
 			return m
 		}
@@ -229,7 +229,7 @@ func (m *WebsocketMessage) NotCode(code ...int) *WebsocketMessage {
 func (m *WebsocketMessage) checkClosed(where string) bool {
 	if m.typ != websocket.CloseMessage {
 		m.chain.fail(
-			"\nunexpected %s usage for not '%' WebSocket message type\n\n"+
+			"\nunexpected %s usage for not '%s' WebSocket message type\n\n"+
 				"got type:\n %s",
 			where,
 			wsMessageTypeName(websocket.CloseMessage),
@@ -247,7 +247,7 @@ func (m *WebsocketMessage) checkClosed(where string) bool {
 //  msg.Body().NotEmpty()
 //  msg.Body().Length().Equal(100)
 func (m *WebsocketMessage) Body() *String {
-	return &String{m.chain, string(m.content)}
+	return &String{m.chain.enter("Body()"), string(m.content)}
 }
 
 // NoContent succeeds if WebSocket message has no content (is empty).
@@ -280,7 +280,8 @@ func (m *WebsocketMessage) NoContent() *WebsocketMessage {
 //  msg := conn.Expect()
 //  msg.JSON().Array().Elements("foo", "bar")
 func (m *WebsocketMessage) JSON() *Value {
-	return &Value{m.chain, m.getJSON()}
+	value := m.getJSON()
+	return &Value{m.chain.enter("JSON()").rootJSON(), value}
 }
 
 func (m *WebsocketMessage) getJSON() interface{} {
@@ -0,0 +1,750 @@
+package httpexpect
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/antchfx/xpath"
+)
+
+// XML provides methods to inspect attached XML element.
+//
+// XML elements may be navigated using XPath 1.0 expressions, inspected
+// directly, or converted to Value or Object, to use the same assertions
+// as for JSON.
+//
+// Namespaced elements and attributes may be referenced in XPath expressions
+// and in Attribute() using prefixes registered with WithNamespace(). For
+// namespaces that are not registered, prefixes used in the document itself
+// are used, so elements in unregistered default namespace are referenced
+// without prefix.
+type XML struct {
+	chain      chain
+	node       *xmlNode
+	namespaces map[string]string
+}
+
+type xmlNode struct {
+	typ      xpath.NodeType
+	prefix   string
+	space    string
+	local    string
+	data     string
+	attrs    []*xmlNode
+	parent   *xmlNode
+	children []*xmlNode
+	index    int
+}
+
+// NewXML returns a new XML object given a reporter used to report failures
+// and XML document to be inspected. The returned object is attached to the
+// root element of the document.
+//
+// reporter should not be nil. If content is not a well-formed XML document,
+// failure is reported.
+//
+// Example:
+//  x := NewXML(t, `<user id="1"><name>john</name></user>`)
+//  x.Attribute("id").Equal("1")
+//  x.Element("name").Text().Equal("john")
+func NewXML(reporter Reporter, content string) *XML {
+	return newXML(makeChain(reporter).root("XML"), []byte(content))
+}
+
+func newXML(chain chain, content []byte) *XML {
+	if chain.failed() {
+		return &XML{chain, nil, nil}
+	}
+
+	doc, err := parseXML(content)
+	if err != nil {
+		chain.fail("\nexpected valid XML document, but got error:\n %s", err.Error())
+		return &XML{chain, nil, nil}
+	}
+
+	for _, child := range doc.children {
+		if child.typ == xpath.ElementNode {
+			return &XML{chain, child, nil}
+		}
+	}
+
+	return &XML{chain, nil, nil}
+}
+
+// WithNamespace returns a new XML object attached to the same element,
+// with given prefix registered for given namespace URI. Registered prefix
+// may be used in XPath expressions and attribute names.
+//
+// Example:
+//  x := NewXML(t, `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope">
+//                    <s:Body/>
+//                  </s:Envelope>`)
+//  x.WithNamespace("soap", "http://www.w3.org/2003/05/soap-envelope").
+//    Element("soap:Body")
+func (x *XML) WithNamespace(prefix, uri string) *XML {
+	namespaces := make(map[string]string, len(x.namespaces)+1)
+	for p, u := range x.namespaces {
+		namespaces[p] = u
+	}
+	namespaces[prefix] = uri
+	return &XML{x.chain, x.node, namespaces}
+}
+
+// Name returns a new String object that may be used to inspect local
+// name of element, i.e. the name without namespace prefix.
+//
+// Example:
+//  x := NewXML(t, `<user/>`)
+//  x.Name().Equal("user")
+func (x *XML) Name() *String {
+	if x.chain.failed() {
+		return &String{x.chain.enter("Name()"), ""}
+	}
+	return &String{x.chain.enter("Name()"), x.node.local}
+}
+
+// Namespace returns a new String object that may be used to inspect
+// namespace URI of element.
+//
+// Example:
+//  x := NewXML(t, `<user xmlns="http://example.com/users"/>`)
+//  x.Namespace().Equal("http://example.com/users")
+func (x *XML) Namespace() *String {
+	if x.chain.failed() {
+		return &String{x.chain.enter("Namespace()"), ""}
+	}
+	return &String{x.chain.enter("Namespace()"), x.node.space}
+}
+
+// Text returns a new String object that may be used to inspect text
+// content of element, i.e. concatenation of all text nodes inside it.
+//
+// Example:
+//  x := NewXML(t, `<name>john <b>smith</b></name>`)
+//  x.Text().Equal("john smith")
+func (x *XML) Text() *String {
+	if x.chain.failed() {
+		return &String{x.chain.enter("Text()"), ""}
+	}
+	return &String{x.chain.enter("Text()"), x.node.text()}
+}
+
+// Attribute returns a new String object that may be used to inspect
+// value of given attribute.
+//
+// If element has no such attribute, failure is reported.
+//
+// Example:
+//  x := NewXML(t, `<user id="1" xmlns:a="http://example.com/a" a:role="admin"/>`)
+//  x.Attribute("id").Equal("1")
+//  x.Attribute("a:role").Equal("admin")
+func (x *XML) Attribute(name string) *String {
+	chain := x.chain.enter("Attribute(%q)", name)
+
+	if chain.failed() {
+		return &String{chain, ""}
+	}
+
+	attr := x.attribute(name)
+	if attr == nil {
+		chain.fail("\nexpected element %q containing attribute %q, but got:\n %s",
+			x.qualifiedName(x.node), name, x.dumpAttributes())
+		return &String{chain, ""}
+	}
+
+	return &String{chain, attr.data}
+}
+
+// ContainsAttribute succeeds if element has given attribute.
+//
+// Example:
+//  x := NewXML(t, `<user id="1"/>`)
+//  x.ContainsAttribute("id")
+func (x *XML) ContainsAttribute(name string) *XML {
+	if x.chain.failed() {
+		return x
+	}
+	if x.attribute(name) == nil {
+		x.chain.fail("\nexpected element %q containing attribute %q, but got:\n %s",
+			x.qualifiedName(x.node), name, x.dumpAttributes())
+	}
+	return x
+}
+
+// NotContainsAttribute succeeds if element doesn't have given attribute.
+//
+// Example:
+//  x := NewXML(t, `<user id="1"/>`)
+//  x.NotContainsAttribute("name")
+func (x *XML) NotContainsAttribute(name string) *XML {
+	if x.chain.failed() {
+		return x
+	}
+	if x.attribute(name) != nil {
+		x.chain.fail("\nexpected element %q not containing attribute %q, but got:\n %s",
+			x.qualifiedName(x.node), name, x.dumpAttributes())
+	}
+	return x
+}
+
+// Element returns a new XML object attached to the first element matching
+// given XPath expression. The expression is evaluated relative to current
+// element.
+//
+// If there are no matching elements, or expression matches nodes other
+// than elements, failure is reported.
+//
+// Example:
+//  x := NewXML(t, `<users><user id="1"/><user id="2"/></users>`)
+//  x.Element("user[@id='2']").Attribute("id").Equal("2")
+//  x.Element("//user").Attribute("id").Equal("1")
+func (x *XML) Element(expr string) *XML {
+	chain := x.chain.enter("Element(%q)", expr)
+
+	elements, ok := x.selectElements(&chain, expr)
+	if !ok {
+		return &XML{chain, nil, x.namespaces}
+	}
+
+	if len(elements) == 0 {
+		chain.fail("\nexpected element matching XPath:\n %q\n\nbut got nothing", expr)
+		return &XML{chain, nil, x.namespaces}
+	}
+
+	return &XML{chain, elements[0], x.namespaces}
+}
+
+// Elements returns a slice of XML objects attached to all elements matching
+// given XPath expression. The expression is evaluated relative to current
+// element.
+//
+// If expression matches nodes other than elements, failure is reported.
+//
+// Example:
+//  x := NewXML(t, `<users><user id="1"/><user id="2"/></users>`)
+//  for _, user := range x.Elements("user") {
+//      user.ContainsAttribute("id")
+//  }
+func (x *XML) Elements(expr string) []XML {
+	chain := x.chain.enter("Elements(%q)", expr)
+
+	elements, ok := x.selectElements(&chain, expr)
+	if !ok {
+		return []XML{}
+	}
+
+	ret := make([]XML, 0, len(elements))
+	for n, element := range elements {
+		ret = append(ret, XML{chain.enter("[%d]", n), element, x.namespaces})
+	}
+
+	return ret
+}
+
+// XPath returns a new Value object with result of given XPath expression,
+// evaluated relative to current element.
+//
+// Numeric, string, and boolean results are returned as is. Node sets are
+// returned as arrays, where elements are converted as described in Value(),
+// and attribute, text, and comment nodes are converted to strings.
+//
+// Example:
+//  x := NewXML(t, `<users><user id="1"/><user id="2"/></users>`)
+//  x.XPath("count(user)").Number().Equal(2)
+//  x.XPath("user/@id").Array().Elements("1", "2")
+//  x.XPath("string(user[2]/@id)").String().Equal("2")
+func (x *XML) XPath(expr string) *Value {
+	chain := x.chain.enter("XPath(%q)", expr).rootJSON()
+
+	if chain.failed() {
+		return &Value{chain, nil}
+	}
+
+	compiled, err := xpath.Compile(expr)
+	if err != nil {
+		chain.fail("\ninvalid XPath expression %q:\n %s", expr, err.Error())
+		return &Value{chain, nil}
+	}
+
+	switch result := compiled.Evaluate(x.navigator()).(type) {
+	case *xpath.NodeIterator:
+		values := []interface{}{}
+		for _, node := range x.collect(result) {
+			values = append(values, x.convert(node))
+		}
+		return &Value{chain, values}
+
+	default:
+		return &Value{chain, result}
+	}
+}
+
+// Value returns a new Value object with element converted to JSON-like
+// representation, so that it can be inspected using the same assertions
+// as JSON values.
+//
+// Element without attributes and child elements is converted to string
+// with its trimmed text content. Other elements are converted to objects,
+// where attributes are stored under "@name" keys, child elements are stored
+// under their names (as arrays if there are several elements with the same
+// name), and non-empty trimmed text content is stored under "#text" key.
+// Namespaced names are prefixed as described in XML.
+//
+// Example:
+//  x := NewXML(t, `<user id="1"><name>john</name><role>a</role><role>b</role></user>`)
+//  x.Value().Equal(map[string]interface{}{
+//      "@id":  "1",
+//      "name": "john",
+//      "role": []interface{}{"a", "b"},
+//  })
+func (x *XML) Value() *Value {
+	chain := x.chain.enter("Value()").rootJSON()
+
+	if chain.failed() {
+		return &Value{chain, nil}
+	}
+
+	return &Value{chain, x.convert(x.node)}
+}
+
+// Object returns a new Object with element converted to JSON-like
+// representation, as described in Value(). Unlike Value(), element
+// without attributes and child elements is converted to object with
+// the only "#text" key, or empty object if element has no text.
+//
+// Example:
+//  x := NewXML(t, `<user id="1"><name>john</name></user>`)
+//  x.Object().ValueEqual("@id", "1")
+//  x.Element("name").Object().ValueEqual("#text", "john")
+func (x *XML) Object() *Object {
+	chain := x.chain.enter("Object()").rootJSON()
+
+	if chain.failed() {
+		return &Object{chain, nil}
+	}
+
+	switch value := x.convert(x.node).(type) {
+	case map[string]interface{}:
+		return &Object{chain, value}
+	case string:
+		if value == "" {
+			return &Object{chain, map[string]interface{}{}}
+		}
+		return &Object{chain, map[string]interface{}{"#text": value}}
+	default:
+		return &Object{chain, nil}
+	}
+}
+
+func (x *XML) navigator() *xmlNavigator {
+	root := x.node
+	for root.parent != nil {
+		root = root.parent
+	}
+	return &xmlNavigator{x, root, x.node, -1}
+}
+
+func (x *XML) selectElements(chain *chain, expr string) ([]*xmlNode, bool) {
+	if chain.failed() {
+		return nil, false
+	}
+
+	compiled, err := xpath.Compile(expr)
+	if err != nil {
+		chain.fail("\ninvalid XPath expression %q:\n %s", expr, err.Error())
+		return nil, false
+	}
+
+	result := compiled.Evaluate(x.navigator())
+
+	it, ok := result.(*xpath.NodeIterator)
+	if !ok {
+		chain.fail("\nexpected XPath expression %q selecting elements,"+
+			" but it evaluates to:\n%s", expr, dumpValue(result))
+		return nil, false
+	}
+
+	nodes := x.collect(it)
+	for _, node := range nodes {
+		if node.typ != xpath.ElementNode {
+			chain.fail("\nexpected XPath expression %q selecting elements,"+
+				" but it selects:\n%s", expr, dumpValue(x.convert(node)))
+			return nil, false
+		}
+	}
+
+	return nodes, true
+}
+
+func (x *XML) collect(it *xpath.NodeIterator) []*xmlNode {
+	var nodes []*xmlNode
+	for it.MoveNext() {
+		nav := it.Current().(*xmlNavigator)
+		if nav.attr >= 0 {
+			nodes = append(nodes, nav.curr.attrs[nav.attr])
+		} else {
+			nodes = append(nodes, nav.curr)
+		}
+	}
+	return nodes
+}
+
+func (x *XML) attribute(name string) *xmlNode {
+	for _, attr := range x.node.attrs {
+		if x.qualifiedName(attr) == name {
+			return attr
+		}
+	}
+	return nil
+}
+
+func (x *XML) dumpAttributes() string {
+	attrs := make([]string, 0, len(x.node.attrs))
+	for _, attr := range x.node.attrs {
+		attrs = append(attrs, fmt.Sprintf("%s=%q", x.qualifiedName(attr), attr.data))
+	}
+	return "[" + strings.Join(attrs, " ") + "]"
+}
+
+// prefix returns prefix that should be used to reference given node.
+func (x *XML) prefix(node *xmlNode) string {
+	if node.space == "" {
+		return ""
+	}
+	for prefix, uri := range x.namespaces {
+		if uri == node.space {
+			return prefix
+		}
+	}
+	if _, ok := x.namespaces[node.prefix]; ok {
+		// document prefix is registered for another namespace
+		return "{" + node.space + "}"
+	}
+	return node.prefix
+}
+
+func (x *XML) qualifiedName(node *xmlNode) string {
+	if prefix := x.prefix(node); prefix != "" {
+		return prefix + ":" + node.local
+	}
+	return node.local
+}
+
+func (x *XML) convert(node *xmlNode) interface{} {
+	switch node.typ {
+	case xpath.RootNode:
+		object := map[string]interface{}{}
+		for _, child := range node.children {
+			if child.typ == xpath.ElementNode {
+				object[x.qualifiedName(child)] = x.convert(child)
+			}
+		}
+		return object
+
+	case xpath.ElementNode:
+		object := map[string]interface{}{}
+		for _, attr := range node.attrs {
+			object["@"+x.qualifiedName(attr)] = attr.data
+		}
+
+		var text strings.Builder
+		for _, child := range node.children {
+			switch child.typ {
+			case xpath.ElementNode:
+				name := x.qualifiedName(child)
+				value := x.convert(child)
+				switch prev := object[name].(type) {
+				case nil:
+					object[name] = value
+				case []interface{}:
+					object[name] = append(prev, value)
+				default:
+					object[name] = []interface{}{prev, value}
+				}
+			case xpath.TextNode:
+				text.WriteString(child.data)
+			}
+		}
+
+		trimmed := strings.TrimSpace(text.String())
+		if len(object) == 0 {
+			return trimmed
+		}
+		if trimmed != "" {
+			object["#text"] = trimmed
+		}
+		return object
+
+	default:
+		return node.data
+	}
+}
+
+// text returns XPath string-value of node.
+func (n *xmlNode) text() string {
+	switch n.typ {
+	case xpath.RootNode, xpath.ElementNode:
+		var b strings.Builder
+		var walk func(*xmlNode)
+		walk = func(n *xmlNode) {
+			for _, child := range n.children {
+				switch child.typ {
+				case xpath.ElementNode:
+					walk(child)
+				case xpath.TextNode:
+					b.WriteString(child.data)
+				}
+			}
+		}
+		walk(n)
+		return b.String()
+	default:
+		return n.data
+	}
+}
+
+func (n *xmlNode) appendChild(child *xmlNode) {
+	child.parent = n
+	child.index = len(n.children)
+	n.children = append(n.children, child)
+}
+
+const xmlNamespaceURI = "http://www.w3.org/XML/1998/namespace"
+
+// parseXML parses document into a tree with resolved namespaces.
+// Unlike xml.Decoder.Token, it preserves prefixes used in document.
+func parseXML(content []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+
+	doc := &xmlNode{typ: xpath.RootNode}
+	curr := doc
+
+	scopes := []map[string]string{
+		{"xml": xmlNamespaceURI},
+	}
+
+	lookup := func(prefix string) (string, bool) {
+		for n := len(scopes) - 1; n >= 0; n-- {
+			if uri, ok := scopes[n][prefix]; ok {
+				return uri, true
+			}
+		}
+		return "", prefix == ""
+	}
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if curr == doc && doc.hasElement() {
+				return nil, fmt.Errorf("unexpected element <%s> after root element",
+					t.Name.Local)
+			}
+
+			scope := map[string]string{}
+			for _, attr := range t.Attr {
+				switch {
+				case attr.Name.Space == "xmlns":
+					scope[attr.Name.Local] = attr.Value
+				case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+					scope[""] = attr.Value
+				}
+			}
+			scopes = append(scopes, scope)
+
+			element := &xmlNode{
+				typ:    xpath.ElementNode,
+				prefix: t.Name.Space,
+				local:  t.Name.Local,
+			}
+
+			uri, ok := lookup(t.Name.Space)
+			if !ok {
+				return nil, fmt.Errorf("undeclared namespace prefix %q", t.Name.Space)
+			}
+			element.space = uri
+
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" ||
+					(attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+					continue
+				}
+				node := &xmlNode{
+					typ:    xpath.AttributeNode,
+					prefix: attr.Name.Space,
+					local:  attr.Name.Local,
+					data:   attr.Value,
+					parent: element,
+				}
+				if attr.Name.Space != "" {
+					uri, ok := lookup(attr.Name.Space)
+					if !ok {
+						return nil, fmt.Errorf("undeclared namespace prefix %q",
+							attr.Name.Space)
+					}
+					node.space = uri
+				}
+				element.attrs = append(element.attrs, node)
+			}
+
+			curr.appendChild(element)
+			curr = element
+
+		case xml.EndElement:
+			if curr == doc || curr.prefix != t.Name.Space || curr.local != t.Name.Local {
+				return nil, fmt.Errorf("unexpected end element </%s>", t.Name.Local)
+			}
+			scopes = scopes[:len(scopes)-1]
+			curr = curr.parent
+
+		case xml.CharData:
+			if curr == doc {
+				if len(bytes.TrimSpace(t)) != 0 {
+					return nil, fmt.Errorf("unexpected text outside of root element")
+				}
+				continue
+			}
+			if last := len(curr.children) - 1; last >= 0 &&
+				curr.children[last].typ == xpath.TextNode {
+				curr.children[last].data += string(t)
+				continue
+			}
+			curr.appendChild(&xmlNode{typ: xpath.TextNode, data: string(t)})
+
+		case xml.Comment:
+			curr.appendChild(&xmlNode{typ: xpath.CommentNode, data: string(t)})
+		}
+	}
+
+	if curr != doc {
+		return nil, fmt.Errorf("unexpected EOF: element <%s> is not closed", curr.local)
+	}
+	if !doc.hasElement() {
+		return nil, fmt.Errorf("no root element")
+	}
+
+	return doc, nil
+}
+
+func (n *xmlNode) hasElement() bool {
+	for _, child := range n.children {
+		if child.typ == xpath.ElementNode {
+			return true
+		}
+	}
+	return false
+}
+
+// xmlNavigator implements xpath.NodeNavigator for xmlNode tree.
+type xmlNavigator struct {
+	xml  *XML
+	root *xmlNode
+	curr *xmlNode
+	attr int
+}
+
+func (n *xmlNavigator) node() *xmlNode {
+	if n.attr >= 0 {
+		return n.curr.attrs[n.attr]
+	}
+	return n.curr
+}
+
+func (n *xmlNavigator) NodeType() xpath.NodeType {
+	return n.node().typ
+}
+
+func (n *xmlNavigator) LocalName() string {
+	return n.node().local
+}
+
+func (n *xmlNavigator) Prefix() string {
+	return n.xml.prefix(n.node())
+}
+
+func (n *xmlNavigator) Value() string {
+	return n.node().text()
+}
+
+func (n *xmlNavigator) Copy() xpath.NodeNavigator {
+	c := *n
+	return &c
+}
+
+func (n *xmlNavigator) MoveToRoot() {
+	n.curr = n.root
+	n.attr = -1
+}
+
+func (n *xmlNavigator) MoveToParent() bool {
+	if n.attr >= 0 {
+		n.attr = -1
+		return true
+	}
+	if n.curr.parent == nil {
+		return false
+	}
+	n.curr = n.curr.parent
+	return true
+}
+
+func (n *xmlNavigator) MoveToNextAttribute() bool {
+	if n.attr+1 >= len(n.curr.attrs) {
+		return false
+	}
+	n.attr++
+	return true
+}
+
+func (n *xmlNavigator) MoveToChild() bool {
+	if n.attr >= 0 || len(n.curr.children) == 0 {
+		return false
+	}
+	n.curr = n.curr.children[0]
+	return true
+}
+
+func (n *xmlNavigator) MoveToFirst() bool {
+	if n.attr >= 0 || n.curr.parent == nil || n.curr.index == 0 {
+		return false
+	}
+	n.curr = n.curr.parent.children[0]
+	return true
+}
+
+func (n *xmlNavigator) MoveToNext() bool {
+	if n.attr >= 0 || n.curr.parent == nil ||
+		n.curr.index+1 >= len(n.curr.parent.children) {
+		return false
+	}
+	n.curr = n.curr.parent.children[n.curr.index+1]
+	return true
+}
+
+func (n *xmlNavigator) MoveToPrevious() bool {
+	if n.attr >= 0 || n.curr.parent == nil || n.curr.index == 0 {
+		return false
+	}
+	n.curr = n.curr.parent.children[n.curr.index-1]
+	return true
+}
+
+func (n *xmlNavigator) MoveTo(other xpath.NodeNavigator) bool {
+	o, ok := other.(*xmlNavigator)
+	if !ok || o.root != n.root {
+		return false
+	}
+	n.curr = o.curr
+	n.attr = o.attr
+	return true
+}
@@ -0,0 +1,325 @@
+package httpexpect
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testXMLUsers = `<?xml version="1.0" encoding="UTF-8"?>
+<!-- users -->
+<users count="2">
+  <user id="1" active="true">
+    <name>john</name>
+    <role>admin</role>
+    <role>dev</role>
+  </user>
+  <user id="2">
+    <name>bob <b>smith</b></name>
+  </user>
+</users>`
+
+func TestXMLFailed(t *testing.T) {
+	chain := makeChain(newMockReporter(t))
+
+	chain.fail("fail")
+
+	value := &XML{chain, nil, nil}
+
+	value.WithNamespace("a", "b").chain.assertFailed(t)
+	value.Name().chain.assertFailed(t)
+	value.Namespace().chain.assertFailed(t)
+	value.Text().chain.assertFailed(t)
+	value.Attribute("foo").chain.assertFailed(t)
+	value.ContainsAttribute("foo").chain.assertFailed(t)
+	value.NotContainsAttribute("foo").chain.assertFailed(t)
+	value.Element("foo").chain.assertFailed(t)
+	value.XPath("foo").chain.assertFailed(t)
+	value.Value().chain.assertFailed(t)
+	value.Object().chain.assertFailed(t)
+
+	assert.Equal(t, 0, len(value.Elements("foo")))
+}
+
+func TestXMLInvalid(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	for _, content := range []string{
+		``,
+		`<users>`,
+		`<users></user>`,
+		`<a/><b/>`,
+		`text<a/>`,
+		`<a:users/>`,
+		`<users a:id="1"/>`,
+	} {
+		NewXML(reporter, content).chain.assertFailed(t)
+	}
+}
+
+func TestXMLElement(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	x := NewXML(reporter, testXMLUsers)
+	x.chain.assertOK(t)
+
+	x.Name().Equal("users").chain.assertOK(t)
+	x.Namespace().Equal("").chain.assertOK(t)
+	x.Attribute("count").Equal("2").chain.assertOK(t)
+	x.ContainsAttribute("count").chain.assertOK(t)
+	x.NotContainsAttribute("id").chain.assertOK(t)
+
+	x.Attribute("id").chain.assertFailed(t)
+	x.ContainsAttribute("id").chain.assertFailed(t)
+	x.chain.reset()
+
+	x.NotContainsAttribute("count").chain.assertFailed(t)
+	x.chain.reset()
+
+	user := x.Element("user[@id='2']")
+	user.chain.assertOK(t)
+	user.Attribute("id").Equal("2").chain.assertOK(t)
+	user.Element("name").Text().Equal("bob smith").chain.assertOK(t)
+
+	x.Element("//name").Text().Equal("john").chain.assertOK(t)
+	x.Element("/users/user/name").Text().Equal("john").chain.assertOK(t)
+	x.Element("user").Element("..").Name().Equal("users").chain.assertOK(t)
+
+	x.Element("missing").chain.assertFailed(t)
+	x.Element("user/@id").chain.assertFailed(t)
+	x.Element("count(user)").chain.assertFailed(t)
+	x.Element("[").chain.assertFailed(t)
+
+	users := x.Elements("user")
+	assert.Equal(t, 2, len(users))
+	users[0].Attribute("id").Equal("1").chain.assertOK(t)
+	users[1].Attribute("id").Equal("2").chain.assertOK(t)
+
+	assert.Equal(t, 0, len(x.Elements("missing")))
+	assert.Equal(t, 0, len(x.Elements("user/@id")))
+}
+
+func TestXMLXPath(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	x := NewXML(reporter, testXMLUsers)
+
+	x.XPath("count(user)").Number().Equal(2).chain.assertOK(t)
+	x.XPath("user/@id").Array().Elements("1", "2").chain.assertOK(t)
+	x.XPath("string(user[2]/@id)").String().Equal("2").chain.assertOK(t)
+	x.XPath("boolean(user[@active])").Boolean().True().chain.assertOK(t)
+	x.XPath("user[1]/role/text()").Array().Elements("admin", "dev").
+		chain.assertOK(t)
+	x.XPath("//comment()").Array().Elements(" users ").chain.assertOK(t)
+	x.XPath("missing").Array().Empty().chain.assertOK(t)
+
+	x.XPath("user[1]/name").Array().Elements("john").chain.assertOK(t)
+
+	x.XPath("user[").chain.assertFailed(t)
+}
+
+func TestXMLNamespaces(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	x := NewXML(reporter, `
+<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"
+            xmlns:u="http://example.com/users">
+  <s:Body>
+    <GetUserResponse xmlns="http://example.com/users">
+      <User u:id="1">john</User>
+    </GetUserResponse>
+  </s:Body>
+</s:Envelope>`)
+
+	x.chain.assertOK(t)
+
+	x.Name().Equal("Envelope").chain.assertOK(t)
+	x.Namespace().Equal("http://www.w3.org/2003/05/soap-envelope").
+		chain.assertOK(t)
+
+	x.Element("s:Body").chain.assertOK(t)
+	x.Element("Body").chain.assertFailed(t)
+
+	ns := x.WithNamespace("soap", "http://www.w3.org/2003/05/soap-envelope").
+		WithNamespace("users", "http://example.com/users")
+
+	ns.Element("soap:Body").chain.assertOK(t)
+	ns.Element("s:Body").chain.assertFailed(t)
+
+	user := ns.Element("soap:Body/users:GetUserResponse/users:User")
+	user.chain.assertOK(t)
+	user.Namespace().Equal("http://example.com/users").chain.assertOK(t)
+	user.Attribute("users:id").Equal("1").chain.assertOK(t)
+	user.Attribute("u:id").chain.assertFailed(t)
+
+	x.Element("s:Body/GetUserResponse/User").Text().Equal("john").
+		chain.assertOK(t)
+	x.Element("//*[local-name()='User']").Attribute("u:id").Equal("1").
+		chain.assertOK(t)
+
+	ns.Element("soap:Body").Value().Equal(map[string]interface{}{
+		"users:GetUserResponse": map[string]interface{}{
+			"users:User": map[string]interface{}{
+				"@users:id": "1",
+				"#text":     "john",
+			},
+		},
+	}).chain.assertOK(t)
+
+	conflict := x.WithNamespace("s", "http://example.com/other")
+	conflict.Element("s:Body").chain.assertFailed(t)
+}
+
+func TestXMLConvert(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	x := NewXML(reporter, testXMLUsers)
+
+	x.Value().Equal(map[string]interface{}{
+		"@count": "2",
+		"user": []interface{}{
+			map[string]interface{}{
+				"@id":     "1",
+				"@active": "true",
+				"name":    "john",
+				"role":    []interface{}{"admin", "dev"},
+			},
+			map[string]interface{}{
+				"@id": "2",
+				"name": map[string]interface{}{
+					"#text": "bob",
+					"b":     "smith",
+				},
+			},
+		},
+	}).chain.assertOK(t)
+
+	x.Object().Value("user").Array().Element(0).Object().
+		ValueEqual("@id", "1").
+		chain.assertOK(t)
+
+	name := x.Element("user/name")
+	name.Value().String().Equal("john").chain.assertOK(t)
+	name.Object().Equal(map[string]interface{}{"#text": "john"}).chain.assertOK(t)
+
+	NewXML(reporter, `<empty/>`).Object().Empty().chain.assertOK(t)
+
+	failure := &mockFailureReporter{mockReporter: *newMockReporter(t)}
+
+	NewXML(failure, testXMLUsers).Object().
+		Value("user").Array().Element(0).Object().ValueEqual("@id", "2")
+
+	assert.Equal(t, 1, len(failure.failures))
+	assert.Equal(t, "$.user[0]['@id']", failure.failures[0].JSONPath)
+}
+
+func TestXMLResponse(t *testing.T) {
+	reporter := newMockReporter(t)
+
+	body := `<user id="1"><name>john</name></user>`
+
+	for _, contentType := range []string{
+		"application/xml",
+		"application/xml; charset=utf-8",
+		"text/xml",
+		"application/soap+xml",
+	} {
+		httpResp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header(map[string][]string{
+				"Content-Type": {contentType},
+			}),
+			Body: ioutil.NopCloser(bytes.NewBufferString(body)),
+		}
+
+		resp := NewResponse(reporter, httpResp)
+
+		x := resp.XML()
+		x.chain.assertOK(t)
+		x.Attribute("id").Equal("1").chain.assertOK(t)
+		x.Element("name").Text().Equal("john").chain.assertOK(t)
+	}
+
+	for _, contentType := range []string{
+		"application/json",
+		"application/xml; charset=latin1",
+		"",
+	} {
+		httpResp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header(map[string][]string{
+				"Content-Type": {contentType},
+			}),
+			Body: ioutil.NopCloser(bytes.NewBufferString(body)),
+		}
+
+		resp := NewResponse(reporter, httpResp)
+
+		resp.XML().chain.assertFailed(t)
+	}
+
+	httpResp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header(map[string][]string{
+			"Content-Type": {"application/vnd.foo"},
+		}),
+		Body: ioutil.NopCloser(bytes.NewBufferString(body)),
+	}
+
+	resp := NewResponse(reporter, httpResp)
+
+	resp.XML(ContentOpts{MediaType: "application/vnd.foo"}).
+		Attribute("id").Equal("1").
+		chain.assertOK(t)
+
+	httpResp = &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header(map[string][]string{
+			"Content-Type": {"application/xml"},
+		}),
+		Body: ioutil.NopCloser(bytes.NewBufferString(`<user>`)),
+	}
+
+	NewResponse(reporter, httpResp).XML().chain.assertFailed(t)
+}
+
+func TestXMLRequest(t *testing.T) {
+	type User struct {
+		XMLName xml.Name `xml:"user"`
+		ID      int      `xml:"id,attr"`
+		Name    string   `xml:"name"`
+	}
+
+	client := &mockClient{}
+
+	reporter := newMockReporter(t)
+
+	config := Config{
+		RequestFactory: DefaultRequestFactory{},
+		Client:         client,
+		Reporter:       reporter,
+	}
+
+	req := NewRequest(config, "POST", "http://example.com").
+		WithXML(User{ID: 1, Name: "john"})
+
+	resp := req.Expect()
+	resp.chain.assertOK(t)
+
+	assert.Equal(t, "application/xml; charset=utf-8",
+		client.req.Header.Get("Content-Type"))
+	assert.Equal(t, `<user id="1"><name>john</name></user>`, string(resp.content))
+
+	NewRequest(config, "POST", "http://example.com").
+		WithXML(make(chan int)).
+		chain.assertFailed(t)
+
+	NewRequest(config, "POST", "http://example.com").
+		WithText("hello").
+		WithXML(User{}).
+		chain.assertFailed(t)
+}